@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/enumgen"
+)
+
+// enumgen is kept as the alias of 'gox enum' for the existing go:generate directives.
+func main() {
+	command.Main(enumgen.Command)
+}
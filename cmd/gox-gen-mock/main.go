@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yanun0323/gox/gen"
+)
+
+// gox-gen-mock is the reference plugin of gox, it generates the mock of the interface whose methods
+// call the function fields of the same names.
+//
+//	//go:generate gox plugin -plugin=mock -destination=../mock/member.go -package=mock
+//
+// The mock is named <Interface>Mock, or the parameter of 'gox plugin -param'.
+func main() {
+	gen.ServePlugin(generate)
+}
+
+func generate(req *gen.PluginRequest) (*gen.PluginResponse, error) {
+	data := req.Implement
+	name := data.Interface + "Mock"
+	if len(req.Parameter) != 0 {
+		name = req.Parameter
+	}
+
+	buf := strings.Builder{}
+	fmt.Fprintf(&buf, "// Code generated by %s. DO NOT EDIT.\n\n", data.Generator)
+	fmt.Fprintf(&buf, "package %s\n\n", data.Package)
+
+	buf.WriteString("import (\n")
+	for _, imp := range data.Imports {
+		fmt.Fprintf(&buf, "\t%s %q\n", imp.Name, imp.Path)
+	}
+	buf.WriteString(")\n\n")
+
+	fmt.Fprintf(&buf, "// %s is the mock of %s, the methods call the functions of the same names.\n", name, data.InterfaceType)
+	fmt.Fprintf(&buf, "type %s struct {\n", name)
+	for _, m := range data.Methods {
+		params, _ := mockParams(m)
+		fmt.Fprintf(&buf, "\t%sFunc func(%s) %s\n", m.Name, params, mockResults(m))
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "var _ %s = (*%s)(nil)\n", data.InterfaceType, name)
+
+	for _, m := range data.Methods {
+		params, args := mockParams(m)
+		receiver := mockReceiver(m)
+		call := fmt.Sprintf("%s.%sFunc(%s)", receiver, m.Name, args)
+		if len(m.Results) != 0 {
+			call = "return " + call
+		}
+
+		fmt.Fprintf(&buf, "\n// %s calls %sFunc.\n", m.Name, m.Name)
+		fmt.Fprintf(&buf, "func (%s *%s) %s(%s) %s {\n\t%s\n}\n", receiver, name, m.Name, params, mockResults(m), call)
+	}
+
+	return &gen.PluginResponse{Files: []gen.PluginFile{{Path: req.Destination, Content: buf.String()}}}, nil
+}
+
+// mockReceiver returns the receiver name of the method, it's 'm' unless a parameter is named 'm'.
+func mockReceiver(m gen.Method) string {
+	names := paramNames(m)
+	receiver := "m"
+	for i := 0; names[receiver]; i++ {
+		receiver = fmt.Sprintf("m%d", i)
+	}
+
+	return receiver
+}
+
+// paramNames returns the names of the named parameters of the method.
+func paramNames(m gen.Method) map[string]bool {
+	names := make(map[string]bool, len(m.Params))
+	for _, p := range m.Params {
+		if len(p.Name) != 0 && p.Name != "_" {
+			names[p.Name] = true
+		}
+	}
+
+	return names
+}
+
+// mockParams returns the parameters of the method and the arguments passing them, the unnamed
+// parameters are named by their indexes, with the suffixes '_' when the names are used by the other
+// parameters.
+func mockParams(m gen.Method) (params, args string) {
+	names := paramNames(m)
+	paramList := make([]string, 0, len(m.Params))
+	argList := make([]string, 0, len(m.Params))
+	for i, p := range m.Params {
+		name := p.Name
+		if len(name) == 0 || name == "_" {
+			name = fmt.Sprintf("p%d", i)
+			for names[name] {
+				name += "_"
+			}
+
+			names[name] = true
+		}
+
+		paramList = append(paramList, name+" "+p.QualifiedType)
+		if strings.HasPrefix(p.QualifiedType, "...") {
+			name += "..."
+		}
+
+		argList = append(argList, name)
+	}
+
+	return strings.Join(paramList, ", "), strings.Join(argList, ", ")
+}
+
+// mockResults returns the result types of the method.
+func mockResults(m gen.Method) string {
+	results := make([]string, 0, len(m.Results))
+	for _, r := range m.Results {
+		results = append(results, r.QualifiedType)
+	}
+
+	if len(results) == 1 {
+		return results[0]
+	}
+
+	if len(results) == 0 {
+		return ""
+	}
+
+	return "(" + strings.Join(results, ", ") + ")"
+}
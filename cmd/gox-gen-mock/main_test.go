@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/gen/plugintest"
+)
+
+const _src = `package domain
+
+import (
+	"context"
+	"io"
+)
+
+type MemberUsecase interface {
+	io.Closer
+	Get(ctx context.Context, id int64) (*Member, error)
+	Find(string, ...int64) ([]Member, error)
+	Notify(_ chan<- Member)
+}
+
+type Member struct{ ID int64 }
+`
+
+func TestGenerate(t *testing.T) {
+	h := plugintest.New(t, _src, "MemberUsecase", gen.Options{})
+	files := h.Build(h.Run(generate))
+
+	content := string(files[0].Content)
+	for _, expected := range []string{
+		"type MemberUsecaseMock struct {",
+		"\tGetFunc    func(ctx context.Context, id int64) (*domain.Member, error)",
+		"var _ domain.MemberUsecase = (*MemberUsecaseMock)(nil)",
+		"func (m *MemberUsecaseMock) Find(p0 string, p1 ...int64) ([]domain.Member, error) {\n\treturn m.FindFunc(p0, p1...)\n}",
+		"func (m *MemberUsecaseMock) Notify(p0 chan<- domain.Member) {\n\tm.NotifyFunc(p0)\n}",
+		"func (m *MemberUsecaseMock) Close() error {",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("%q not found in:\n%s", expected, content)
+		}
+	}
+}
+
+func TestGenerateNameCollision(t *testing.T) {
+	const src = `package domain
+
+type Store interface {
+	Put(m string, value int) error
+	Swap(_ string, p0 int, m0 bool) (int, error)
+}
+`
+
+	h := plugintest.New(t, src, "Store", gen.Options{})
+	files := h.Build(h.Run(generate))
+
+	content := string(files[0].Content)
+	for _, expected := range []string{
+		"func (m0 *StoreMock) Put(m string, value int) error {\n\treturn m0.PutFunc(m, value)\n}",
+		"func (m *StoreMock) Swap(p0_ string, p0 int, m0 bool) (int, error) {\n\treturn m.SwapFunc(p0_, p0, m0)\n}",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("%q not found in:\n%s", expected, content)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	exe := plugintest.BuildPlugin(t, ".", "mock")
+
+	h := plugintest.New(t, _src, "MemberUsecase", gen.Options{Destination: "../mock/member.go", Package: "mock"})
+	h.Request.Parameter = "Member"
+
+	files := h.Build(h.Exec(exe))
+	if !strings.Contains(string(files[0].Content), "func (m *Member) Get(ctx context.Context, id int64) (*domain.Member, error) {") {
+		t.Fatalf("mock mismatch:\n%s", files[0].Content)
+	}
+}
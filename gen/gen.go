@@ -0,0 +1,193 @@
+// Package gen is the library of the gox generators, it generates the file contents without
+// reading the go:generate environment, so the generators can be called from the other programs.
+//
+//	result, err := gen.Implement(ctx, gen.InterfaceRef{File: "domain/member.go", Name: "MemberUsecase"}, gen.Options{
+//		Destination: "usecase/member.go",
+//		Package:     "usecase",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	return result.Save()
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yanun0323/goast"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+// ErrNotFound is returned when the referenced declaration is not found in the file.
+var ErrNotFound = errors.New("declaration not found")
+
+// PositionError is the error of the declaration at the position, e.g. the interface which can't be
+// implemented.
+type PositionError struct {
+	Pos token.Position
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// InterfaceRef locates the interface to implement in the file, by the line of the go:generate
+// directive above the interface or by the name of the interface.
+type InterfaceRef struct {
+	// File is the path of the go file declaring the interface.
+	File string
+	// Line is the line of the go:generate directive above the interface, it's used when Name is empty.
+	Line int
+	// Name is the name of the interface.
+	Name string
+}
+
+// Options is the options of Implement, they're the same as the flags of 'gox domain'.
+type Options struct {
+	// Destination is the path of the generated file, it's relative to the folder of the
+	// interface file if it's not absolute.
+	Destination string
+	// Package is the package name of the generated file.
+	Package string
+	// Name is the implementation struct name, it's the interface name in lower camel case by default.
+	Name string
+	// Replace replaces the existing methods of the implementation.
+	Replace bool
+	// NoEmbed skips implementing the methods of the embedded interfaces.
+	NoEmbed bool
+	// NoStruct skips generating the implementation struct.
+	NoStruct bool
+	// NoConstructor skips generating the constructor function.
+	NoConstructor bool
+	// Template is the template file or the folder of the *.tmpl files overriding the default
+	// templates, see ImplementData. It's relative to the folder of the interface file if it's not absolute.
+	Template string
+}
+
+// File is a generated file.
+type File struct {
+	// Path is the path of the file.
+	Path string
+	// Content is the formatted content of the file.
+	Content []byte
+}
+
+// ActionKind is the kind of Action.
+type ActionKind string
+
+const (
+	// ActionCreateFile creates the file of Action.Name.
+	ActionCreateFile ActionKind = "create_file"
+	// ActionUpdateFile updates the existing file of Action.Name.
+	ActionUpdateFile ActionKind = "update_file"
+	// ActionAdd adds the declaration of Action.Name.
+	ActionAdd ActionKind = "add"
+	// ActionSkip skips the declaration of Action.Name, which already exists.
+	ActionSkip ActionKind = "skip"
+	// ActionReplace replaces the existing declaration of Action.Name.
+	ActionReplace ActionKind = "replace"
+	// ActionRemove removes the existing declaration of Action.Name.
+	ActionRemove ActionKind = "remove"
+)
+
+// Action is a change of the generated files.
+type Action struct {
+	Kind ActionKind `json:"kind"`
+	// Name is the path of the file actions, or the declaration of the others, e.g. the struct name,
+	// the function name or 'Receiver.Method' of the methods.
+	Name string `json:"name"`
+}
+
+// FileAction returns the action writing the file, it's ActionUpdateFile if the file exists.
+func FileAction(path string) Action {
+	if _, err := os.Stat(path); err == nil {
+		return Action{Kind: ActionUpdateFile, Name: path}
+	}
+
+	return Action{Kind: ActionCreateFile, Name: path}
+}
+
+// Result is the result of a generator.
+type Result struct {
+	Files []File
+	// Type is the name of the declaration generating the files.
+	Type string
+	// Source is the position of the declaration.
+	Source token.Position
+	// Actions is the changes of the files in the order they're made.
+	Actions []Action
+}
+
+// Save writes the files of the result, the missing folders are created.
+func (r *Result) Save() error {
+	for _, f := range r.Files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0777); err != nil {
+			return fmt.Errorf("mkdir %s, err: %w", filepath.Dir(f.Path), err)
+		}
+
+		if err := os.WriteFile(f.Path, f.Content, 0644); err != nil {
+			return fmt.Errorf("write file %s, err: %w", f.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// render returns the content of the ast formatted with the imports, the missing imports are added
+// by goimports.
+func render(file string, a goast.Ast, importPaths map[string]string) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for _, sc := range a.Scope() {
+		sc.Node().IterNext(func(n *goast.Node) bool {
+			buf.WriteString(n.Text())
+			return true
+		})
+	}
+
+	if buf.Len() == 0 {
+		return nil, errors.New("render empty content")
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse generated %s, err: %w", file, err)
+	}
+
+	paths := make([]string, 0, len(importPaths))
+	for importPath := range importPaths {
+		paths = append(paths, importPath)
+	}
+
+	sort.Strings(paths)
+	for _, importPath := range paths {
+		astutil.AddNamedImport(fset, f, importPaths[importPath], importPath)
+	}
+
+	buf.Reset()
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, fmt.Errorf("format %s, err: %w", file, err)
+	}
+
+	formatted, err := imports.Process(file, buf.Bytes(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("format imports of %s, err: %w", file, err)
+	}
+
+	return formatted, nil
+}
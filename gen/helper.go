@@ -0,0 +1,50 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/yanun0323/gox/internal/util"
+)
+
+var helper = helperInstance{}
+
+type helperInstance struct {
+	util.Helper
+}
+
+// sourceImport returns the import path of the package in the folder, and the alias of it when the
+// package name differs from the folder name.
+func (helperInstance) sourceImport(dir, pkg string) (alias, importPath string, err error) {
+	for moduleDir := dir; ; moduleDir = filepath.Dir(moduleDir) {
+		moduleName, err := helper.ReadModuleName(filepath.Join(moduleDir, "go.mod"))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", "", err
+			}
+
+			if parent := filepath.Dir(moduleDir); parent == moduleDir {
+				return "", "", errors.New("go.mod not found")
+			}
+
+			continue
+		}
+
+		rel, err := filepath.Rel(moduleDir, dir)
+		if err != nil {
+			return "", "", err
+		}
+
+		importPath = moduleName
+		if rel != "." {
+			importPath = moduleName + "/" + filepath.ToSlash(rel)
+		}
+
+		if filepath.Base(dir) != pkg {
+			alias = pkg
+		}
+
+		return alias, importPath, nil
+	}
+}
@@ -0,0 +1,560 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/goast/kind"
+	"github.com/yanun0323/goast/scope"
+	"golang.org/x/tools/go/packages"
+)
+
+// _implementName is the generator name written in the comments of the replaced code.
+const _implementName = "domaingen"
+
+// Implement generates the implementation of the interface referenced by ref, the existing
+// destination file is updated with the missing methods, or replaced by Options.Replace. The package
+// of the interface is type checked, so the types of the methods are qualified and imported by the
+// packages they belong to.
+func Implement(ctx context.Context, ref InterfaceRef, opt Options) (*Result, error) {
+	return ImplementPackage(ctx, nil, ref, opt)
+}
+
+// ImplementPackage is Implement with the package of the interface loaded by LoadMode, so the
+// packages loaded at once by packages.Load are shared by the interfaces declared in them. The
+// package is loaded like Implement when it's nil.
+func ImplementPackage(ctx context.Context, pkg *packages.Package, ref InterfaceRef, opt Options) (*Result, error) {
+	g, err := resolveImplementer(ctx, pkg, ref, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	templatePath := g.opt.Template
+	if len(templatePath) != 0 && !filepath.IsAbs(templatePath) {
+		templatePath = filepath.Join(g.sourceDir, templatePath)
+	}
+
+	if g.tmpl, err = loadTemplates(templatePath); err != nil {
+		return nil, err
+	}
+
+	desAst, err := g.tryGetDestinationFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var resultAst goast.Ast
+	if desAst == nil {
+		resultAst, err = g.createNewDestinationFile()
+	} else {
+		resultAst, err = g.updateDestinationFile(desAst)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	content, err := render(g.opt.Destination, resultAst, g.qualifier.Imports())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Files:   []File{{Path: g.opt.Destination, Content: content}},
+		Type:    g.data.Interface,
+		Source:  g.source,
+		Actions: g.actions,
+	}, nil
+}
+
+// resolveImplementer type checks the package of the interface unless it's loaded, and returns the
+// implementer with the data of the interface qualified for the destination.
+func resolveImplementer(ctx context.Context, pkg *packages.Package, ref InterfaceRef, opt Options) (*implementer, error) {
+	if len(opt.Destination) == 0 {
+		return nil, errors.New("destination not define")
+	}
+
+	if len(opt.Package) == 0 {
+		return nil, errors.New("package not define")
+	}
+
+	file, err := filepath.Abs(ref.File)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %s, err: %w", ref.File, err)
+	}
+
+	g := &implementer{opt: opt, sourceFile: file, sourceDir: filepath.Dir(file)}
+	if !filepath.IsAbs(g.opt.Destination) {
+		g.opt.Destination = filepath.Join(g.sourceDir, g.opt.Destination)
+	}
+
+	if !strings.HasSuffix(g.opt.Destination, ".go") {
+		g.opt.Destination = g.opt.Destination + ".go"
+	}
+
+	if pkg == nil {
+		if pkg, err = loadPackage(ctx, g.sourceDir); err != nil {
+			return nil, err
+		}
+	}
+
+	named, err := findInterfaceType(pkg, file, ref.Line, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaceName := named.Obj().Name()
+	if len(g.opt.Name) == 0 {
+		g.opt.Name = helper.FirstLowerCase(interfaceName)
+	}
+
+	g.qualifier = newQualifier(pkg.PkgPath)
+	if !g.isDestinationSameFolderToSource() {
+		// the destination outside the module qualifies all the packages
+		_, destinationPath, _ := helper.sourceImport(filepath.Dir(g.opt.Destination), g.opt.Package)
+		g.qualifier = newQualifier(destinationPath)
+	}
+
+	methods := interfaceMethods(named.Underlying().(*types.Interface), g.opt.NoEmbed, map[string]bool{})
+	params := paramNames(methods)
+
+	// the receiver is shared by the methods, so it's numbered when a parameter has the name
+	base := defaultReceiverName(g.opt.Name)
+	receiver := base
+	for i := 0; params[receiver]; i++ {
+		receiver = base + strconv.Itoa(i)
+	}
+
+	// the packages are qualified after the generated identifiers are reserved
+	g.qualifier.Reserve(g.opt.Name, receiver, constructFuncName(interfaceName))
+	for name := range params {
+		g.qualifier.Reserve(name)
+	}
+
+	for _, fn := range methods {
+		if strings.Contains(types.TypeString(fn.Type(), nil), "invalid type") {
+			return nil, fmt.Errorf("type check %s, err: %w", pkg.PkgPath, packageError(pkg))
+		}
+
+		if obj, ok := findUnexported(fn.Type(), pkg.Types); ok && g.qualifier.pkgPath != pkg.PkgPath {
+			err := fmt.Errorf("%s.%s uses the unexported type %s, which can't be referenced from package %s", interfaceName, fn.Name(), obj.Name(), g.opt.Package)
+			return nil, &PositionError{Pos: pkg.Fset.Position(fn.Pos()), Err: err}
+		}
+	}
+
+	g.sourcePkg = pkg.Types
+	g.source = pkg.Fset.Position(named.Obj().Pos())
+	g.data = ImplementData{
+		Package:       g.opt.Package,
+		Interface:     interfaceName,
+		InterfaceType: types.TypeString(named, g.qualifier.Qualify),
+		Struct:        g.opt.Name,
+		Receiver:      receiver,
+		Constructor:   constructFuncName(interfaceName),
+		Generator:     _implementName,
+		Replace:       g.opt.Replace,
+		Methods:       make([]Method, 0, len(methods)),
+	}
+
+	fields := methodFields(pkg)
+	for _, fn := range methods {
+		g.data.Methods = append(g.data.Methods, newMethod(fn, fields[fn.Pos()], sourceQualifier(pkg.Types), g.qualifier))
+	}
+
+	g.data.Imports = importList(g.qualifier)
+
+	return g, nil
+}
+
+// implementer generates the implementation of an interface with the options.
+type implementer struct {
+	opt        Options
+	sourceFile string
+	sourceDir  string
+	sourcePkg  *types.Package
+	source     token.Position
+	qualifier  *qualifier
+	tmpl       *template.Template
+	data       ImplementData
+	actions    []Action
+}
+
+// record records the change of the destination.
+func (g *implementer) record(kind ActionKind, name string) {
+	g.actions = append(g.actions, Action{Kind: kind, Name: name})
+}
+
+// methodName returns the name of the method action.
+func (g *implementer) methodName(m Method) string {
+	return g.opt.Name + "." + m.Name
+}
+
+func (g *implementer) isDestinationSameFolderToSource() bool {
+	return g.sourceDir == filepath.Dir(g.opt.Destination)
+}
+
+func (g *implementer) tryGetDestinationFile() (goast.Ast, error) {
+	desAst, err := goast.ParseAst(g.opt.Destination)
+	if err != nil && !errors.Is(err, goast.ErrNotExist) {
+		return nil, fmt.Errorf("parse destination ast, err: %w", err)
+	}
+
+	return desAst, nil
+}
+
+func (g *implementer) createNewDestinationFile() (goast.Ast, error) {
+	implementation, err := g.genImplementationString()
+	if err != nil {
+		return nil, err
+	}
+
+	constructor, err := g.genConstructorString()
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("%s\n%s\n%s\n", g.genPackageString(), implementation, constructor)
+
+	scs, err := goast.ParseScope(0, []byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse scope for creating struct, err: %w", err)
+	}
+
+	methodScopes, err := g.genMethodScopes(g.data.Methods)
+	if err != nil {
+		return nil, err
+	}
+
+	newAst, err := goast.NewAst(append(scs, methodScopes...)...)
+	if err != nil {
+		return nil, fmt.Errorf("new ast, err: %w", err)
+	}
+
+	g.record(ActionCreateFile, g.opt.Destination)
+	if !g.opt.NoStruct {
+		g.record(ActionAdd, g.opt.Name)
+	}
+
+	if !g.opt.NoConstructor {
+		g.record(ActionAdd, g.data.Constructor)
+	}
+
+	for _, m := range g.data.Methods {
+		g.record(ActionAdd, g.methodName(m))
+	}
+
+	return newAst, nil
+}
+
+func (g *implementer) genPackageString() string {
+	return fmt.Sprintf("package %s\n", g.opt.Package)
+}
+
+// genImplementationString returns the implementation struct by the template "struct".
+func (g *implementer) genImplementationString() (string, error) {
+	if g.opt.NoStruct {
+		return "", nil
+	}
+
+	return executeTemplate(g.tmpl, "struct", g.data)
+}
+
+// genConstructorString returns the constructor function by the template "constructor".
+func (g *implementer) genConstructorString() (string, error) {
+	if g.opt.NoConstructor {
+		return "", nil
+	}
+
+	return executeTemplate(g.tmpl, "constructor", g.data)
+}
+
+// genMethodScopes returns the scopes of the methods implemented by the struct.
+func (g *implementer) genMethodScopes(methods []Method) ([]goast.Scope, error) {
+	result := make([]goast.Scope, 0, len(methods))
+	for _, m := range methods {
+		text, err := executeTemplate(g.tmpl, "method", MethodData{ImplementData: g.data, Method: m})
+		if err != nil {
+			return nil, err
+		}
+
+		scs, err := goast.ParseScope(0, []byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("parse scope for method %s, err: %w", m.Name, err)
+		}
+
+		// the leading new line separates the method from the last scope of the existing destination
+		if len(scs) != 0 {
+			head := goast.NewNodes(scs[0].Line(), "\n")
+			head.Last().ReplaceNext(scs[0].Node())
+			scs[0] = goast.NewScope(scs[0].Line(), scs[0].Kind(), head)
+		}
+
+		result = append(result, scs...)
+	}
+
+	return result, nil
+}
+
+// paramNames returns the names of the parameters and the results of the methods.
+func paramNames(methods []*types.Func) map[string]bool {
+	names := map[string]bool{}
+	for _, fn := range methods {
+		sig := fn.Type().(*types.Signature)
+		for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if name := tuple.At(i).Name(); len(name) != 0 && name != "_" {
+					names[name] = true
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// defaultReceiverName returns the receiver name of the struct without the existing methods.
+func defaultReceiverName(structName string) string {
+	lowercaseName := strings.ToLower(structName)
+	if strings.Contains(lowercaseName, "usecase") {
+		return "use"
+	} else if strings.Contains(lowercaseName, "repo") {
+		return "repo"
+	}
+
+	return string(helper.FirstLowerCase(structName)[0])
+}
+
+func constructFuncName(interfaceName string) string {
+	return fmt.Sprintf("New%s", interfaceName)
+}
+
+func (g *implementer) updateDestinationFile(desAst goast.Ast) (goast.Ast, error) {
+	// find implementation is exist or not
+	var (
+		isPackageExist     bool
+		isStructExist      bool
+		isConstructorExist bool
+		scopes             []goast.Scope
+		existMethods       = map[string]bool{}
+		droppedMethods     []string
+
+		newFuncName = g.data.Constructor
+	)
+
+	existReceiverName := ""
+
+	if g.opt.Replace {
+		/* keep other code */
+		desAst.IterScope(func(sc goast.Scope) bool {
+			if sc.Kind() == scope.Package {
+				isPackageExist = true
+			}
+
+			/* keep struct */
+			name, ok := sc.GetStructName()
+			if ok && strings.EqualFold(name, g.opt.Name) {
+				isStructExist = true
+				// return true
+			}
+
+			/* keep construct */
+			fnName, ok := sc.GetFuncName()
+			if ok && strings.EqualFold(fnName, newFuncName) {
+				isConstructorExist = true
+				// return true
+			}
+
+			/* drop method */
+			receiverName, receiverType, methodName, ok := findScopeMethod(sc)
+			if ok && helper.EqualFold(receiverType, g.opt.Name, '*') {
+				if len(receiverName) != 0 {
+					existReceiverName = receiverName
+				}
+
+				droppedMethods = append(droppedMethods, methodName)
+
+				// drop the doc comments of the dropped method
+				for line := sc.Line(); len(scopes) != 0; scopes = scopes[:len(scopes)-1] {
+					last := scopes[len(scopes)-1]
+					if (last.Kind() != scope.Comment && last.Kind() != scope.InnerComment) || last.Line()+1 != line {
+						break
+					}
+
+					line = last.Line()
+				}
+
+				return true
+			}
+
+			scopes = append(scopes, sc)
+
+			return true
+		})
+	} else {
+		/* find isStructExist, isConstructorExist and if methods exist */
+		desAst.IterScope(func(sc goast.Scope) bool {
+			scopes = append(scopes, sc)
+
+			if sc.Kind() == scope.Package {
+				isPackageExist = true
+			}
+
+			name, ok := sc.GetStructName()
+			if ok && strings.EqualFold(name, g.opt.Name) {
+				isStructExist = true
+			}
+
+			fnName, ok := sc.GetFuncName()
+			if ok && strings.EqualFold(fnName, newFuncName) {
+				isConstructorExist = true
+			}
+
+			receiverName, receiverType, methodName, ok := findScopeMethod(sc)
+			if !ok {
+				return true
+			}
+
+			if !helper.EqualFold(receiverType, g.opt.Name, '*') {
+				return true
+			}
+
+			if len(receiverName) != 0 {
+				existReceiverName = receiverName
+			}
+
+			existMethods[methodName] = true
+
+			return true
+		})
+	}
+
+	g.record(ActionUpdateFile, g.opt.Destination)
+
+	if !isPackageExist {
+		scs, err := goast.ParseScope(0, []byte(g.genPackageString()))
+		if err != nil {
+			return nil, fmt.Errorf("parse scope for package, err: %w", err)
+		}
+
+		scopes = append(scs, scopes...)
+	}
+
+	if isStructExist {
+		g.record(ActionSkip, g.opt.Name)
+	} else if !g.opt.NoStruct {
+		g.record(ActionAdd, g.opt.Name)
+	}
+
+	if !isStructExist {
+		implementation, err := g.genImplementationString()
+		if err != nil {
+			return nil, err
+		}
+
+		scs, err := goast.ParseScope(0, []byte(implementation))
+		if err != nil {
+			return nil, fmt.Errorf("parse scope for struct, err: %w", err)
+		}
+
+		scopes = append(scopes, scs...)
+	}
+
+	if isConstructorExist {
+		g.record(ActionSkip, newFuncName)
+	}
+
+	if !isConstructorExist && !g.opt.NoConstructor {
+		g.record(ActionAdd, newFuncName)
+
+		constructor, err := g.genConstructorString()
+		if err != nil {
+			return nil, err
+		}
+
+		scs, err := goast.ParseScope(0, []byte(constructor))
+		if err != nil {
+			return nil, fmt.Errorf("parse scope for constructor, err: %w", err)
+		}
+
+		scopes = append(scopes, scs...)
+	}
+
+	implemented := make(map[string]bool, len(g.data.Methods))
+	missing := make([]Method, 0, len(g.data.Methods))
+	for _, m := range g.data.Methods {
+		implemented[m.Name] = true
+		if existMethods[m.Name] {
+			g.record(ActionSkip, g.methodName(m))
+			continue
+		}
+
+		if slices.Contains(droppedMethods, m.Name) {
+			g.record(ActionReplace, g.methodName(m))
+		} else {
+			g.record(ActionAdd, g.methodName(m))
+		}
+
+		missing = append(missing, m)
+	}
+
+	for _, name := range droppedMethods {
+		if !implemented[name] {
+			g.record(ActionRemove, g.opt.Name+"."+name)
+		}
+	}
+
+	if len(existReceiverName) != 0 {
+		g.data.Receiver = existReceiverName
+	}
+
+	methodScopes, err := g.genMethodScopes(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	return desAst.SetScope(append(scopes, methodScopes...)), nil
+}
+
+func findScopeMethod(sc goast.Scope) (receiverName, receiverType, methodName string, ok bool) {
+	if sc.Kind() != scope.Func {
+		return "", "", "", false
+	}
+
+	var (
+		rName   string
+		rnFound bool
+		rType   string
+		rtFound bool
+		mName   string
+		mFound  bool
+	)
+	sc.Node().IterNext(func(n *goast.Node) bool {
+		switch n.Kind() {
+		case kind.MethodReceiverName:
+			rName = n.Text()
+			rnFound = true
+		case kind.MethodReceiverType:
+			rType = n.Text()
+			rtFound = true
+		case kind.FuncName:
+			mName = n.Text()
+			mFound = true
+		}
+
+		return !mFound
+	})
+
+	return rName, rType, mName, rnFound && rtFound && mFound
+}
@@ -0,0 +1,336 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestImplement(t *testing.T) {
+	const src = `package domain
+
+import (
+	"context"
+	"io"
+)
+
+type Base interface {
+	io.Closer
+	Ping(ctx context.Context) error
+}
+
+//go:generate gox domain -destination=../usecase/member.go -package=usecase
+type MemberUsecase interface {
+	Base
+	Get(ctx context.Context, id int64) (*Member, error)
+	Index(map[string]Member, ...func(Member) bool) (chan Member, error)
+}
+
+type Member struct{ ID int64 }
+
+type status int
+
+type StatusUsecase interface {
+	List() []status
+}
+`
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(dir, "domain", "member.go"), src)
+
+	ref := InterfaceRef{File: filepath.Join(dir, "domain", "member.go"), Line: 13}
+	opt := Options{Destination: "../usecase/member.go", Package: "usecase"}
+
+	result, err := Implement(context.Background(), ref, opt)
+	if err != nil {
+		t.Fatalf("implement, err: %+v", err)
+	}
+
+	destination := filepath.Join(dir, "usecase", "member.go")
+	if len(result.Files) != 1 || result.Files[0].Path != destination {
+		t.Fatalf("files mismatch: %+v", result.Files)
+	}
+
+	if len(result.Actions) != 7 || result.Actions[0] != (Action{Kind: ActionCreateFile, Name: destination}) || result.Type != "MemberUsecase" || result.Source.Line != 14 {
+		t.Fatalf("result mismatch: %s %s %+v", result.Type, result.Source, result.Actions)
+	}
+
+	if _, err := os.Stat(destination); !os.IsNotExist(err) {
+		t.Fatalf("destination shouldn't be written before saving, err: %+v", err)
+	}
+
+	content := string(result.Files[0].Content)
+	for _, expected := range []string{
+		`"example.com/app/domain"`,
+		"type memberUsecase struct {",
+		"func NewMemberUsecase() (domain.MemberUsecase, error) {",
+		"func (use *memberUsecase) Get(ctx context.Context, id int64) (*domain.Member, error) {",
+		"func (use *memberUsecase) Index(map[string]domain.Member, ...func(domain.Member) bool) (chan domain.Member, error) {",
+		"func (use *memberUsecase) Ping(ctx context.Context) error {",
+		"func (use *memberUsecase) Close() error {",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("%q not found in:\n%s", expected, content)
+		}
+	}
+
+	if err := result.Save(); err != nil {
+		t.Fatalf("save, err: %+v", err)
+	}
+
+	writeFile(t, ref.File, strings.Replace(src, "\tBase\n", "\tBase\n\tDelete(ctx context.Context, id int64) error\n", 1))
+
+	ref = InterfaceRef{File: ref.File, Name: "MemberUsecase"}
+	opt.NoEmbed = true
+	result, err = Implement(context.Background(), ref, opt)
+	if err != nil {
+		t.Fatalf("implement existing destination, err: %+v", err)
+	}
+
+	content = string(result.Files[0].Content)
+	if strings.Count(content, "func (use *memberUsecase) Get(") != 1 || !strings.Contains(content, "func (use *memberUsecase) Delete(") {
+		t.Fatalf("existing destination mismatch:\n%s", content)
+	}
+
+	if !reflect.DeepEqual(result.Actions, []Action{
+		{Kind: ActionUpdateFile, Name: destination},
+		{Kind: ActionSkip, Name: "memberUsecase"},
+		{Kind: ActionSkip, Name: "NewMemberUsecase"},
+		{Kind: ActionAdd, Name: "memberUsecase.Delete"},
+		{Kind: ActionSkip, Name: "memberUsecase.Get"},
+		{Kind: ActionSkip, Name: "memberUsecase.Index"},
+	}) {
+		t.Fatalf("actions mismatch: %+v", result.Actions)
+	}
+
+	if _, err := Implement(context.Background(), InterfaceRef{File: ref.File, Name: "Unknown"}, opt); err == nil {
+		t.Fatal("expected error of unknown interface")
+	}
+
+	var posErr *PositionError
+	if _, err := Implement(context.Background(), InterfaceRef{File: ref.File, Name: "StatusUsecase"}, opt); !errors.As(err, &posErr) || posErr.Pos.Line != 26 {
+		t.Fatalf("expected positioned error of the unexported type, err: %+v", err)
+	}
+}
+
+func TestImplementSignature(t *testing.T) {
+	const src = `package domain
+
+import "context"
+
+type MemberUsecase interface {
+	// Get returns the member of the id.
+	// It returns an error when missing.
+	Get(ctx context.Context, id, orgID int64) (*Member, error)
+	List(a, b string, opts ...int) (members []*Member, total int, err error)
+	/* Ping checks the service. */
+	Ping(int, string) (ok bool)
+}
+
+type Member struct{ ID int64 }
+`
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(dir, "domain", "member.go"), src)
+
+	ref := InterfaceRef{File: filepath.Join(dir, "domain", "member.go"), Name: "MemberUsecase"}
+	opt := Options{Destination: "../usecase/member.go", Package: "usecase"}
+
+	expected := []string{
+		"// Get returns the member of the id.\n// It returns an error when missing.\nfunc (use *memberUsecase) Get(ctx context.Context, id, orgID int64) (*domain.Member, error) {",
+		"\nfunc (use *memberUsecase) List(a, b string, opts ...int) (members []*domain.Member, total int, err error) {",
+		"/* Ping checks the service. */\nfunc (use *memberUsecase) Ping(int, string) (ok bool) {",
+	}
+
+	for _, replace := range []bool{false, true, true} {
+		opt.Replace = replace
+		result, err := Implement(context.Background(), ref, opt)
+		if err != nil {
+			t.Fatalf("implement, replace: %t, err: %+v", replace, err)
+		}
+
+		content := string(result.Files[0].Content)
+		for _, e := range expected {
+			if strings.Count(content, e) != 1 {
+				t.Fatalf("replace: %t, %q not found once in:\n%s", replace, e, content)
+			}
+		}
+
+		if strings.Count(content, "// Get returns") != 1 || strings.Count(content, "/* Ping") != 1 {
+			t.Fatalf("replace: %t, duplicated doc comments in:\n%s", replace, content)
+		}
+
+		if err := result.Save(); err != nil {
+			t.Fatalf("save, err: %+v", err)
+		}
+	}
+}
+
+func TestImplementNameCollision(t *testing.T) {
+	const src = `package a
+
+import "context"
+
+type A interface {
+	Get(context context.Context, a2 int) (a *A, err error)
+	Close() error
+}
+`
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, "a", "a.go"), src)
+
+	ref := InterfaceRef{File: filepath.Join(dir, "a", "a.go"), Name: "A"}
+	result, err := Implement(context.Background(), ref, Options{Destination: "../usecase/a.go", Package: "usecase"})
+	if err != nil {
+		t.Fatalf("implement, err: %+v", err)
+	}
+
+	content := string(result.Files[0].Content)
+	for _, expected := range []string{
+		`a3 "example.com/app/a"`,
+		`context2 "context"`,
+		"type a struct",
+		"func NewA() (a3.A, error) {",
+		"func (a0 *a) Get(context context2.Context, a2 int) (a *a3.A, err error) {",
+		"func (a0 *a) Close() error {",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("%q not found in:\n%s", expected, content)
+		}
+	}
+
+	if err := result.Save(); err != nil {
+		t.Fatalf("save, err: %+v", err)
+	}
+
+	if testing.Short() {
+		t.Skip("skip building the generated code in short mode")
+	}
+
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("vet generated code, err: %+v\n%s\n%s", err, output, content)
+	}
+}
+
+func TestImplementTemplate(t *testing.T) {
+	const src = `package domain
+
+import "context"
+
+type MemberUsecase interface {
+	Get(ctx context.Context, id int64) (*Member, error)
+	Find(names ...string) []Member
+}
+
+type Member struct{ ID int64 }
+`
+
+	const tmpl = `{{define "struct" -}}
+// {{.Struct}} implements {{.InterfaceType}} in {{.Package}}.
+type {{.Struct}} struct {
+{{- range .Methods}}
+	{{.Name}}Func func({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.QualifiedType}}{{end}}) ({{range $i, $r := .Results}}{{if $i}}, {{end}}{{$r.QualifiedType}}{{end}})
+{{- end}}
+}
+{{end}}
+{{define "method" -}}
+// {{.Method.Name}} is {{range .Method.Params}}{{.Name}} {{.Type}}, {{end}}by {{.Receiver}}.
+func ({{.Receiver}} *{{.Struct}}) {{.Method.Name}}{{.Method.Signature}} {
+	panic("{{.Interface}}.{{.Method.Name}}")
+}
+{{end}}`
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(dir, "domain", "member.go"), src)
+	writeFile(t, filepath.Join(dir, "domain", "tmpl", "mock.tmpl"), tmpl)
+
+	ref := InterfaceRef{File: filepath.Join(dir, "domain", "member.go"), Name: "MemberUsecase"}
+	opt := Options{Destination: "../mock/member.go", Package: "mock", Name: "MemberMock", Template: "tmpl"}
+
+	result, err := Implement(context.Background(), ref, opt)
+	if err != nil {
+		t.Fatalf("implement, err: %+v", err)
+	}
+
+	content := string(result.Files[0].Content)
+	for _, expected := range []string{
+		"// MemberMock implements domain.MemberUsecase in mock.",
+		"\tGetFunc  func(context.Context, int64) (*domain.Member, error)",
+		"\tFindFunc func(...string) []domain.Member",
+		"func NewMemberUsecase() (domain.MemberUsecase, error) {",
+		"// Get is ctx context.Context, id int64, by m.\nfunc (m *MemberMock) Get(ctx context.Context, id int64) (*domain.Member, error) {",
+		"// Find is names ...string, by m.\nfunc (m *MemberMock) Find(names ...string) []domain.Member {",
+		`panic("MemberUsecase.Find")`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("%q not found in:\n%s", expected, content)
+		}
+	}
+
+	opt.Template = "unknown.tmpl"
+	if _, err := Implement(context.Background(), ref, opt); err == nil {
+		t.Fatal("expected error of the missing template")
+	}
+}
+
+func TestImplementPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, "base", "base.go"), "package base\n\ntype Closer interface{ Close() error }\n")
+	writeFile(t, filepath.Join(dir, "domain", "member.go"), "package domain\n\nimport \"example.com/app/base\"\n\ntype MemberUsecase interface {\n\tbase.Closer\n\tGet(id int64) (string, error)\n}\n")
+
+	pkgs, err := packages.Load(&packages.Config{Mode: LoadMode, Dir: dir}, "./base", "./domain")
+	if err != nil || len(pkgs) != 2 {
+		t.Fatalf("load packages %+v, err: %+v", pkgs, err)
+	}
+
+	ref := InterfaceRef{File: filepath.Join(dir, "domain", "member.go"), Name: "MemberUsecase"}
+	opt := Options{Destination: "../usecase/member.go", Package: "usecase"}
+
+	want, err := Implement(context.Background(), ref, opt)
+	if err != nil {
+		t.Fatalf("implement, err: %+v", err)
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Name != "domain" {
+			continue
+		}
+
+		got, err := ImplementPackage(context.Background(), pkg, ref, opt)
+		if err != nil {
+			t.Fatalf("implement package, err: %+v", err)
+		}
+
+		if string(got.Files[0].Content) != string(want.Files[0].Content) || !slices.Equal(got.Actions, want.Actions) {
+			t.Fatalf("implementation of the loaded package mismatch, want:\n%s\ngot:\n%s", want.Files[0].Content, got.Files[0].Content)
+		}
+	}
+}
+
+func writeFile(t *testing.T, file, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		t.Fatalf("mkdir, err: %+v", err)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write file, err: %+v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+const (
+	// PluginPrefix is the prefix of the plugin executables, the plugin 'mock' is run by gox-gen-mock in PATH.
+	PluginPrefix = "gox-gen-"
+	// PluginProtocolVersion is the version of PluginRequest and PluginResponse.
+	PluginProtocolVersion = 1
+)
+
+// PluginRequest is written to the stdin of the plugin as JSON, it describes the interface resolved
+// like the implementation of Implement.
+type PluginRequest struct {
+	// Version is PluginProtocolVersion of gox.
+	Version int `json:"version"`
+	// Plugin is the plugin name, e.g. mock.
+	Plugin string `json:"plugin"`
+	// Parameter is the parameter passed to the plugin by 'gox plugin -param'.
+	Parameter string `json:"parameter"`
+	// Source is the package declaring the interface.
+	Source PluginSource `json:"source"`
+	// Destination is the file name of the destination, the plugin usually generates the file of it.
+	Destination string `json:"destination"`
+	// Implement is the interface whose types are qualified for the package of the destination.
+	Implement ImplementData `json:"implement"`
+}
+
+// PluginSource is the package declaring the interface.
+type PluginSource struct {
+	// File is the absolute path of the file declaring the interface.
+	File string `json:"file"`
+	// Package is the package name.
+	Package string `json:"package"`
+	// PackagePath is the import path of the package.
+	PackagePath string `json:"packagePath"`
+}
+
+// PluginResponse is read from the stdout of the plugin as JSON.
+type PluginResponse struct {
+	// Files is the generated files.
+	Files []PluginFile `json:"files"`
+	// Error is the error of the plugin, the files are ignored when it's not empty.
+	Error string `json:"error,omitempty"`
+}
+
+// PluginFile is a file generated by the plugin.
+type PluginFile struct {
+	// Path is the file path relative to the folder of the destination, it can't be outside the folder.
+	Path string `json:"path"`
+	// Content is the content of the file, the go files are formatted by goimports, so the plugins can
+	// import all of Implement.Imports and the unused ones are removed.
+	Content string `json:"content"`
+}
+
+// PluginHandler generates the files of the request in the plugin.
+type PluginHandler func(req *PluginRequest) (*PluginResponse, error)
+
+// ServePlugin runs the handler with the request of the stdin and writes the response to the stdout,
+// it's the main function of the plugins.
+//
+//	func main() {
+//		gen.ServePlugin(func(req *gen.PluginRequest) (*gen.PluginResponse, error) {
+//			return &gen.PluginResponse{Files: []gen.PluginFile{{Path: req.Destination, Content: "package " + req.Implement.Package}}}, nil
+//		})
+//	}
+func ServePlugin(handler PluginHandler) {
+	if err := HandlePlugin(os.Stdin, os.Stdout, handler); err != nil {
+		fmt.Fprintf(os.Stderr, "%s, err: %+v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+}
+
+// HandlePlugin reads the request from r and writes the response of the handler to w, the error of
+// the handler is written as PluginResponse.Error.
+func HandlePlugin(r io.Reader, w io.Writer, handler PluginHandler) error {
+	req := &PluginRequest{}
+	if err := json.NewDecoder(r).Decode(req); err != nil {
+		return fmt.Errorf("decode request, err: %w", err)
+	}
+
+	if req.Version != PluginProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d, the plugin supports %d", req.Version, PluginProtocolVersion)
+	}
+
+	resp, err := handler(req)
+	if err != nil {
+		resp = &PluginResponse{Error: err.Error()}
+	}
+
+	if resp == nil {
+		resp = &PluginResponse{}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return fmt.Errorf("encode response, err: %w", err)
+	}
+
+	return nil
+}
+
+// NewPluginRequest resolves the interface referenced by ref with the options, and returns the
+// request of the plugin.
+func NewPluginRequest(ctx context.Context, ref InterfaceRef, opt Options, plugin, parameter string) (*PluginRequest, error) {
+	req, _, err := newPluginRequest(ctx, ref, opt, plugin, parameter)
+	return req, err
+}
+
+func newPluginRequest(ctx context.Context, ref InterfaceRef, opt Options, plugin, parameter string) (*PluginRequest, *implementer, error) {
+	g, err := resolveImplementer(ctx, nil, ref, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.data.Generator = PluginPrefix + plugin
+
+	return &PluginRequest{
+		Version:   PluginProtocolVersion,
+		Plugin:    plugin,
+		Parameter: parameter,
+		Source: PluginSource{
+			File:        g.sourceFile,
+			Package:     g.sourcePkg.Name(),
+			PackagePath: g.sourcePkg.Path(),
+		},
+		Destination: filepath.Base(g.opt.Destination),
+		Implement:   g.data,
+	}, g, nil
+}
+
+// ExecPlugin runs the plugin executable of the request found in PATH, the stderr of the plugin is
+// passed through.
+func ExecPlugin(ctx context.Context, req *PluginRequest) (*PluginResponse, error) {
+	name := PluginPrefix + req.Plugin
+	exe, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("find plugin %s, err: %w", name, err)
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request of %s, err: %w", name, err)
+	}
+
+	output := bytes.Buffer{}
+	c := exec.CommandContext(ctx, exe)
+	c.Stdin, c.Stdout, c.Stderr = bytes.NewReader(input), &output, os.Stderr
+
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("run plugin %s, err: %w", name, err)
+	}
+
+	resp := &PluginResponse{}
+	if err := json.Unmarshal(output.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("decode response of %s, err: %w", name, err)
+	}
+
+	if len(resp.Error) != 0 {
+		return nil, fmt.Errorf("plugin %s, err: %s", name, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// RunPlugin runs the plugin with the interface referenced by ref, the files of the result are
+// resolved to the folder of the destination.
+func RunPlugin(ctx context.Context, ref InterfaceRef, opt Options, plugin, parameter string) (*Result, error) {
+	req, g, err := newPluginRequest(ctx, ref, opt, plugin, parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ExecPlugin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resp.Result(filepath.Dir(g.opt.Destination))
+	if err != nil {
+		return nil, err
+	}
+
+	result.Type, result.Source = g.data.Interface, g.source
+
+	return result, nil
+}
+
+// Result returns the result of the files in the folder of the destination, the go files are
+// formatted by goimports. The actions of the result create or update the files.
+func (resp *PluginResponse) Result(dir string) (*Result, error) {
+	result := &Result{Files: make([]File, 0, len(resp.Files))}
+	for _, f := range resp.Files {
+		if !filepath.IsLocal(f.Path) {
+			return nil, fmt.Errorf("invalid file path %q of the plugin, it should be relative to the destination folder", f.Path)
+		}
+
+		path := filepath.Join(dir, f.Path)
+		content := []byte(f.Content)
+		if strings.HasSuffix(path, ".go") {
+			formatted, err := imports.Process(path, content, nil)
+			if err != nil {
+				return nil, fmt.Errorf("format %s of the plugin, err: %w", f.Path, err)
+			}
+
+			content = formatted
+		}
+
+		result.Files = append(result.Files, File{Path: path, Content: content})
+		result.Actions = append(result.Actions, FileAction(path))
+	}
+
+	if len(result.Files) == 0 {
+		return nil, errors.New("no file generated by the plugin")
+	}
+
+	return result, nil
+}
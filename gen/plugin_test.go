@@ -0,0 +1,64 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandlePlugin(t *testing.T) {
+	handler := func(req *PluginRequest) (*PluginResponse, error) {
+		if len(req.Parameter) == 0 {
+			return nil, errors.New("parameter not define")
+		}
+
+		return &PluginResponse{Files: []PluginFile{{Path: req.Destination, Content: "package " + req.Implement.Package + "\nfunc  " + req.Parameter + "() {}"}}}, nil
+	}
+
+	handle := func(req PluginRequest) *PluginResponse {
+		input, output := bytes.Buffer{}, bytes.Buffer{}
+		if err := json.NewEncoder(&input).Encode(req); err != nil {
+			t.Fatalf("encode request, err: %+v", err)
+		}
+
+		if err := HandlePlugin(&input, &output, handler); err != nil {
+			t.Fatalf("handle plugin, err: %+v", err)
+		}
+
+		resp := &PluginResponse{}
+		if err := json.NewDecoder(&output).Decode(resp); err != nil {
+			t.Fatalf("decode response, err: %+v", err)
+		}
+
+		return resp
+	}
+
+	req := PluginRequest{Version: PluginProtocolVersion, Parameter: "Hello", Destination: "member.go", Implement: ImplementData{Package: "mock"}}
+	result, err := handle(req).Result("/app/mock")
+	if err != nil {
+		t.Fatalf("result, err: %+v", err)
+	}
+
+	if len(result.Files) != 1 || result.Files[0].Path != filepath.Join("/app/mock", "member.go") || string(result.Files[0].Content) != "package mock\n\nfunc Hello() {}\n" {
+		t.Fatalf("result mismatch: %+v", result.Files)
+	}
+
+	req.Parameter = ""
+	if resp := handle(req); !strings.Contains(resp.Error, "parameter not define") {
+		t.Fatalf("error mismatch: %+v", resp)
+	}
+
+	if err := HandlePlugin(strings.NewReader(`{"version":2}`), &bytes.Buffer{}, handler); err == nil {
+		t.Fatal("expected error of the unsupported version")
+	}
+
+	for _, path := range []string{"", "../member.go", "/app/member.go"} {
+		resp := &PluginResponse{Files: []PluginFile{{Path: path, Content: "package mock\n"}}}
+		if _, err := resp.Result("/app/mock"); err == nil {
+			t.Fatalf("expected error of the invalid path %q", path)
+		}
+	}
+}
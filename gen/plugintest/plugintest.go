@@ -0,0 +1,161 @@
+// Package plugintest is the test harness of the gox plugins. It declares the interface of the test
+// in a temporary module, resolves the request like 'gox plugin' does, and builds the generated
+// files with the module.
+//
+//	func TestMock(t *testing.T) {
+//		h := plugintest.New(t, src, "MemberUsecase", gen.Options{})
+//		resp := h.Run(handle)
+//		h.Build(resp)
+//	}
+package plugintest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanun0323/gox/gen"
+)
+
+// _module is the module name of the temporary module.
+const _module = "example.com/plugintest"
+
+// Harness is the temporary module declaring the interface, and the plugin request of the interface.
+type Harness struct {
+	// Dir is the folder of the temporary module.
+	Dir string
+	// Request is the request of the interface, it can be modified before running the plugin, e.g. the Parameter.
+	Request *gen.PluginRequest
+
+	t       testing.TB
+	destDir string
+}
+
+// New declares the source in the package 'domain' of a temporary module, and resolves the request of
+// the interface. The destination is ../output/output.go of the package 'output' by default.
+func New(t testing.TB, src, interfaceName string, opt gen.Options) *Harness {
+	t.Helper()
+
+	if len(opt.Destination) == 0 {
+		opt.Destination = "../output/output.go"
+	}
+
+	if len(opt.Package) == 0 {
+		opt.Package = "output"
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "domain", "domain.go")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module "+_module+"\n\ngo 1.22\n")
+	writeFile(t, file, src)
+
+	req, err := gen.NewPluginRequest(context.Background(), gen.InterfaceRef{File: file, Name: interfaceName}, opt, "test", "")
+	if err != nil {
+		t.Fatalf("new plugin request, err: %+v", err)
+	}
+
+	destDir := opt.Destination
+	if !filepath.IsAbs(destDir) {
+		destDir = filepath.Join(filepath.Dir(file), destDir)
+	}
+
+	return &Harness{Dir: dir, Request: req, t: t, destDir: filepath.Dir(destDir)}
+}
+
+// Run runs the handler with the request through the JSON protocol, and returns the response.
+func (h *Harness) Run(handler gen.PluginHandler) *gen.PluginResponse {
+	h.t.Helper()
+
+	input, output := bytes.Buffer{}, bytes.Buffer{}
+	if err := json.NewEncoder(&input).Encode(h.Request); err != nil {
+		h.t.Fatalf("encode request, err: %+v", err)
+	}
+
+	if err := gen.HandlePlugin(&input, &output, handler); err != nil {
+		h.t.Fatalf("handle plugin, err: %+v", err)
+	}
+
+	resp := &gen.PluginResponse{}
+	if err := json.NewDecoder(&output).Decode(resp); err != nil {
+		h.t.Fatalf("decode response, err: %+v", err)
+	}
+
+	return resp
+}
+
+// Exec runs the plugin executable like gox does, the name of the executable should be gox-gen-<plugin>.
+func (h *Harness) Exec(exe string) *gen.PluginResponse {
+	h.t.Helper()
+
+	name := filepath.Base(exe)
+	if !strings.HasPrefix(name, gen.PluginPrefix) {
+		h.t.Fatalf("plugin executable %s should be named with %s", name, gen.PluginPrefix)
+	}
+
+	h.t.Setenv("PATH", filepath.Dir(exe)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	h.Request.Plugin = strings.TrimPrefix(name, gen.PluginPrefix)
+
+	resp, err := gen.ExecPlugin(context.Background(), h.Request)
+	if err != nil {
+		h.t.Fatalf("exec plugin, err: %+v", err)
+	}
+
+	return resp
+}
+
+// Build saves the files of the response into the destination folder like gox does, and builds the
+// temporary module. It returns the saved files.
+func (h *Harness) Build(resp *gen.PluginResponse) []gen.File {
+	h.t.Helper()
+
+	if len(resp.Error) != 0 {
+		h.t.Fatalf("plugin error: %s", resp.Error)
+	}
+
+	result, err := resp.Result(h.destDir)
+	if err != nil {
+		h.t.Fatalf("result of the response, err: %+v", err)
+	}
+
+	if err := result.Save(); err != nil {
+		h.t.Fatalf("save result, err: %+v", err)
+	}
+
+	c := exec.Command("go", "build", "./...")
+	c.Dir = h.Dir
+	if output, err := c.CombinedOutput(); err != nil {
+		h.t.Fatalf("build generated files, err: %+v\n%s", err, output)
+	}
+
+	return result.Files
+}
+
+// BuildPlugin builds the main package of the plugin into a temporary folder, and returns the path of
+// the executable named gox-gen-<plugin>.
+func BuildPlugin(t testing.TB, pkg, plugin string) string {
+	t.Helper()
+
+	exe := filepath.Join(t.TempDir(), gen.PluginPrefix+plugin)
+	if output, err := exec.Command("go", "build", "-o", exe, pkg).CombinedOutput(); err != nil {
+		t.Fatalf("build plugin %s, err: %+v\n%s", pkg, err, output)
+	}
+
+	return exe
+}
+
+func writeFile(t testing.TB, file, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		t.Fatalf("mkdir, err: %+v", err)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write file, err: %+v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// _implementTemplates is the default templates of the implementation. The templates overriding
+// them are parsed into the same set, so the templates not overridden keep the defaults.
+const _implementTemplates = `
+{{- define "struct" -}}
+type {{.Struct}} struct {
+{{- if .Replace}}
+	// Replace by {{.Generator}}
+{{- end}}
+	// TODO: Implement {{.Struct}}
+}
+{{end}}
+
+{{- define "constructor" -}}
+func {{.Constructor}}() ({{.InterfaceType}}, error) {
+{{- if .Replace}}
+	// Replace by {{.Generator}}
+{{- end}}
+	// TODO: Implement {{.Constructor}}
+	return &{{.Struct}}{}, nil
+}
+{{end}}
+
+{{- define "method" -}}
+{{with .Method.Doc}}{{.}}
+{{end -}}
+func ({{.Receiver}} *{{.Struct}}) {{.Method.Name}}{{.Method.Signature}} {
+{{- if .Replace}}
+	// Replace by {{.Generator}}
+{{- end}}
+	// TODO: Implement {{.Struct}}.{{.Method.Name}}
+	panic("")
+}
+{{end}}`
+
+// ImplementData is the data of the implementation templates. The template "struct" generates the
+// implementation struct and "constructor" generates the constructor function with it, and
+// "method" generates each missing method with MethodData.
+type ImplementData struct {
+	// Package is the package name of the generated file.
+	Package string `json:"package"`
+	// Interface is the interface name, e.g. MemberUsecase.
+	Interface string `json:"interface"`
+	// InterfaceType is the interface type referenced from the generated file, e.g. domain.MemberUsecase.
+	InterfaceType string `json:"interfaceType"`
+	// Struct is the implementation struct name, e.g. memberUsecase.
+	Struct string `json:"struct"`
+	// Receiver is the receiver name of the methods, it's the receiver of the existing methods if there are.
+	Receiver string `json:"receiver"`
+	// Constructor is the constructor function name, e.g. NewMemberUsecase.
+	Constructor string `json:"constructor"`
+	// Generator is the generator name written in the comments of the replaced code.
+	Generator string `json:"generator"`
+	// Replace reports whether the existing implementation is replaced.
+	Replace bool `json:"replace"`
+	// Imports is the packages referenced by the qualified types, sorted by the paths.
+	Imports []Import `json:"imports"`
+	// Methods is all the methods of the interface, including the existing ones.
+	Methods []Method `json:"methods"`
+}
+
+// MethodData is the data of the template "method".
+type MethodData struct {
+	ImplementData
+	// Method is the method to generate.
+	Method Method `json:"method"`
+}
+
+// Import is a package imported by the generated file.
+type Import struct {
+	// Name is the alias of the package, it's empty unless the package name differs from the last element of the path.
+	Name string `json:"name"`
+	// Path is the import path of the package.
+	Path string `json:"path"`
+}
+
+// Method is a method of the interface.
+type Method struct {
+	// Name is the method name.
+	Name string `json:"name"`
+	// Params is the parameters of the method.
+	Params []Var `json:"params"`
+	// Results is the results of the method.
+	Results []Var `json:"results"`
+	// Variadic reports whether the last parameter is variadic, its types are written with '...'.
+	Variadic bool `json:"variadic"`
+	// Signature is the qualified signature without 'func' and the name, e.g. (ctx context.Context, id int64) (*domain.Member, error).
+	// The parameters and the results are grouped like the interface, e.g. (a, b int).
+	Signature string `json:"signature"`
+	// Doc is the doc comment of the method in the interface, e.g. '// Get returns the member.', it's
+	// empty without the doc comment or the source of the interface.
+	Doc string `json:"doc"`
+}
+
+// Var is a parameter or a result of a method.
+type Var struct {
+	// Name is the name of the variable, it's empty if it isn't named in the interface.
+	Name string `json:"name"`
+	// Type is the type written in the package of the interface, e.g. *Member.
+	Type string `json:"type"`
+	// QualifiedType is the type referenced from the generated file, e.g. *domain.Member.
+	QualifiedType string `json:"qualifiedType"`
+}
+
+// loadTemplates returns the default templates overridden by the template file, or the *.tmpl files
+// of the template folder.
+func loadTemplates(path string) (*template.Template, error) {
+	tmpl := template.Must(template.New("implement").Parse(_implementTemplates))
+	if len(path) == 0 {
+		return tmpl, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat template %s, err: %w", path, err)
+	}
+
+	if info.IsDir() {
+		tmpl, err = tmpl.ParseGlob(filepath.Join(path, "*.tmpl"))
+	} else {
+		tmpl, err = tmpl.ParseFiles(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s, err: %w", path, err)
+	}
+
+	return tmpl, nil
+}
+
+// executeTemplate returns the text of the template executed with the data.
+func executeTemplate(tmpl *template.Template, name string, data any) (string, error) {
+	buf := bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("execute template %s, err: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// newMethod returns the method of the function, the types are written by the source qualifier and
+// the destination qualifier. The field of the method in the interface groups the parameters and the
+// results of the signature and provides the doc comment, it's nil without the source of the interface.
+func newMethod(fn *types.Func, field *ast.Field, source types.Qualifier, q *qualifier) Method {
+	sig := fn.Type().(*types.Signature)
+
+	method := Method{
+		Name:     fn.Name(),
+		Params:   newVars(sig.Params(), sig.Variadic(), source, q),
+		Results:  newVars(sig.Results(), false, source, q),
+		Variadic: sig.Variadic(),
+	}
+
+	var ft *ast.FuncType
+	if field != nil {
+		ft, _ = field.Type.(*ast.FuncType)
+		if field.Doc != nil {
+			lines := make([]string, 0, len(field.Doc.List))
+			for _, c := range field.Doc.List {
+				lines = append(lines, c.Text)
+			}
+
+			method.Doc = strings.Join(lines, "\n")
+		}
+	}
+
+	if ft == nil || !writeGroupedSignature(&method, ft) {
+		signature := bytes.Buffer{}
+		types.WriteSignature(&signature, sig, q.Qualify)
+		method.Signature = signature.String()
+	}
+
+	return method
+}
+
+// writeGroupedSignature sets the signature of the method with the parameters and the results
+// grouped like the fields of the function type, e.g. (a, b int). It returns false when the fields
+// don't match the method.
+func writeGroupedSignature(m *Method, ft *ast.FuncType) bool {
+	params, ok := groupedTuple(m.Params, ft.Params)
+	if !ok {
+		return false
+	}
+
+	results, ok := groupedTuple(m.Results, ft.Results)
+	if !ok {
+		return false
+	}
+
+	m.Signature = params
+	switch {
+	case len(m.Results) == 0:
+	case len(m.Results) == 1 && len(m.Results[0].Name) == 0:
+		m.Signature += " " + m.Results[0].QualifiedType
+	default:
+		m.Signature += " " + results
+	}
+
+	return true
+}
+
+// groupedTuple returns the parenthesized variables grouped like the fields.
+func groupedTuple(vars []Var, fields *ast.FieldList) (string, bool) {
+	var list []*ast.Field
+	if fields != nil {
+		list = fields.List
+	}
+
+	var (
+		groups = make([]string, 0, len(list))
+		i      = 0
+	)
+
+	for _, f := range list {
+		n := max(len(f.Names), 1)
+		if i+n > len(vars) {
+			return "", false
+		}
+
+		group := vars[i].QualifiedType
+		if len(f.Names) != 0 {
+			names := make([]string, 0, n)
+			for _, v := range vars[i : i+n] {
+				names = append(names, v.Name)
+			}
+
+			group = strings.Join(names, ", ") + " " + group
+		}
+
+		groups = append(groups, group)
+		i += n
+	}
+
+	if i != len(vars) {
+		return "", false
+	}
+
+	return "(" + strings.Join(groups, ", ") + ")", true
+}
+
+func newVars(tuple *types.Tuple, variadic bool, source types.Qualifier, q *qualifier) []Var {
+	result := make([]Var, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		typ, prefix := v.Type(), ""
+		if variadic && i == tuple.Len()-1 {
+			if s, ok := typ.(*types.Slice); ok {
+				typ, prefix = s.Elem(), "..."
+			}
+		}
+
+		result = append(result, Var{
+			Name:          v.Name(),
+			Type:          prefix + types.TypeString(typ, source),
+			QualifiedType: prefix + types.TypeString(typ, q.Qualify),
+		})
+	}
+
+	return result
+}
+
+// sourceQualifier qualifies the types of the other packages by the package names, like they're
+// written in the package of the interface.
+func sourceQualifier(pkg *types.Package) types.Qualifier {
+	return func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+
+		return p.Name()
+	}
+}
+
+// importList returns the imports of the qualifier sorted by the paths.
+func importList(q *qualifier) []Import {
+	result := make([]Import, 0, len(q.names))
+	for pkgPath, name := range q.Imports() {
+		result = append(result, Import{Name: name, Path: pkgPath})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
@@ -0,0 +1,290 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadMode is the information of the source package resolving the identifiers of the interface, the
+// packages passed to ImplementPackage are loaded by it.
+const LoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
+
+// loadPackage loads and type checks the package in the folder.
+func loadPackage(ctx context.Context, dir string) (*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Dir: dir, Mode: LoadMode}, ".")
+	if err != nil {
+		return nil, fmt.Errorf("load package %s, err: %w", dir, err)
+	}
+
+	if len(pkgs) != 1 || pkgs[0].Types == nil {
+		return nil, fmt.Errorf("load package %s, err: package not found", dir)
+	}
+
+	return pkgs[0], nil
+}
+
+// methodFields returns the fields of the interface methods declared in the package, keyed by the
+// positions of the method names.
+func methodFields(pkg *packages.Package) map[token.Pos]*ast.Field {
+	result := map[token.Pos]*ast.Field{}
+	for _, f := range pkg.Syntax {
+		ast.Inspect(f, func(n ast.Node) bool {
+			iface, ok := n.(*ast.InterfaceType)
+			if !ok {
+				return true
+			}
+
+			for _, field := range iface.Methods.List {
+				if _, ok := field.Type.(*ast.FuncType); ok && len(field.Names) == 1 {
+					result[field.Names[0].Pos()] = field
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+// packageError returns the first error of the package, the errors positioned in the source files
+// are preferred. The generation continues with the errors unless the target resolves to the invalid
+// types.
+func packageError(pkg *packages.Package) error {
+	var result, positioned error
+	packages.Visit([]*packages.Package{pkg}, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			if result == nil {
+				result = e
+			}
+
+			if positioned == nil && len(e.Pos) != 0 && e.Pos != "-" {
+				positioned = e
+			}
+		}
+	})
+
+	if positioned != nil {
+		return positioned
+	}
+
+	return result
+}
+
+// findInterfaceType returns the interface type of the name, or the interface declared right after
+// the go:generate directive in the file.
+func findInterfaceType(pkg *packages.Package, file string, goLine int, name string) (*types.Named, error) {
+	if len(name) == 0 {
+		spec, err := findTypeSpecBelow(pkg, file, goLine)
+		if err != nil {
+			return nil, err
+		}
+
+		name = spec.Name.Name
+	}
+
+	obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("find interface %s, err: %w", name, ErrNotFound)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok || !types.IsInterface(named) {
+		return nil, &PositionError{Pos: pkg.Fset.Position(obj.Pos()), Err: fmt.Errorf("%s isn't an interface", name)}
+	}
+
+	if named.TypeParams().Len() != 0 {
+		return nil, &PositionError{Pos: pkg.Fset.Position(obj.Pos()), Err: fmt.Errorf("generic interface %s isn't supported", name)}
+	}
+
+	return named, nil
+}
+
+// findTypeSpecBelow returns the type declared right after the go:generate directive, only the
+// comments can be put between them.
+func findTypeSpecBelow(pkg *packages.Package, file string, goLine int) (*ast.TypeSpec, error) {
+	for _, f := range pkg.Syntax {
+		if filepath.Clean(pkg.Fset.Position(f.Pos()).Filename) != filepath.Clean(file) {
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			if pkg.Fset.Position(decl.End()).Line <= goLine {
+				continue
+			}
+
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE || len(gen.Specs) == 0 {
+				break
+			}
+
+			if start := pkg.Fset.Position(gen.Pos()).Line; start > goLine+1 && !commentedUpTo(pkg.Fset, gen.Doc, goLine) {
+				break
+			}
+
+			return gen.Specs[0].(*ast.TypeSpec), nil
+		}
+	}
+
+	return nil, fmt.Errorf("find target interface, err: %w", ErrNotFound)
+}
+
+// commentedUpTo reports whether the doc comments fill the lines between the directive and the declaration.
+func commentedUpTo(fset *token.FileSet, doc *ast.CommentGroup, goLine int) bool {
+	return doc != nil && fset.Position(doc.Pos()).Line <= goLine+1
+}
+
+// interfaceMethods returns the methods of the interface, the explicit methods in the declared order
+// are followed by the methods of the embedded interfaces.
+func interfaceMethods(iface *types.Interface, noEmbed bool, seen map[string]bool) []*types.Func {
+	explicit := make([]*types.Func, 0, iface.NumExplicitMethods())
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		explicit = append(explicit, iface.ExplicitMethod(i))
+	}
+
+	sort.SliceStable(explicit, func(i, j int) bool { return explicit[i].Pos() < explicit[j].Pos() })
+
+	result := make([]*types.Func, 0, iface.NumMethods())
+	for _, fn := range explicit {
+		if !seen[fn.Name()] {
+			seen[fn.Name()] = true
+			result = append(result, fn)
+		}
+	}
+
+	if noEmbed {
+		return result
+	}
+
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		if embedded, ok := iface.EmbeddedType(i).Underlying().(*types.Interface); ok {
+			result = append(result, interfaceMethods(embedded, false, seen)...)
+		}
+	}
+
+	return result
+}
+
+// findUnexported returns the unexported type of the package referenced by the type, which can't be
+// referenced by the other packages.
+func findUnexported(t types.Type, pkg *types.Package) (*types.TypeName, bool) {
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() == pkg && !obj.Exported() {
+			return obj, true
+		}
+
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if obj, ok := findUnexported(t.TypeArgs().At(i), pkg); ok {
+				return obj, true
+			}
+		}
+	case *types.Alias:
+		if obj := t.Obj(); obj.Pkg() == pkg && !obj.Exported() {
+			return obj, true
+		}
+	case *types.Pointer:
+		return findUnexported(t.Elem(), pkg)
+	case *types.Slice:
+		return findUnexported(t.Elem(), pkg)
+	case *types.Array:
+		return findUnexported(t.Elem(), pkg)
+	case *types.Chan:
+		return findUnexported(t.Elem(), pkg)
+	case *types.Map:
+		if obj, ok := findUnexported(t.Key(), pkg); ok {
+			return obj, true
+		}
+
+		return findUnexported(t.Elem(), pkg)
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if obj, ok := findUnexported(tuple.At(i).Type(), pkg); ok {
+					return obj, true
+				}
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if obj, ok := findUnexported(t.Field(i).Type(), pkg); ok {
+				return obj, true
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			if obj, ok := findUnexported(t.Method(i).Type(), pkg); ok {
+				return obj, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// qualifier qualifies the types of the other packages by their names, and records the imports of
+// them for the generated file.
+type qualifier struct {
+	pkgPath string
+	names   map[string]string
+	paths   map[string]string
+	// taken is the identifiers of the generated code, the packages can't be named by them.
+	taken map[string]bool
+}
+
+func newQualifier(pkgPath string) *qualifier {
+	return &qualifier{pkgPath: pkgPath, names: map[string]string{}, paths: map[string]string{}, taken: map[string]bool{}}
+}
+
+// Reserve takes the identifiers of the generated code, like the struct, receiver and parameter
+// names, so the packages qualified afterward are aliased instead of colliding with them.
+func (q *qualifier) Reserve(names ...string) {
+	for _, name := range names {
+		q.taken[name] = true
+	}
+}
+
+// Qualify is the types.Qualifier, the packages having the same name or the name of a reserved
+// identifier are aliased by the numbered names.
+func (q *qualifier) Qualify(p *types.Package) string {
+	if p.Path() == q.pkgPath {
+		return ""
+	}
+
+	if name, ok := q.names[p.Path()]; ok {
+		return name
+	}
+
+	name := p.Name()
+	for i := 2; len(q.paths[name]) != 0 || q.taken[name]; i++ {
+		name = p.Name() + strconv.Itoa(i)
+	}
+
+	q.names[p.Path()] = name
+	q.paths[name] = p.Path()
+	return name
+}
+
+// Imports returns the imports of the qualified packages keyed by the import paths, the values are
+// the aliases of the packages whose names differ from the last elements of the paths.
+func (q *qualifier) Imports() map[string]string {
+	result := make(map[string]string, len(q.names))
+	for pkgPath, name := range q.names {
+		if path.Base(pkgPath) == name {
+			name = ""
+		}
+
+		result[pkgPath] = name
+	}
+
+	return result
+}
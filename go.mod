@@ -1,11 +1,16 @@
 module github.com/yanun0323/gox
 
-go 1.23.3
+go 1.25.0
 
-require github.com/yanun0323/goast v1.2.6
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/yanun0323/goast v1.2.6
+	golang.org/x/tools v0.44.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	golang.org/x/mod v0.22.0 // indirect
-	golang.org/x/sync v0.9.0 // indirect
-	golang.org/x/tools v0.27.0 // indirect
+	golang.org/x/mod v0.36.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
 )
@@ -0,0 +1,156 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/report"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+var (
+	// _inProcess reports whether a command is run by RunIn, the flag errors are returned instead of
+	// exiting the process.
+	_inProcess bool
+	// _inProcessFlags is the flag sets parsed by the command run by RunIn, they're restored after
+	// the run.
+	_inProcessFlags []*flag.FlagSet
+)
+
+// Command is a subcommand of gox. The generators are also installed as the standalone commands
+// named by their aliases, so the existing go:generate directives keep working.
+type Command struct {
+	// Name is the subcommand name, e.g. 'model' of 'gox model'.
+	Name string
+	// Alias is the standalone command name, e.g. 'modelgen'.
+	Alias string
+	// Summary is the one line description shown in the help of gox.
+	Summary string
+	// Run runs the command with the arguments following the command name.
+	Run func(args []string) error
+}
+
+// Main runs the command as a standalone command with the arguments of the process.
+func Main(cmd Command) {
+	util.NoError(cmd.Run(os.Args[1:]))
+}
+
+// NewFlagSet returns the flag set of the command, the flags of the commands are separated so that
+// they can be registered into the same binary.
+func NewFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
+
+// Parse sets the log prefix and the usage of the command, and parses the arguments by the flags.
+// The flags are reset to their defaults first, since the commands run by RunIn parse them again in
+// the same process.
+func Parse(flags *flag.FlagSet, usage func(), args []string) error {
+	log.SetFlags(0)
+	log.SetPrefix(flags.Name() + ": ")
+	flags.Usage = usage
+
+	flags.VisitAll(func(f *flag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+	})
+
+	if _inProcess {
+		// the errors and the usage are written to the writer of RunIn
+		flags.Init(flags.Name(), flag.ContinueOnError)
+		flags.SetOutput(log.Writer())
+		_inProcessFlags = append(_inProcessFlags, flags)
+	}
+
+	// the flag set exits on the parsing errors unless it's run by RunIn
+	return flags.Parse(args)
+}
+
+// RunIn runs the command in the current process like running it as a process in the folder with the
+// environment, e.g. the go:generate environment of a directive, and the logs and the reports of the
+// command are written to w. The working directory, the environment and the flags of the process are
+// changed until it returns, so the runs must not overlap with each other, nor with the code
+// depending on them.
+func (cmd Command) RunIn(dir string, env []string, args []string, w io.Writer) (err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory, err: %w", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("change directory to %s, err: %w", dir, err)
+	}
+	defer os.Chdir(cwd)
+
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if previous, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, previous)
+		} else {
+			defer os.Unsetenv(name)
+		}
+
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("set environment %s, err: %w", name, err)
+		}
+	}
+
+	logPrefix, logFlags, logOutput := log.Prefix(), log.Flags(), log.Writer()
+	defer func() {
+		log.SetPrefix(logPrefix)
+		log.SetFlags(logFlags)
+		log.SetOutput(logOutput)
+	}()
+
+	reports := report.Writer()
+	defer report.SetOutput(reports)
+
+	log.SetOutput(w)
+	report.SetOutput(w)
+
+	_inProcess = true
+	defer func() {
+		_inProcess = false
+		for _, flags := range _inProcessFlags {
+			flags.Init(flags.Name(), flag.ExitOnError)
+			flags.SetOutput(nil)
+		}
+
+		_inProcessFlags = nil
+
+		// the panic of a generator fails its run like the process
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s panic: %v", cmd.Name, r)
+		}
+	}()
+
+	return cmd.Run(args)
+}
+
+// Find returns the command of the name or the alias.
+func Find(cmds []Command, name string) (Command, bool) {
+	for _, cmd := range cmds {
+		if cmd.Name == name || cmd.Alias == name {
+			return cmd, true
+		}
+	}
+
+	return Command{}, false
+}
+
+// PrintUsage prints the usage of gox listing the commands.
+func PrintUsage(cmds []Command) {
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "gox: generate go code from the declaration below the go:generate directive\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\tusage: gox <command> [flags]\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	for _, cmd := range cmds {
+		fmt.Fprintf(os.Stderr, "\t%-10s%s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\trun 'gox <command> -h' for the flags of the command\n")
+	fmt.Fprintf(os.Stderr, "\n")
+}
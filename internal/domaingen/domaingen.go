@@ -0,0 +1,249 @@
+package domaingen
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/report"
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	_commandName    = "domaingen"
+	_subcommandName = "domain"
+)
+
+var (
+	_flags = command.NewFlagSet(_commandName)
+	_opt   = newOptions(_flags)
+)
+
+// options is the flags of the command, they're defined by newOptions on the flag set of the command
+// and on the flag set parsing the arguments of ParseTarget.
+type options struct {
+	help          *bool
+	debug         *bool
+	replace       *bool
+	noEmbed       *bool
+	destination   *string
+	pkg           *string
+	noStruct      *bool
+	name          *string
+	typeNames     *string
+	noConstructor *bool
+	template      *string
+	json          *bool
+}
+
+func newOptions(flags *flag.FlagSet) options {
+	return options{
+		help:          flags.Bool("h", false, "show command help"),
+		debug:         flags.Bool("v", false, "show debug information"),
+		replace:       flags.Bool("replace", false, "replace all structure and method if there's already a same structure"),
+		noEmbed:       flags.Bool("noembed", false, "skip implementing embed interface functions"),
+		destination:   flags.String("destination", "", "target file name to generate implementation"),
+		pkg:           flags.String("package", "", "target implementation structure name"),
+		noStruct:      flags.Bool("noStruct", false, "generate struct"),
+		name:          flags.String("name", "", "target implementation structure name"),
+		typeNames:     flags.String("type", "", "comma separated target interface names in the package, instead of the interface below the go:generate directive"),
+		noConstructor: flags.Bool("noConstructor", false, "generate constructor function"),
+		template:      flags.String("template", "", "template file or folder of the *.tmpl files overriding the struct, constructor and method templates"),
+		json:          flags.Bool("json", false, "print the report of each interface as a JSON line"),
+	}
+}
+
+// implementOptions returns the options of gen.Implement.
+func (o options) implementOptions() gen.Options {
+	return gen.Options{
+		Destination:   *o.destination,
+		Package:       *o.pkg,
+		Name:          *o.name,
+		Replace:       *o.replace,
+		NoEmbed:       *o.noEmbed,
+		NoStruct:      *o.noStruct,
+		NoConstructor: *o.noConstructor,
+		Template:      *o.template,
+	}
+}
+
+// Usage is a replacement usage function for the flags package.
+func Usage() {
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "%s: generate an implementation from the interface \n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\t-h\t\t\t\tshow usage\n")
+	fmt.Fprintf(os.Stderr, "\t-noStruct\t\t\tskip generate struct\n")
+	fmt.Fprintf(os.Stderr, "\t-name\t\t\t\timplemented struct name\t\t\t-name=usecase\n")
+	fmt.Fprintf(os.Stderr, "\t-type\t\t\t\ttarget interface names in the package\t-type=MemberUsecase,MemberRepository\n")
+	fmt.Fprintf(os.Stderr, "\t-noConstructor\t\t\tskip generate constructor function\n")
+	fmt.Fprintf(os.Stderr, "\t-package\t(require)\timplemented struct package name\n")
+	fmt.Fprintf(os.Stderr, "\t-destination\t(require)\tgenerated file path\t\t\t-destination=../../usecase/member_usecase.go\n")
+	fmt.Fprintf(os.Stderr, "\t-replace\t\t\tforce replace exist struct/func/method\n")
+	fmt.Fprintf(os.Stderr, "\t-template\t\t\ttemplate file or folder overriding the code\t-template=./tmpl/impl.tmpl\n")
+	fmt.Fprintf(os.Stderr, "\t-json\t\t\t\tprint the report of each interface as a JSON line\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\texample:\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\t//go:generate %s -destination=../../usecase/member.go -name=usecase -replace -constructor\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// Command is the domain command of gox, it's also installed as domaingen.
+var Command = command.Command{
+	Name:    _subcommandName,
+	Alias:   _commandName,
+	Summary: "generate an implementation from the interface",
+	Run:     run,
+}
+
+func run(args []string) error {
+	if err := helper.setupLog(args); err != nil {
+		return err
+	}
+
+	helper.debugPrint()
+
+	if *_opt.help {
+		_flags.Usage()
+		return nil
+	}
+
+	rep := report.New(_subcommandName, *_opt.json)
+
+	return rep.Finish(generate(rep))
+}
+
+func generate(rep *report.Reporter) error {
+	if err := helper.requireTag(); err != nil {
+		return err
+	}
+
+	dir, file, err := helper.GetDir()
+	if err != nil {
+		return fmt.Errorf("get directory, err: %w", err)
+	}
+
+	t, err := newTarget(dir, file, os.Getenv("GOLINE"), _opt)
+	if err != nil {
+		return err
+	}
+
+	reports, err := t.Generate(context.Background(), nil)
+	for _, rp := range reports {
+		rep.Write(rp)
+	}
+
+	return err
+}
+
+// Target is the interfaces implemented by a run of the command, it's resolved by ParseTarget so
+// that 'gox generate' runs the directives in its own process.
+type Target struct {
+	// Dir is the package folder of the interfaces.
+	Dir string
+	// Refs is the interfaces to implement.
+	Refs []gen.InterfaceRef
+	// Options is the options of the implementations.
+	Options gen.Options
+}
+
+// ParseTarget returns the target of the command run by the arguments in the package folder with the
+// go:generate environment of the file and the line, the line is empty for the config targets.
+func ParseTarget(dir, file, line string, args []string) (Target, error) {
+	flags := flag.NewFlagSet(_commandName, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	opt := newOptions(flags)
+	if err := flags.Parse(args); err != nil {
+		return Target{}, err
+	}
+
+	if len(*opt.destination) == 0 {
+		return Target{}, errors.New("destination not define")
+	}
+
+	if len(*opt.pkg) == 0 {
+		return Target{}, errors.New("package not define")
+	}
+
+	return newTarget(dir, file, line, opt)
+}
+
+// newTarget returns the target of the options run in the package folder.
+func newTarget(dir, file, line string, opt options) (Target, error) {
+	refs, err := FindInterfaceRefs(dir, file, line, helper.SplitList(*opt.typeNames), *opt.name)
+	if err != nil {
+		return Target{}, err
+	}
+
+	implementOptions := opt.implementOptions()
+	if !filepath.IsAbs(implementOptions.Destination) {
+		implementOptions.Destination = filepath.Join(dir, implementOptions.Destination)
+	}
+
+	return Target{Dir: dir, Refs: refs, Options: implementOptions}, nil
+}
+
+// Generate implements the interfaces of the target in the package, and returns the reports of the
+// interfaces. The results are saved one by one, so the implementations in the same destination are
+// merged. The package is loaded by gen.Implement when it's nil.
+func (t Target) Generate(ctx context.Context, pkg *packages.Package) ([]report.Report, error) {
+	reports := make([]report.Report, 0, len(t.Refs))
+	for _, ref := range t.Refs {
+		rp := report.Report{Command: _subcommandName, Type: ref.Name, Source: report.NewPosition(ref.File, ref.Line), Destination: t.Options.Destination}
+		result, err := gen.ImplementPackage(ctx, pkg, ref, t.Options)
+		if err == nil {
+			rp, err = report.Result(result), result.Save()
+			rp.Command = _subcommandName
+		}
+
+		if err != nil {
+			rp.Error = report.NewError(err, rp.Source)
+		}
+
+		reports = append(reports, rp)
+		if err != nil {
+			return reports, err
+		}
+	}
+
+	return reports, nil
+}
+
+// FindInterfaceRefs returns the interfaces of the type names declared in the package folder, or the
+// interface below the go:generate directive at the line of the file when no type name is given. The
+// implementation name can't be shared by several interfaces, it's also used by 'gox plugin'.
+func FindInterfaceRefs(dir, file, line string, typeNames []string, name string) ([]gen.InterfaceRef, error) {
+	if len(typeNames) == 0 {
+		goLine, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse GOLINE, err: %w", err)
+		}
+
+		return []gen.InterfaceRef{{File: file, Line: goLine}}, nil
+	}
+
+	if len(typeNames) > 1 && len(name) != 0 {
+		return nil, errors.New("-name can't be used with multiple -type")
+	}
+
+	refs := make([]gen.InterfaceRef, 0, len(typeNames))
+	for _, typeName := range typeNames {
+		file, _, _, err := helper.FindTypeSpec(dir, typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		refs = append(refs, gen.InterfaceRef{File: file, Name: typeName})
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,41 @@
+package domaingen
+
+import (
+	"errors"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+var helper = helperInstance{}
+
+type helperInstance struct {
+	util.Helper
+}
+
+func (helperInstance) setupLog(args []string) error {
+	return command.Parse(_flags, Usage, args)
+}
+
+func (helperInstance) requireTag() error {
+	if len(*_opt.destination) == 0 {
+		_flags.Usage()
+		return errors.New("entity/use/repo at least one param provide")
+	}
+
+	if len(*_opt.pkg) == 0 {
+		_flags.Usage()
+		return errors.New("package not define")
+	}
+
+	return nil
+}
+
+func (helperInstance) debugPrint() {
+	if *_opt.debug {
+		println()
+		println("\t", "replace", "=", *_opt.replace)
+		println("\t", "name", "=", *_opt.destination)
+		println()
+	}
+}
@@ -0,0 +1,368 @@
+package enumgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_transformSnake = "snake"
+	_transformKebab = "kebab"
+	_transformLower = "lower"
+	_transformUpper = "upper"
+)
+
+// enumKind is the kind of the underlying type of the enum.
+type enumKind int
+
+const (
+	enumSigned enumKind = iota
+	enumUnsigned
+	enumString
+)
+
+// enum is the named type and its constants declared in the package.
+type enum struct {
+	Package string
+	Name    string
+	Kind    enumKind
+	// Values is the constants of the distinct values in declaration order.
+	Values []enumValue
+	// declared is the methods of the enum and the functions already declared outside the destination.
+	declared map[string]bool
+}
+
+type enumValue struct {
+	// Const is the constant name, Name is the name used by String and Parse.
+	Const string
+	Name  string
+}
+
+// loadEnum type checks the package in the folder without the destination file, and collects the
+// constants of the enum type.
+func loadEnum(dir, typeName, destination string) (*enum, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("import dir %s, err: %w", dir, err)
+	}
+
+	var (
+		fset  = token.NewFileSet()
+		files []*ast.File
+	)
+
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		file := filepath.Join(dir, name)
+		if helper.AbsPath(file) == destination {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parse file %s, err: %w", file, err)
+		}
+
+		files = append(files, f)
+	}
+
+	return parseEnum(fset, files, typeName)
+}
+
+// parseEnum collects the constants of the enum type from the files of the package.
+//
+// The type errors are ignored, the imports unrelated to the constants may not be resolved.
+func parseEnum(fset *token.FileSet, files []*ast.File, typeName string) (*enum, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no go file found")
+	}
+
+	var (
+		conf = types.Config{Importer: importer.Default(), Error: func(error) {}}
+		info = &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	)
+
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", typeName)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("type %s is an alias", typeName)
+	}
+
+	e := &enum{
+		Package:  pkg.Name(),
+		Name:     typeName,
+		declared: map[string]bool{},
+	}
+
+	basic, ok := named.Underlying().(*types.Basic)
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unsupported underlying type %s of %s", named.Underlying(), typeName)
+	case basic.Info()&types.IsUnsigned != 0:
+		e.Kind = enumUnsigned
+	case basic.Info()&types.IsInteger != 0:
+		e.Kind = enumSigned
+	case basic.Info()&types.IsString != 0:
+		e.Kind = enumString
+	default:
+		return nil, fmt.Errorf("unsupported underlying type %s of %s", basic, typeName)
+	}
+
+	for i := 0; i < named.NumMethods(); i++ {
+		e.declared[typeName+"."+named.Method(i).Name()] = true
+	}
+
+	for _, name := range []string{"Parse" + typeName, typeName + "Values"} {
+		if pkg.Scope().Lookup(name) != nil {
+			e.declared[name] = true
+		}
+	}
+
+	var (
+		values = map[string]string{}
+		names  = map[string]string{}
+	)
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, ident := range vs.Names {
+					c, ok := info.Defs[ident].(*types.Const)
+					if !ok || ident.Name == "_" || !types.Identical(c.Type(), named) || c.Val().Kind() == constant.Unknown {
+						continue
+					}
+
+					// the aliases of the declared values are skipped like stringer does
+					value := c.Val().ExactString()
+					if _, ok := values[value]; ok {
+						continue
+					}
+
+					name := enumName(ident.Name)
+					if comment, ok := lineComment(vs); ok && *_lineComment {
+						name = comment
+					}
+
+					if other, ok := names[name]; ok {
+						return nil, fmt.Errorf("duplicate name %q of %s and %s", name, other, ident.Name)
+					}
+
+					values[value] = ident.Name
+					names[name] = ident.Name
+					e.Values = append(e.Values, enumValue{Const: ident.Name, Name: name})
+				}
+			}
+		}
+	}
+
+	if len(e.Values) == 0 {
+		return nil, fmt.Errorf("no constant of %s found", typeName)
+	}
+
+	return e, nil
+}
+
+// lineComment returns the trailing line comment of the constant spec declaring one constant, the
+// quotes of a quoted comment like "in progress" are stripped.
+func lineComment(vs *ast.ValueSpec) (string, bool) {
+	if len(vs.Names) != 1 || vs.Comment == nil {
+		return "", false
+	}
+
+	comment := strings.TrimSpace(vs.Comment.Text())
+	if unquoted, err := strconv.Unquote(comment); err == nil {
+		comment = unquoted
+	}
+
+	return comment, len(comment) != 0
+}
+
+// enumName returns the name of the constant with -trimprefix and -transform applied.
+func enumName(constName string) string {
+	name := strings.TrimPrefix(constName, *_trimPrefix)
+	if len(name) == 0 {
+		name = constName
+	}
+
+	switch *_transform {
+	case _transformSnake:
+		return helper.SnakeCase(name)
+	case _transformKebab:
+		return strings.ReplaceAll(helper.SnakeCase(name), "_", "-")
+	case _transformLower:
+		return strings.ToLower(name)
+	case _transformUpper:
+		return strings.ToUpper(name)
+	}
+
+	return name
+}
+
+// ImportPaths returns the imports used by the generated methods.
+func (e *enum) ImportPaths() []string {
+	if e.Kind == enumString {
+		return []string{"database/sql/driver", "fmt"}
+	}
+
+	return []string{"database/sql/driver", "fmt", "strconv"}
+}
+
+// ShouldGenerate reports whether the method or function isn't declared outside the destination.
+func (e *enum) ShouldGenerate(key string) bool {
+	return !e.declared[key]
+}
+
+// generateEnum generates String, Parse, Values, IsValid, the text marshaling methods, and the
+// sql.Scanner and driver.Valuer implementations of the enum.
+func generateEnum(e *enum) []merge.Scope {
+	var (
+		r        = string(helper.FirstLowerCase(e.Name)[0])
+		consts   = make([]string, 0, len(e.Values))
+		zero     = "0"
+		valueFmt = "%d"
+		result   []merge.Scope
+	)
+
+	for _, v := range e.Values {
+		consts = append(consts, v.Const)
+	}
+
+	if e.Kind == enumString {
+		zero, valueFmt = `""`, "%q"
+	}
+
+	add := func(key, text string) {
+		if e.ShouldGenerate(key) {
+			result = append(result, merge.Scope{Key: key, Text: text})
+		}
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// String returns the name of the %s.\n", e.Name))
+	buf.WriteString(fmt.Sprintf("func (%s %s) String() string {\n\tswitch %s {\n", r, e.Name, r))
+	for _, v := range e.Values {
+		buf.WriteString(fmt.Sprintf("\tcase %s:\n\t\treturn %q\n", v.Const, v.Name))
+	}
+	buf.WriteString("\t}\n\n")
+	switch e.Kind {
+	case enumSigned:
+		buf.WriteString(fmt.Sprintf("\treturn \"%s(\" + strconv.FormatInt(int64(%s), 10) + \")\"\n}\n", e.Name, r))
+	case enumUnsigned:
+		buf.WriteString(fmt.Sprintf("\treturn \"%s(\" + strconv.FormatUint(uint64(%s), 10) + \")\"\n}\n", e.Name, r))
+	case enumString:
+		buf.WriteString(fmt.Sprintf("\treturn \"%s(\" + string(%s) + \")\"\n}\n", e.Name, r))
+	}
+	add(e.Name+".String", buf.String())
+
+	buf.Reset()
+	buf.WriteString(fmt.Sprintf("// Parse%s returns the %s of the name, it returns an error for the undeclared names.\n", e.Name, e.Name))
+	buf.WriteString(fmt.Sprintf("func Parse%s(name string) (%s, error) {\n\tswitch name {\n", e.Name, e.Name))
+	for _, v := range e.Values {
+		buf.WriteString(fmt.Sprintf("\tcase %q:\n\t\treturn %s, nil\n", v.Name, v.Const))
+	}
+	buf.WriteString(fmt.Sprintf("\t}\n\n\treturn %s, fmt.Errorf(\"invalid %s name: %%q\", name)\n}\n", zero, e.Name))
+	add("Parse"+e.Name, buf.String())
+
+	add(e.Name+"Values", fmt.Sprintf(`// %sValues returns the declared values of the %s in order.
+func %sValues() []%s {
+	return []%s{%s}
+}
+`, e.Name, e.Name, e.Name, e.Name, e.Name, strings.Join(consts, ", ")))
+
+	add(e.Name+".IsValid", fmt.Sprintf(`// IsValid reports whether the %s is one of the declared values.
+func (%s %s) IsValid() bool {
+	switch %s {
+	case %s:
+		return true
+	}
+
+	return false
+}
+`, e.Name, r, e.Name, r, strings.Join(consts, ", ")))
+
+	add(e.Name+".MarshalText", fmt.Sprintf(`// MarshalText implements encoding.TextMarshaler, it returns an error for the undeclared values.
+func (%s %s) MarshalText() ([]byte, error) {
+	if !%s.IsValid() {
+		return nil, fmt.Errorf("invalid %s value: %s", %s)
+	}
+
+	return []byte(%s.String()), nil
+}
+`, r, e.Name, r, e.Name, valueFmt, e.valueArg(r), r))
+
+	add(e.Name+".UnmarshalText", fmt.Sprintf(`// UnmarshalText implements encoding.TextUnmarshaler, it accepts the names returned by String.
+func (%s *%s) UnmarshalText(text []byte) error {
+	value, err := Parse%s(string(text))
+	if err != nil {
+		return err
+	}
+
+	*%s = value
+	return nil
+}
+`, r, e.Name, e.Name, r))
+
+	add(e.Name+".Value", fmt.Sprintf(`// Value implements driver.Valuer, the %s is stored by its name.
+func (%s %s) Value() (driver.Value, error) {
+	if !%s.IsValid() {
+		return nil, fmt.Errorf("invalid %s value: %s", %s)
+	}
+
+	return %s.String(), nil
+}
+`, e.Name, r, e.Name, r, e.Name, valueFmt, e.valueArg(r), r))
+
+	buf.Reset()
+	if e.Kind == enumString {
+		buf.WriteString("// Scan implements sql.Scanner, it accepts the names returned by String.\n")
+	} else {
+		buf.WriteString("// Scan implements sql.Scanner, it accepts the names returned by String and the integer values.\n")
+	}
+	buf.WriteString(fmt.Sprintf("func (%s *%s) Scan(src any) error {\n\tswitch v := src.(type) {\n", r, e.Name))
+	buf.WriteString(fmt.Sprintf("\tcase string:\n\t\treturn %s.UnmarshalText([]byte(v))\n", r))
+	buf.WriteString(fmt.Sprintf("\tcase []byte:\n\t\treturn %s.UnmarshalText(v)\n", r))
+	if e.Kind != enumString {
+		buf.WriteString(fmt.Sprintf("\tcase int64:\n\t\tif value := %s(v); int64(value) == v && value.IsValid() {\n\t\t\t*%s = value\n\t\t\treturn nil\n\t\t}\n\n", e.Name, r))
+		buf.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s value: %%d\", v)\n", e.Name))
+	}
+	buf.WriteString(fmt.Sprintf("\t}\n\n\treturn fmt.Errorf(\"unsupported scan type %%T of %s\", src)\n}\n", e.Name))
+	add(e.Name+".Scan", buf.String())
+
+	return result
+}
+
+// valueArg returns the argument formatting the underlying value of the receiver, the String method
+// isn't used since it may be overridden.
+func (e *enum) valueArg(r string) string {
+	switch e.Kind {
+	case enumSigned:
+		return "int64(" + r + ")"
+	case enumUnsigned:
+		return "uint64(" + r + ")"
+	}
+
+	return "string(" + r + ")"
+}
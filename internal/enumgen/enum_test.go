@@ -0,0 +1,244 @@
+package enumgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseEnum(t *testing.T) {
+	const src = `package status
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+	StatusInactive // disabled
+	StatusPending  // "in progress"
+	StatusDefault = StatusActive
+	_
+	unrelated = 10
+)
+
+func (s Status) String() string { return "" }
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "status.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse file, err: %+v", err)
+	}
+
+	*_trimPrefix, *_transform = "Status", _transformSnake
+	defer func() { *_trimPrefix, *_transform, *_lineComment = "", "", false }()
+
+	testCases := []struct {
+		desc        string
+		lineComment bool
+		expected    []enumValue
+	}{
+		{
+			desc:     "constant names",
+			expected: []enumValue{{"StatusUnknown", "unknown"}, {"StatusActive", "active"}, {"StatusInactive", "inactive"}, {"StatusPending", "pending"}},
+		},
+		{
+			desc:        "line comments",
+			lineComment: true,
+			expected:    []enumValue{{"StatusUnknown", "unknown"}, {"StatusActive", "active"}, {"StatusInactive", "disabled"}, {"StatusPending", "in progress"}},
+		},
+	}
+
+	var e *enum
+	for _, tc := range testCases {
+		*_lineComment = tc.lineComment
+
+		e, err = parseEnum(fset, []*ast.File{f}, "Status")
+		if err != nil {
+			t.Fatalf("%s: parse enum, err: %+v", tc.desc, err)
+		}
+
+		if len(e.Values) != len(tc.expected) {
+			t.Fatalf("%s: values mismatch: %+v", tc.desc, e.Values)
+		}
+
+		for i := range tc.expected {
+			if e.Values[i] != tc.expected[i] {
+				t.Fatalf("%s: value %d mismatch: %+v", tc.desc, i, e.Values[i])
+			}
+		}
+	}
+
+	if e.Kind != enumSigned || e.ShouldGenerate("Status.String") || !e.ShouldGenerate("ParseStatus") {
+		t.Fatalf("enum mismatch: %+v", e)
+	}
+}
+
+func TestGenerateEnum(t *testing.T) {
+	runModule(t, map[string]string{
+		"status/status.go": `package status
+
+//go:generate enumgen -destination=status_enum.go -trimprefix=Status -transform=snake -linecomment
+type Status int
+
+const (
+	StatusUnknown Status = iota - 1
+	StatusActive
+	StatusInProgress // "in progress"
+)
+
+//go:generate enumgen -destination=level_enum.go -trimprefix=Level -transform=upper
+type Level uint8
+
+const (
+	LevelLow Level = iota + 1
+	LevelHigh
+)
+
+//go:generate enumgen -destination=color_enum.go -trimprefix=Color -transform=kebab
+type Color string
+
+const (
+	ColorDarkRed Color = "dark_red"
+	ColorBlue    Color = "blue"
+)
+`,
+	}, map[string]string{
+		"status/status_test.go": `package status
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type enum interface {
+	fmt.Stringer
+	encoding.TextMarshaler
+	driver.Valuer
+	IsValid() bool
+}
+
+type scanner interface {
+	encoding.TextUnmarshaler
+	sql.Scanner
+}
+
+// roundTrip checks the value is restored by its name through Parse, UnmarshalText and Scan.
+func roundTrip[T enum, P interface {
+	*T
+	scanner
+}](t *testing.T, values []T, names []string, parse func(string) (T, error)) {
+	t.Helper()
+
+	if len(values) != len(names) {
+		t.Fatalf("values mismatch: %v", values)
+	}
+
+	for i, v := range values {
+		if v.String() != names[i] || !v.IsValid() {
+			t.Fatalf("name of %v mismatch: %s", names[i], v.String())
+		}
+
+		if parsed, err := parse(names[i]); err != nil || !reflect.DeepEqual(parsed, v) {
+			t.Fatalf("parse %s mismatch: %v, err: %+v", names[i], parsed, err)
+		}
+
+		text, err := v.MarshalText()
+		if err != nil || string(text) != names[i] {
+			t.Fatalf("marshal %s mismatch: %s, err: %+v", names[i], text, err)
+		}
+
+		var unmarshaled T
+		if err := P(&unmarshaled).UnmarshalText(text); err != nil || !reflect.DeepEqual(unmarshaled, v) {
+			t.Fatalf("unmarshal %s mismatch: %v, err: %+v", names[i], unmarshaled, err)
+		}
+
+		stored, err := v.Value()
+		if err != nil || stored != names[i] {
+			t.Fatalf("value of %s mismatch: %v, err: %+v", names[i], stored, err)
+		}
+
+		for _, src := range []any{stored, []byte(names[i])} {
+			var scanned T
+			if err := P(&scanned).Scan(src); err != nil || !reflect.DeepEqual(scanned, v) {
+				t.Fatalf("scan %v mismatch: %v, err: %+v", src, scanned, err)
+			}
+		}
+	}
+
+	if _, err := parse("undeclared"); err == nil {
+		t.Fatal("parse should fail for the undeclared name")
+	}
+
+	var scanned T
+	for _, src := range []any{"undeclared", []byte("undeclared"), 1.5, nil} {
+		if err := P(&scanned).Scan(src); err == nil {
+			t.Fatalf("scan should fail for %v", src)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	roundTrip(t, StatusValues(), []string{"unknown", "active", "in progress"}, ParseStatus)
+
+	var s Status
+	if err := s.Scan(int64(1)); err != nil || s != StatusInProgress {
+		t.Fatalf("scan integer mismatch: %v, err: %+v", s, err)
+	}
+
+	if err := s.Scan(int64(5)); err == nil {
+		t.Fatal("scan should fail for the undeclared integer")
+	}
+
+	if invalid := Status(5); invalid.String() != "Status(5)" || invalid.IsValid() {
+		t.Fatalf("invalid status mismatch: %s", invalid)
+	}
+
+	if _, err := Status(5).Value(); err == nil {
+		t.Fatal("value should fail for the undeclared status")
+	}
+
+	if _, err := Status(5).MarshalText(); err == nil {
+		t.Fatal("marshal should fail for the undeclared status")
+	}
+}
+
+func TestLevel(t *testing.T) {
+	roundTrip(t, LevelValues(), []string{"LOW", "HIGH"}, ParseLevel)
+
+	var l Level
+	if err := l.Scan(int64(2)); err != nil || l != LevelHigh {
+		t.Fatalf("scan integer mismatch: %v, err: %+v", l, err)
+	}
+
+	for _, v := range []int64{0, 258, -1} {
+		if err := l.Scan(v); err == nil {
+			t.Fatalf("scan should fail for %d", v)
+		}
+	}
+
+	if invalid := Level(9); invalid.String() != "Level(9)" {
+		t.Fatalf("invalid level mismatch: %s", invalid)
+	}
+}
+
+func TestColor(t *testing.T) {
+	roundTrip(t, ColorValues(), []string{"dark-red", "blue"}, ParseColor)
+
+	var c Color
+	if err := c.Scan(int64(1)); err == nil {
+		t.Fatal("scan should fail for the integer of the string enum")
+	}
+
+	if invalid := Color("x"); invalid.String() != "Color(x)" || invalid.IsValid() {
+		t.Fatalf("invalid color mismatch: %s", invalid)
+	}
+}
+`,
+	})
+}
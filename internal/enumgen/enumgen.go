@@ -0,0 +1,177 @@
+package enumgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/merge"
+	"github.com/yanun0323/gox/internal/report"
+)
+
+const (
+	_commandName    = "enumgen"
+	_subcommandName = "enum"
+)
+
+var _flags = command.NewFlagSet(_commandName)
+
+var (
+	_help        = _flags.Bool("h", false, "show command help")
+	_debug       = _flags.Bool("v", false, "show debug information")
+	_replace     = _flags.Bool("replace", false, "replace the existing methods and functions in the destination")
+	_destination = _flags.String("destination", "", "target file name to generate enum methods, it must be in the package of the enum type")
+	_type        = _flags.String("type", "", "comma separated target enum type names in the package, instead of the type below the go:generate directive")
+	_trimPrefix  = _flags.String("trimprefix", "", "prefix trimmed from the constant names, e.g. Status")
+	_transform   = _flags.String("transform", "", "case of the names derived from the constant names (snake, kebab, lower, upper)")
+	_lineComment = _flags.Bool("linecomment", false, "use the trailing line comment of the constant as its name when present")
+	_json        = _flags.Bool("json", false, "print the report of each enum type as a JSON line")
+)
+
+// Usage is a replacement usage function for the flags package.
+func Usage() {
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "%s: generate the methods of the enum type from its constants\n", _commandName)
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "\t-h\t\t\t\tshow usage\n")
+	fmt.Fprintf(_flags.Output(), "\t-destination\t(require)\tgenerated file path in the package of the enum type\t-destination=status_enum.go\n")
+	fmt.Fprintf(_flags.Output(), "\t-type\t\t\t\ttarget enum type names in the package\t\t\t-type=Status,Role\n")
+	fmt.Fprintf(_flags.Output(), "\t-trimprefix\t\t\tprefix trimmed from the constant names\t\t\t-trimprefix=Status\n")
+	fmt.Fprintf(_flags.Output(), "\t-transform\t\t\tcase of the names (snake, kebab, lower, upper)\t\t-transform=snake\n")
+	fmt.Fprintf(_flags.Output(), "\t-linecomment\t\t\tuse the trailing line comment of the constant as its name\n")
+	fmt.Fprintf(_flags.Output(), "\t-replace\t\t\tforce replace exist func/method\n")
+	fmt.Fprintf(_flags.Output(), "\t-json\t\t\t\tprint the report of each enum type as a JSON line\n")
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "\texample:\n")
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "\t//go:generate %s -destination=status_enum.go -trimprefix=Status -transform=snake\n", _commandName)
+	fmt.Fprintf(_flags.Output(), "\n")
+}
+
+// Command is the enum command of gox, it's also installed as enumgen.
+var Command = command.Command{
+	Name:    _subcommandName,
+	Alias:   _commandName,
+	Summary: "generate the methods of the enum type from its constants",
+	Run:     run,
+}
+
+func run(args []string) error {
+	if err := helper.setupLog(args); err != nil {
+		return err
+	}
+
+	helper.debugPrint()
+
+	if *_help {
+		_flags.Usage()
+		return nil
+	}
+
+	rep := report.New(_subcommandName, *_json)
+
+	return rep.Finish(generate(rep))
+}
+
+func generate(rep *report.Reporter) error {
+	if err := helper.requireDestination(); err != nil {
+		return err
+	}
+
+	switch *_transform {
+	case "", _transformSnake, _transformKebab, _transformLower, _transformUpper:
+	default:
+		return fmt.Errorf("unsupported transform: %s", *_transform)
+	}
+
+	dir, file, err := helper.GetDir()
+	if err != nil {
+		return fmt.Errorf("get directory, err: %w", err)
+	}
+
+	destination := helper.AbsPath(*_destination)
+	if filepath.Ext(destination) != ".go" {
+		destination += ".go"
+	}
+
+	if filepath.Dir(destination) != dir {
+		return errors.New("destination must be in the package of the enum type")
+	}
+
+	typeNames := helper.SplitList(*_type)
+	if len(typeNames) == 0 {
+		goLine, err := strconv.Atoi(os.Getenv("GOLINE"))
+		if err != nil {
+			return fmt.Errorf("parse GOLINE, err: %w", err)
+		}
+
+		typeName, err := findTargetType(file, goLine)
+		if err != nil {
+			return err
+		}
+
+		typeNames = append(typeNames, typeName)
+	}
+
+	// the enums are generated one by one, so the methods in the same destination are merged
+	for _, typeName := range typeNames {
+		_actions = nil
+		err := generateEnumAndSave(dir, typeName, destination)
+		rep.Report(report.Report{Type: typeName, Source: report.TypePosition(dir, typeName), Destination: destination, Actions: _actions}, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateEnumAndSave generates the methods of the enum type into the destination.
+func generateEnumAndSave(dir, typeName, destination string) error {
+	e, err := loadEnum(dir, typeName, destination)
+	if err != nil {
+		return err
+	}
+
+	generated := generateEnum(e)
+	if len(generated) == 0 {
+		return fmt.Errorf("nothing to generate, the methods of %s are already declared", typeName)
+	}
+
+	des := merge.Destination{File: destination, Package: e.Package, Replace: *_replace, Record: helper.record}
+	return des.Save(e.ImportPaths(), generated)
+}
+
+// findTargetType returns the name of the type declared right after the go:generate directive.
+func findTargetType(file string, goLine int) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse file %s, err: %w", file, err)
+	}
+
+	for _, decl := range f.Decls {
+		if fset.Position(decl.End()).Line <= goLine {
+			continue
+		}
+
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			break
+		}
+
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); fset.Position(ts.Pos()).Line > goLine {
+				return ts.Name.Name, nil
+			}
+		}
+	}
+
+	return "", errors.New("target type not found")
+}
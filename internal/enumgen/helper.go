@@ -0,0 +1,44 @@
+package enumgen
+
+import (
+	"errors"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+var helper = helperInstance{}
+
+type helperInstance struct {
+	util.Helper
+}
+
+func (helperInstance) setupLog(args []string) error {
+	return command.Parse(_flags, Usage, args)
+}
+
+func (helperInstance) requireDestination() error {
+	if len(*_destination) == 0 {
+		_flags.Usage()
+		return errors.New("destination not define")
+	}
+
+	return nil
+}
+
+// _actions is the changes of the destination made by the current enum, they're reported by -json.
+var _actions []gen.Action
+
+func (helperInstance) record(kind gen.ActionKind, name string) {
+	_actions = append(_actions, gen.Action{Kind: kind, Name: name})
+}
+
+func (helperInstance) debugPrint() {
+	if *_debug {
+		println()
+		println("\t", "replace", "=", *_replace)
+		println("\t", "destination", "=", *_destination)
+		println()
+	}
+}
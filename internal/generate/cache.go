@@ -0,0 +1,216 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/plugingen"
+)
+
+// cache records the inputs of the succeeded jobs, the jobs whose inputs are unchanged since the
+// last run are skipped.
+//
+// The entry of a job is keyed by the hash of the gox executable, the folder, the arguments and the
+// environment of the job, and the value is the hash of the go files in the folder and the folders
+// of the module-local packages it imports, the destination file, the files of the flags, e.g. -source and the templates of -template, and the plugin
+// executable of -plugin.
+type cache struct {
+	dir  string
+	tool string
+}
+
+// newCache returns the cache of the folder, the entries are invalidated by the other gox
+// executables.
+func newCache(dir, exe string) (*cache, error) {
+	tool, err := hashFiles(exe)
+	if err != nil {
+		return nil, fmt.Errorf("hash executable of gox, err: %w", err)
+	}
+
+	// the folder is resolved at once, since the generators run in the process change the working
+	// directory
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %s, err: %w", dir, err)
+	}
+
+	return &cache{dir: abs, tool: tool}, nil
+}
+
+func (c *cache) key(j job) string {
+	h := sha256.New()
+	for _, s := range [][]string{{c.tool, j.Dir}, j.Args, j.Env} {
+		fmt.Fprintf(h, "%d\x00%s\x00", len(s), strings.Join(s, "\x00"))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Unchanged reports whether the inputs of the job are the same as the last succeeded run.
+func (c *cache) Unchanged(j job) bool {
+	recorded, err := os.ReadFile(filepath.Join(c.dir, c.key(j)))
+	if err != nil {
+		return false
+	}
+
+	inputs, err := jobInputs(j)
+	if err != nil {
+		return false
+	}
+
+	return string(recorded) == inputs
+}
+
+// Record saves the inputs of the succeeded job, it's called after all the jobs are done since the
+// generated files may be in the folders of the other jobs.
+func (c *cache) Record(j job) error {
+	inputs, err := jobInputs(j)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		return fmt.Errorf("mkdir %s, err: %w", c.dir, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(c.dir, c.key(j)), []byte(inputs), 0644); err != nil {
+		return fmt.Errorf("write cache of %s, err: %w", j.Name, err)
+	}
+
+	return nil
+}
+
+// jobInputs returns the hash of the go files in the folder of the job and the folders of its
+// dependencies, the destination, the files of the flags and the plugin executable, the test files
+// are skipped.
+func jobInputs(j job) (string, error) {
+	var files []string
+	for _, dir := range append([]string{j.Dir}, j.Deps...) {
+		goFiles, err := sourceFiles(dir)
+		if err != nil {
+			return "", err
+		}
+
+		files = append(files, goFiles...)
+	}
+
+	if len(j.Destination) != 0 && filepath.Dir(j.Destination) != j.Dir {
+		files = append(files, j.Destination)
+	}
+
+	inputs, err := j.inputFiles()
+	if err != nil {
+		return "", err
+	}
+
+	files = append(files, inputs...)
+
+	if plugin := flagValue(j.Args, "plugin"); len(plugin) != 0 && j.Args[0] == plugingen.Command.Name {
+		// the upgraded plugin regenerates the files
+		if exe, err := exec.LookPath(gen.PluginPrefix + plugin); err == nil {
+			files = append(files, exe)
+		}
+	}
+
+	sort.Strings(files)
+	return hashFiles(slices.Compact(files)...)
+}
+
+// sourceFiles returns the go files in the folder except the tests.
+func sourceFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir %s, err: %w", dir, err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+
+	return files, nil
+}
+
+// inputFiles returns the files referenced by the flags of the job, e.g. -source and -snapshot of
+// model, and the templates of -template. The flag values are taken as the paths relative to the
+// folder of the job, the ones which aren't existing files are skipped.
+func (j job) inputFiles() ([]string, error) {
+	var files []string
+	for _, arg := range j.Args[1:] {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			name, value, hasValue = "", arg, true
+		}
+
+		if !hasValue || len(value) == 0 {
+			continue
+		}
+
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(j.Dir, value)
+		}
+
+		info, err := os.Stat(value)
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			files = append(files, filepath.Clean(value))
+			continue
+		}
+
+		if name != "template" {
+			continue
+		}
+
+		templates, err := filepath.Glob(filepath.Join(value, "*.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("find templates of %s, err: %w", value, err)
+		}
+
+		files = append(files, templates...)
+	}
+
+	return files, nil
+}
+
+// hashFiles returns the hash of the names and the contents of the files, the missing files are
+// hashed by their names.
+func hashFiles(files ...string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00", file)
+
+		f, err := os.Open(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return "", fmt.Errorf("open %s, err: %w", file, err)
+		}
+
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("read %s, err: %w", file, err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
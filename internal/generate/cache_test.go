@@ -0,0 +1,154 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "gox")
+	source := filepath.Join(dir, "domain", "member.go")
+	writeTestFile(t, exe, "v1")
+	writeTestFile(t, source, "package domain\n")
+
+	c, err := newCache(filepath.Join(dir, ".gox", "cache"), exe)
+	if err != nil {
+		t.Fatalf("new cache, err: %+v", err)
+	}
+
+	j := job{Dir: filepath.Dir(source), Args: []string{"domain"}, Destination: filepath.Join(dir, "usecase", "member.go")}
+	if c.Unchanged(j) {
+		t.Fatal("job shouldn't be cached before recording")
+	}
+
+	writeTestFile(t, j.Destination, "package usecase\n")
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	if !c.Unchanged(j) {
+		t.Fatal("job should be cached after recording")
+	}
+
+	if c.Unchanged(job{Dir: j.Dir, Args: []string{"domain", "-replace"}, Destination: j.Destination}) {
+		t.Fatal("job of the other flags shouldn't be cached")
+	}
+
+	writeTestFile(t, j.Destination, "package usecase\n\ntype x struct{}\n")
+	if c.Unchanged(j) {
+		t.Fatal("job of the modified destination shouldn't be cached")
+	}
+
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	writeTestFile(t, source, "package domain\n\ntype Member struct{}\n")
+	if c.Unchanged(j) {
+		t.Fatal("job of the modified source shouldn't be cached")
+	}
+
+	writeTestFile(t, exe, "v2")
+	c, err = newCache(c.dir, exe)
+	if err != nil {
+		t.Fatalf("new cache, err: %+v", err)
+	}
+
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	writeTestFile(t, exe, "v3")
+	if c, _ = newCache(c.dir, exe); c.Unchanged(j) {
+		t.Fatal("job of the other gox shouldn't be cached")
+	}
+}
+
+func TestCacheFlagFiles(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "gox")
+	schema := filepath.Join(dir, "schema", "schema.sql")
+	writeTestFile(t, exe, "v1")
+	writeTestFile(t, filepath.Join(dir, "entity", "entity.go"), "package entity\n")
+	writeTestFile(t, schema, "CREATE TABLE members (id BIGINT);\n")
+
+	c, err := newCache(filepath.Join(dir, ".gox", "cache"), exe)
+	if err != nil {
+		t.Fatalf("new cache, err: %+v", err)
+	}
+
+	j := job{
+		Dir:         filepath.Join(dir, "entity"),
+		Args:        []string{"model", "-source=../schema/schema.sql", "-snapshot", "../schema/snapshot.json", "-destination=member.go"},
+		Destination: filepath.Join(dir, "entity", "member.go"),
+	}
+
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	writeTestFile(t, schema, "CREATE TABLE members (id BIGINT, name TEXT);\n")
+	if c.Unchanged(j) {
+		t.Fatal("job of the modified -source shouldn't be cached")
+	}
+
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "schema", "snapshot.json"), "{}\n")
+	if c.Unchanged(j) {
+		t.Fatal("job of the created -snapshot shouldn't be cached")
+	}
+}
+
+func TestCacheDeps(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "gox")
+	embedded := filepath.Join(dir, "b", "b.go")
+	writeTestFile(t, exe, "v1")
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeTestFile(t, filepath.Join(dir, "a", "a.go"), "package a\n\nimport \"example.com/app/b\"\n\ntype A interface{ b.X }\n")
+	writeTestFile(t, embedded, "package b\n\nimport \"example.com/app/c\"\n\ntype X interface{ c.Y }\n")
+	writeTestFile(t, filepath.Join(dir, "c", "c.go"), "package c\n\nimport \"fmt\"\n\ntype Y interface{ fmt.Stringer }\n")
+
+	c, err := newCache(filepath.Join(dir, ".gox", "cache"), exe)
+	if err != nil {
+		t.Fatalf("new cache, err: %+v", err)
+	}
+
+	jobs := []job{{Dir: filepath.Join(dir, "a"), Args: []string{"domain", "-type=A"}, Destination: filepath.Join(dir, "a", "a_impl.go")}}
+	if err := loadDeps(context.Background(), jobs); err != nil {
+		t.Fatalf("load deps, err: %+v", err)
+	}
+
+	j := jobs[0]
+	if want := []string{filepath.Join(dir, "b"), filepath.Join(dir, "c")}; !slices.Equal(j.Deps, want) {
+		t.Fatalf("deps mismatch, want: %+v, got: %+v", want, j.Deps)
+	}
+
+	if err := c.Record(j); err != nil {
+		t.Fatalf("record, err: %+v", err)
+	}
+
+	writeTestFile(t, embedded, "package b\n\nimport \"example.com/app/c\"\n\ntype X interface {\n\tc.Y\n\tThree()\n}\n")
+	if c.Unchanged(j) {
+		t.Fatal("job of the modified imported package shouldn't be cached")
+	}
+}
+
+func writeTestFile(t *testing.T, file, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		t.Fatalf("mkdir, err: %+v", err)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write file, err: %+v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+var helper = util.Helper{}
+
+// Config is the gox.yaml of the project, it declares the generation targets without the
+// go:generate directives.
+//
+//	templates: ./tmpl
+//	targets:
+//	  - type: ./example.ExampleUsecase
+//	    destination: ./example_output/usecase/example.go
+//	    package: usecase
+//	    name: exampleUsecase
+//	  - type: ./example.Example
+//	    command: model
+//	    destination: ./example_output/entity/example.go
+//	    package: entity
+//	    args: [-deepcopy, -replace]
+type Config struct {
+	// Templates is the folder of the *.tmpl files overriding the code of the domain targets, it's relative to the config file.
+	Templates string `yaml:"templates"`
+	// Targets is the generation targets.
+	Targets []Target `yaml:"targets"`
+}
+
+// Target is a generation target of the config.
+type Target struct {
+	// Type is the folder of the package relative to the config file and the type name, e.g. ./example.ExampleUsecase.
+	Type string `yaml:"type"`
+	// Command is the generator of the target (domain, model, enum), it's decided by the kind of the type by default.
+	Command string `yaml:"command"`
+	// Destination is the generated file path relative to the config file.
+	Destination string `yaml:"destination"`
+	// Package is the package name of the generated file.
+	Package string `yaml:"package"`
+	// Name is the generated type name.
+	Name string `yaml:"name"`
+	// Args is the other flags of the generator, e.g. -replace.
+	Args []string `yaml:"args"`
+	// Template is the template file or folder of the domain target relative to the config file, it's Templates of the config by default.
+	Template string `yaml:"template"`
+
+	dir      string
+	typeName string
+	template string
+}
+
+// loadConfig reads the config file, the folders and the destinations of the targets are resolved
+// to the absolute paths.
+func loadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s, err: %w", file, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	cfg := &Config{}
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config %s, err: %w", file, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %s, err: %w", file, err)
+	}
+
+	if len(cfg.Templates) != 0 && !filepath.IsAbs(cfg.Templates) {
+		cfg.Templates = filepath.Join(dir, cfg.Templates)
+	}
+
+	for i := range cfg.Targets {
+		t := &cfg.Targets[i]
+		if len(t.Type) == 0 {
+			return nil, fmt.Errorf("type of the target %d not define", i+1)
+		}
+
+		if len(t.Destination) == 0 {
+			return nil, fmt.Errorf("destination of %s not define", t.Type)
+		}
+
+		if !filepath.IsAbs(t.Destination) {
+			t.Destination = filepath.Join(dir, t.Destination)
+		}
+
+		t.dir, t.typeName, err = parseTypeRef(t.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filepath.IsAbs(t.dir) {
+			t.dir = filepath.Join(dir, t.dir)
+		}
+
+		t.template = cfg.Templates
+		if len(t.Template) != 0 {
+			t.template = t.Template
+			if !filepath.IsAbs(t.template) {
+				t.template = filepath.Join(dir, t.template)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseTypeRef splits the type reference into the folder of the package and the type name, e.g.
+// './example.ExampleUsecase' to './example' and 'ExampleUsecase'.
+func parseTypeRef(ref string) (dir, name string, err error) {
+	i := strings.LastIndex(ref, ".")
+	if i < 0 || i < strings.LastIndex(ref, "/") || i == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid type reference %s, it should be like ./example.ExampleUsecase", ref)
+	}
+
+	dir, name = ref[:i], ref[i+1:]
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	return dir, name, nil
+}
+
+// declaration is the type declaration referenced by a target.
+type declaration struct {
+	// File is the file declaring the type.
+	File string
+	// Package is the package name of the file.
+	Package string
+	// Kind is the kind of the type (interface, struct, type).
+	Kind string
+}
+
+// findDeclaration finds the file declaring the type in the package folder.
+func findDeclaration(dir, name string) (declaration, error) {
+	file, f, spec, err := helper.FindTypeSpec(dir, name)
+	if err != nil {
+		return declaration{}, err
+	}
+
+	kind := "type"
+	switch spec.Type.(type) {
+	case *ast.InterfaceType:
+		kind = "interface"
+	case *ast.StructType:
+		kind = "struct"
+	}
+
+	return declaration{File: file, Package: f.Name.Name, Kind: kind}, nil
+}
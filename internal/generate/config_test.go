@@ -0,0 +1,88 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTypeRef(t *testing.T) {
+	testCases := []struct {
+		ref, dir, name string
+		err            bool
+	}{
+		{ref: "./example.ExampleUsecase", dir: "./example", name: "ExampleUsecase"},
+		{ref: "../domain/v1.2.Member", dir: "../domain/v1.2", name: "Member"},
+		{ref: ".Member", dir: ".", name: "Member"},
+		{ref: "./example", err: true},
+		{ref: "./example.", err: true},
+		{ref: "Member", err: true},
+	}
+
+	for _, tc := range testCases {
+		dir, name, err := parseTypeRef(tc.ref)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("%s: expected error", tc.ref)
+			}
+			continue
+		}
+
+		if err != nil || dir != tc.dir || name != tc.name {
+			t.Fatalf("%s: mismatch %s %s, err: %+v", tc.ref, dir, name, err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "domain"), 0777); err != nil {
+		t.Fatalf("mkdir, err: %+v", err)
+	}
+
+	const src = "package domain\n\ntype Member struct{ ID int64 }\n\ntype MemberUsecase interface{ Get() Member }\n"
+	if err := os.WriteFile(filepath.Join(dir, "domain", "member.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write source, err: %+v", err)
+	}
+
+	const cfg = "templates: ./tmpl\ntargets:\n  - type: ./domain.MemberUsecase\n    destination: ./usecase/member.go\n    package: usecase\n    args: [-replace]\n"
+	if err := os.WriteFile(filepath.Join(dir, "gox.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatalf("write config, err: %+v", err)
+	}
+
+	c, err := loadConfig(filepath.Join(dir, "gox.yaml"))
+	if err != nil {
+		t.Fatalf("load config, err: %+v", err)
+	}
+
+	if len(c.Targets) != 1 {
+		t.Fatalf("targets mismatch: %+v", c.Targets)
+	}
+
+	target := c.Targets[0]
+	if target.dir != filepath.Join(dir, "domain") || target.typeName != "MemberUsecase" || target.Destination != filepath.Join(dir, "usecase", "member.go") || target.template != filepath.Join(dir, "tmpl") {
+		t.Fatalf("target mismatch: %+v", target)
+	}
+
+	decl, err := findDeclaration(target.dir, target.typeName)
+	if err != nil {
+		t.Fatalf("find declaration, err: %+v", err)
+	}
+
+	cmd, err := targetCommand(target, decl)
+	if err != nil || cmd.Name != "domain" {
+		t.Fatalf("command mismatch: %+v, err: %+v", cmd, err)
+	}
+
+	if args := targetArgs(cmd, target); args[len(args)-2] != "-template="+filepath.Join(dir, "tmpl") {
+		t.Fatalf("args mismatch: %+v", args)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "gox.yaml"), []byte("targets:\n  - type: ./domain.Member\n    dest: ./x.go\n"), 0644); err != nil {
+		t.Fatalf("write config, err: %+v", err)
+	}
+
+	if _, err := loadConfig(filepath.Join(dir, "gox.yaml")); err == nil {
+		t.Fatal("expected error of the unknown field")
+	}
+}
@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// _depsLoadMode is the information of the packages finding the module-local packages imported by
+// the packages of the jobs, the imports are listed without parsing the go files.
+const _depsLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule
+
+// loadDeps sets the folders of the module-local packages imported by the package of each job,
+// directly or indirectly, since the generated code depends on them, e.g. the interfaces embedded
+// from the other packages.
+func loadDeps(ctx context.Context, jobs []job) error {
+	dirs := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		dirs = append(dirs, j.Dir)
+	}
+
+	deps, err := packageDeps(ctx, dirs)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		jobs[i].Deps = deps[jobs[i].Dir]
+	}
+
+	return nil
+}
+
+// packageDeps returns the folders of the module-local packages imported by the packages of the
+// folders, keyed by the folders. The packages are loaded at once for each module.
+func packageDeps(ctx context.Context, dirs []string) (map[string][]string, error) {
+	deps := map[string][]string{}
+	for root, dirs := range moduleFolders(dirs) {
+		pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: _depsLoadMode, Dir: root}, dirs...)
+		if err != nil {
+			return nil, fmt.Errorf("load imports of %s, err: %w", root, err)
+		}
+
+		for _, pkg := range pkgs {
+			if len(pkg.Dir) != 0 {
+				deps[filepath.Clean(pkg.Dir)] = moduleImports(pkg)
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// moduleImports returns the sorted folders of the packages imported by the package, directly or
+// indirectly, which are in the main modules.
+func moduleImports(pkg *packages.Package) []string {
+	var (
+		result  []string
+		visited = map[string]bool{}
+		visit   func(p *packages.Package)
+	)
+
+	visit = func(p *packages.Package) {
+		for _, imp := range p.Imports {
+			if visited[imp.PkgPath] {
+				continue
+			}
+
+			visited[imp.PkgPath] = true
+			if imp.Module == nil || !imp.Module.Main || len(imp.Dir) == 0 {
+				continue
+			}
+
+			result = append(result, filepath.Clean(imp.Dir))
+			visit(imp)
+		}
+	}
+
+	visit(pkg)
+	sort.Strings(result)
+	return result
+}
+
+// moduleFolders groups the folders by the roots of their modules, since the packages are loaded in
+// the module.
+func moduleFolders(dirs []string) map[string][]string {
+	modules := map[string][]string{}
+	for _, dir := range dirs {
+		root := moduleRoot(dir)
+		if !slices.Contains(modules[root], dir) {
+			modules[root] = append(modules[root], dir)
+		}
+	}
+
+	return modules
+}
+
+// moduleRoot returns the nearest folder containing go.mod of the folder, it's empty outside the
+// modules, then the packages are loaded in the working directory.
+func moduleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
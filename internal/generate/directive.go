@@ -0,0 +1,261 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/report"
+	"golang.org/x/tools/go/packages"
+)
+
+// _directivePrefix is the prefix of the go:generate directive lines.
+const _directivePrefix = "//go:generate "
+
+// _packagesLoadMode is the information of the packages scanning the directives, the syntax isn't
+// needed since the directives are read line by line like go generate.
+const _packagesLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedModule
+
+// loadDirectiveJobs loads the packages of the patterns at once, and returns the jobs of the
+// go:generate directives running the gox generators in their go files.
+func loadDirectiveJobs(ctx context.Context, patterns []string) ([]job, error) {
+	pkgs, err := loadPackages(ctx, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []job
+	for _, pkg := range pkgs {
+		for _, file := range pkg.GoFiles {
+			jobs, err := scanDirectives(file, pkg.Name, packageGoMod(pkg))
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, jobs...)
+		}
+	}
+
+	return result, nil
+}
+
+// loadPackages loads the names and the go files of the packages of the patterns.
+func loadPackages(ctx context.Context, patterns []string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: _packagesLoadMode}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages %s, err: %w", strings.Join(patterns, " "), err)
+	}
+
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			return nil, fmt.Errorf("load package %s, err: %w", pkg.PkgPath, e)
+		}
+	}
+
+	return pkgs, nil
+}
+
+// packageGoMod returns the go.mod path of the package, it's empty outside the modules.
+func packageGoMod(pkg *packages.Package) string {
+	if pkg.Module == nil {
+		return ""
+	}
+
+	return pkg.Module.GoMod
+}
+
+// scanDirectives returns the jobs of the directives in the file running the gox generators, the
+// other directives are left to go generate.
+func scanDirectives(file, pkgName, goMod string) ([]job, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s, err: %w", file, err)
+	}
+
+	if !bytes.Contains(data, []byte(_directivePrefix)) {
+		return nil, nil
+	}
+
+	var (
+		result  []job
+		dir     = filepath.Dir(file)
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+	)
+
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.HasPrefix(text, _directivePrefix) {
+			continue
+		}
+
+		env := []string{
+			"GOFILE=" + filepath.Base(file),
+			"GOLINE=" + strconv.Itoa(line),
+			"GOPACKAGE=" + pkgName,
+			"DOLLAR=$",
+		}
+
+		words, err := splitDirective(text[len(_directivePrefix):], env)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+
+		args, ok := directiveArgs(words)
+		if !ok {
+			continue
+		}
+
+		if len(goMod) != 0 {
+			env = append(env, "GOX_GOMOD="+goMod)
+		}
+
+		result = append(result, job{
+			Name:        fmt.Sprintf("%s:%d", relativePath(file), line),
+			Dir:         dir,
+			Args:        args,
+			Env:         env,
+			Destination: flagDestination(dir, args),
+			Source:      report.NewPosition(file, line),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan file %s, err: %w", file, err)
+	}
+
+	return result, nil
+}
+
+// splitDirective splits the directive into the words like go generate, the words are separated by
+// the spaces unless they're double quoted, and the $NAME of the words are expanded by the directive
+// environment and then the process environment.
+func splitDirective(line string, env []string) ([]string, error) {
+	lookup := func(name string) string {
+		for _, kv := range env {
+			if k, v, _ := strings.Cut(kv, "="); k == name {
+				return v
+			}
+		}
+
+		return os.Getenv(name)
+	}
+
+	var words []string
+	for line = strings.TrimSpace(line); len(line) != 0; line = strings.TrimLeft(line, " \t") {
+		if line[0] == '"' {
+			end := 1
+			for ; end < len(line) && line[end] != '"'; end++ {
+				if line[end] == '\\' {
+					end++
+				}
+			}
+
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated quoted string in the directive")
+			}
+
+			word, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s, err: %w", line[:end+1], err)
+			}
+
+			words = append(words, os.Expand(word, lookup))
+			line = line[end+1:]
+			continue
+		}
+
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+
+		words = append(words, os.Expand(line[:end], lookup))
+		line = line[end:]
+	}
+
+	return words, nil
+}
+
+// directiveArgs returns the arguments of gox running the generator of the directive words, e.g.
+// 'domaingen -replace' and 'gox domain -replace' are both 'domain -replace'.
+func directiveArgs(words []string) ([]string, bool) {
+	if len(words) == 0 {
+		return nil, false
+	}
+
+	name, args := filepath.Base(words[0]), words[1:]
+	if name == "gox" {
+		if len(args) == 0 {
+			return nil, false
+		}
+
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := command.Find(_generators, name)
+	if !ok {
+		return nil, false
+	}
+
+	return append([]string{cmd.Name}, args...), true
+}
+
+// flagDestination returns the absolute path of the -destination flag in the arguments, the jobs
+// without the flag are run on their own.
+func flagDestination(dir string, args []string) string {
+	return flagPath(dir, args, "destination")
+}
+
+// flagPath returns the absolute path of the flag in the arguments, it's empty without the flag.
+func flagPath(dir string, args []string, flagName string) string {
+	value := flagValue(args, flagName)
+	if len(value) == 0 {
+		return ""
+	}
+
+	if !filepath.IsAbs(value) {
+		value = filepath.Join(dir, value)
+	}
+
+	return filepath.Clean(value)
+}
+
+// flagValue returns the value of the flag in the arguments, it's empty without the flag.
+func flagValue(args []string, flagName string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != flagName {
+			continue
+		}
+
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+
+		return value
+	}
+
+	return ""
+}
+
+// relativePath returns the path relative to the working directory if it's inside, for the logs.
+func relativePath(file string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+
+	rel, err := filepath.Rel(cwd, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+
+	return rel
+}
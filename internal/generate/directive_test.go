@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitDirective(t *testing.T) {
+	env := []string{"GOFILE=member.go", "DOLLAR=$"}
+	testCases := []struct {
+		line  string
+		words []string
+		err   bool
+	}{
+		{line: "domaingen -replace", words: []string{"domaingen", "-replace"}},
+		{line: "  gox  model\t-destination ../entity/member.go ", words: []string{"gox", "model", "-destination", "../entity/member.go"}},
+		{line: `gox enum -destination "my dir/$GOFILE"`, words: []string{"gox", "enum", "-destination", "my dir/member.go"}},
+		{line: `echo "a\"b" ${DOLLAR}x`, words: []string{"echo", `a"b`, "$x"}},
+		{line: `echo "abc`, err: true},
+	}
+
+	for _, tc := range testCases {
+		words, err := splitDirective(tc.line, env)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("%s: expected error", tc.line)
+			}
+			continue
+		}
+
+		if err != nil || !reflect.DeepEqual(words, tc.words) {
+			t.Fatalf("%s: mismatch %q, err: %+v", tc.line, words, err)
+		}
+	}
+}
+
+func TestScanDirectives(t *testing.T) {
+	dir := t.TempDir()
+	const src = `package domain
+
+//go:generate domaingen -destination=../usecase/member.go -package=usecase
+type MemberUsecase interface{}
+
+//go:generate gox model -destination ../entity/member.go
+type Member struct{}
+
+//go:generate gox generate ./...
+//go:generate stringer -type=Status
+type Status int
+`
+
+	file := filepath.Join(dir, "member.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("write source, err: %+v", err)
+	}
+
+	jobs, err := scanDirectives(file, "domain", "/app/go.mod")
+	if err != nil {
+		t.Fatalf("scan directives, err: %+v", err)
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("jobs mismatch: %+v", jobs)
+	}
+
+	if !reflect.DeepEqual(jobs[0].Args, []string{"domain", "-destination=../usecase/member.go", "-package=usecase"}) ||
+		jobs[0].Destination != filepath.Join(filepath.Dir(dir), "usecase", "member.go") {
+		t.Fatalf("domain job mismatch: %+v", jobs[0])
+	}
+
+	if !reflect.DeepEqual(jobs[1].Env, []string{"GOFILE=member.go", "GOLINE=6", "GOPACKAGE=domain", "DOLLAR=$", "GOX_GOMOD=/app/go.mod"}) ||
+		jobs[1].Destination != filepath.Join(filepath.Dir(dir), "entity", "member.go") {
+		t.Fatalf("model job mismatch: %+v", jobs[1])
+	}
+}
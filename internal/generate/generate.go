@@ -0,0 +1,272 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/domaingen"
+	"github.com/yanun0323/gox/internal/enumgen"
+	"github.com/yanun0323/gox/internal/modelgen"
+	"github.com/yanun0323/gox/internal/plugingen"
+	"github.com/yanun0323/gox/internal/report"
+)
+
+const _commandName = "generate"
+
+var _flags = command.NewFlagSet(_commandName)
+
+var (
+	_help    = _flags.Bool("h", false, "show command help")
+	_debug   = _flags.Bool("v", false, "print the targets and the generator commands")
+	_config  = _flags.String("config", "gox.yaml", "config file declaring the generation targets, it's optional when the packages are given")
+	_workers = _flags.Int("p", runtime.NumCPU(), "number of the generators running in parallel")
+	_cache   = _flags.String("cache", ".gox/cache", "folder of the cache recording the inputs of the generated targets")
+	_force   = _flags.Bool("force", false, "regenerate the targets even if their inputs are unchanged")
+	_json    = _flags.Bool("json", false, "print the report of each target as a JSON line, the logs are printed to the stderr")
+)
+
+// _generators is the commands which can be used by the targets of the config.
+var _generators = []command.Command{domaingen.Command, modelgen.Command, enumgen.Command, plugingen.Command}
+
+// Usage is a replacement usage function for the flags package.
+func Usage() {
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "%s: generate the targets declared in the config file and the go:generate directives of the packages\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\tusage: gox %s [flags] [packages]\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+	_flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\texample:\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\tgox %s -config=gox.yaml\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\tgox %s -p=8 ./...\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\tgox %s -json ./... > report.jsonl\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// Command is the generate command of gox, it runs the generators of the targets in gox.yaml and
+// the go:generate directives of the packages.
+var Command = command.Command{
+	Name:    _commandName,
+	Summary: "generate the targets of gox.yaml and the gox directives of the packages in parallel",
+	Run:     run,
+}
+
+func run(args []string) error {
+	if err := command.Parse(_flags, Usage, args); err != nil {
+		return err
+	}
+
+	if *_help {
+		_flags.Usage()
+		return nil
+	}
+
+	rep := report.New(_commandName, *_json)
+
+	return rep.Finish(generate(rep))
+}
+
+func generate(rep *report.Reporter) error {
+	ctx := context.Background()
+	jobs, failed, err := loadJobs(ctx, *_config, _flags.Args(), rep)
+	if err != nil {
+		return err
+	}
+
+	total := len(jobs) + failed
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("get executable of gox, err: %w", err)
+	}
+
+	c, err := newCache(*_cache, exe)
+	if err != nil {
+		return err
+	}
+
+	skip := c.Unchanged
+	if *_force {
+		skip = nil
+	}
+
+	// the stdout is left to the reports by -json
+	out := os.Stdout
+	if *_json {
+		out = os.Stderr
+	}
+
+	results := runJobs(ctx, exe, jobs, *_workers, skip, *_json)
+	for _, r := range results {
+		if *_debug {
+			if r.Cached {
+				fmt.Fprintf(out, "%s (cached)\n", r.Job)
+			} else {
+				fmt.Fprintln(out, r.Job)
+			}
+		}
+
+		out.Write(r.Output)
+
+		for _, rp := range r.Reports {
+			rep.Write(rp)
+		}
+
+		if r.Cached {
+			rp := r.Job.Report()
+			rp.Cached = true
+			rep.Write(rp)
+		}
+
+		if r.Err != nil {
+			log.Printf("generate %s, err: %+v", r.Job.Name, r.Err)
+			failed++
+
+			// the generator failing before reporting, e.g. by the invalid flags, is reported by the job
+			if !slices.ContainsFunc(r.Reports, func(rp report.Report) bool { return rp.Error != nil }) {
+				rep.Report(r.Job.Report(), r.Err)
+			}
+		}
+	}
+
+	for _, r := range results {
+		if r.Err == nil && !r.Cached {
+			if err := c.Record(r.Job); err != nil {
+				log.Printf("record cache, err: %+v", err)
+			}
+		}
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d targets failed", failed, total)
+	}
+
+	return nil
+}
+
+// loadJobs returns the jobs of the config targets and the directives of the package patterns, the
+// config is required without the patterns, and the jobs carry the folders of their module-local imports.
+// The targets failing to resolve are reported and counted.
+func loadJobs(ctx context.Context, config string, patterns []string, rep *report.Reporter) (jobs []job, failed int, err error) {
+	if _, err := os.Stat(config); len(patterns) == 0 || err == nil {
+		jobs, failed, err = loadTargetJobs(config, rep)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if len(patterns) != 0 {
+		directives, err := loadDirectiveJobs(ctx, patterns)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		jobs = append(jobs, directives...)
+	}
+
+	if err := loadDeps(ctx, jobs); err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, failed, nil
+}
+
+// loadTargetJobs returns the jobs of the targets in the config, the targets failing to resolve are
+// logged, reported and counted.
+func loadTargetJobs(config string, rep *report.Reporter) (jobs []job, failed int, err error) {
+	cfg, err := loadConfig(config)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, t := range cfg.Targets {
+		j, err := targetJob(t)
+		if err != nil {
+			log.Printf("generate %s, err: %+v", t.Type, err)
+			rep.Report(report.Report{Command: t.Command, Type: t.typeName, Source: report.NewPosition(config, 0), Destination: t.Destination}, err)
+			failed++
+			continue
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, failed, nil
+}
+
+// targetJob returns the job running the generator of the target in the package folder of the
+// type, like go generate runs the directive with the environment of the file.
+func targetJob(t Target) (job, error) {
+	decl, err := findDeclaration(t.dir, t.typeName)
+	if err != nil {
+		return job{}, err
+	}
+
+	cmd, err := targetCommand(t, decl)
+	if err != nil {
+		return job{}, err
+	}
+
+	if len(t.Template) != 0 && cmd.Name != domaingen.Command.Name {
+		return job{}, fmt.Errorf("template isn't supported by gox %s", cmd.Name)
+	}
+
+	return job{
+		Name:        t.Type,
+		Dir:         t.dir,
+		Args:        targetArgs(cmd, t),
+		Env:         []string{"GOFILE=" + filepath.Base(decl.File), "GOPACKAGE=" + decl.Package},
+		Destination: t.Destination,
+		Type:        t.typeName,
+		Source:      report.TypePosition(t.dir, t.typeName),
+	}, nil
+}
+
+// targetCommand returns the generator of the target, it's decided by the kind of the type when the
+// command isn't declared.
+func targetCommand(t Target, decl declaration) (command.Command, error) {
+	name := t.Command
+	if len(name) == 0 {
+		switch decl.Kind {
+		case "interface":
+			name = domaingen.Command.Name
+		case "struct":
+			name = modelgen.Command.Name
+		default:
+			name = enumgen.Command.Name
+		}
+	}
+
+	cmd, ok := command.Find(_generators, name)
+	if !ok {
+		return command.Command{}, errors.New("unknown command " + name)
+	}
+
+	return cmd, nil
+}
+
+func targetArgs(cmd command.Command, t Target) []string {
+	args := []string{cmd.Name, "-type=" + t.typeName, "-destination=" + t.Destination}
+	if len(t.Package) != 0 {
+		args = append(args, "-package="+t.Package)
+	}
+
+	if len(t.Name) != 0 {
+		args = append(args, "-name="+t.Name)
+	}
+
+	if len(t.template) != 0 && cmd.Name == domaingen.Command.Name {
+		args = append(args, "-template="+t.template)
+	}
+
+	return append(args, t.Args...)
+}
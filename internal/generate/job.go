@@ -0,0 +1,317 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/domaingen"
+	"github.com/yanun0323/gox/internal/enumgen"
+	"github.com/yanun0323/gox/internal/modelgen"
+	"github.com/yanun0323/gox/internal/report"
+	"golang.org/x/tools/go/packages"
+)
+
+// _processMu guards the working directory and the environment of the process. The generators run by
+// command.RunIn hold it exclusively since they change them, and the other jobs hold it shared while
+// they depend on them, e.g. goimports and the plugin processes inheriting the environment.
+var _processMu sync.RWMutex
+
+// job is a generator run of a directive or a config target.
+type job struct {
+	// Name is the position of the directive or the type of the config target, shown in the logs.
+	Name string
+	// Dir is the folder running the generator, which is the package folder of the source.
+	Dir string
+	// Args is the arguments of gox, e.g. 'model -destination=../entity/member.go'.
+	Args []string
+	// Env is the go:generate environment of the generator, e.g. GOFILE.
+	Env []string
+	// Deps is the folders of the module-local packages imported by the package of Dir, directly or
+	// indirectly, their go files are the inputs of the job like the ones of Dir.
+	Deps []string
+	// Destination is the absolute path of the generated file, the jobs of the same destination run in order.
+	Destination string
+	// Type is the type name of the config target, it's empty for the directives.
+	Type string
+	// Source is the position of the directive or the type of the config target, shown in the reports.
+	Source *report.Position
+}
+
+func (j job) String() string {
+	return fmt.Sprintf("%s: gox %s", j.Name, strings.Join(j.Args, " "))
+}
+
+// env returns the value of the go:generate environment variable of the job, it's empty without it.
+func (j job) env(name string) string {
+	for _, kv := range j.Env {
+		if k, v, _ := strings.Cut(kv, "="); k == name {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// args returns the arguments of gox running the job, -json is inserted when the reports are
+// requested, so the cache of the job is shared with the runs without it.
+func (j job) args(reports bool) []string {
+	if !reports {
+		return j.Args
+	}
+
+	return append([]string{j.Args[0], "-json"}, j.Args[1:]...)
+}
+
+// Report returns the report of the job without the result.
+func (j job) Report() report.Report {
+	return report.Report{Command: j.Args[0], Type: j.Type, Source: j.Source, Destination: j.Destination}
+}
+
+// jobResult is the result of a job.
+type jobResult struct {
+	Job    job
+	Cached bool
+	// Output is the output of the generator, except the reports.
+	Output []byte
+	// Reports is the reports printed by the generator when the reports are requested.
+	Reports []report.Report
+	Err     error
+}
+
+// runJobs runs the jobs by the workers, the jobs of the same destination are run by the same worker
+// in order, since the generators merge the generated code into the existing destination. The
+// results are returned in the order of the jobs. The generators are run with -json when the reports
+// are requested.
+//
+// The built-in generators are run in the process, and the packages of the domain jobs are loaded at
+// once and shared by them. The plugins are run as the gox processes of exe.
+func runJobs(ctx context.Context, exe string, jobs []job, workers int, skip func(job) bool, reports bool) []jobResult {
+	var (
+		results = make([]jobResult, len(jobs))
+		groups  = map[string][]int{}
+		order   []string
+		loader  = newPackageLoader(jobs)
+	)
+
+	for i, j := range jobs {
+		key := j.Destination
+		if len(key) == 0 {
+			key = fmt.Sprintf("#%d", i)
+		}
+
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+
+		groups[key] = append(groups[key], i)
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan []int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for indexes := range queue {
+				for _, i := range indexes {
+					results[i] = runJob(ctx, exe, jobs[i], skip, reports, loader)
+				}
+			}
+		}()
+	}
+
+	for _, key := range order {
+		queue <- groups[key]
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+func runJob(ctx context.Context, exe string, j job, skip func(job) bool, reports bool, loader *packageLoader) jobResult {
+	if skip != nil && skip(j) {
+		return jobResult{Job: j, Cached: true}
+	}
+
+	switch j.Args[0] {
+	case domaingen.Command.Name:
+		return implementJob(ctx, j, loader)
+	case modelgen.Command.Name, enumgen.Command.Name:
+		return runJobInProcess(ctx, j, reports)
+	default:
+		return execJob(ctx, exe, j, reports)
+	}
+}
+
+// implementJob implements the interfaces of the domain job in the process, with the package loaded
+// by the loader.
+func implementJob(ctx context.Context, j job, loader *packageLoader) jobResult {
+	result := jobResult{Job: j}
+	t, err := domaingen.ParseTarget(j.Dir, filepath.Join(j.Dir, j.env("GOFILE")), j.env("GOLINE"), j.Args[1:])
+	if err != nil {
+		result.Err = fmt.Errorf("parse gox %s, err: %w", j.Args[0], err)
+		return result
+	}
+
+	// goimports formatting the implementations runs go in the working directory
+	_processMu.RLock()
+	defer _processMu.RUnlock()
+
+	pkg, err := loader.Load(ctx, j.Dir)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if result.Reports, err = t.Generate(ctx, pkg); err != nil {
+		result.Err = fmt.Errorf("run gox %s, err: %w", j.Args[0], err)
+	}
+
+	return result
+}
+
+// runJobInProcess runs the generator of the job in the process, the runs are serialized since the
+// generator runs in the folder of the job with its environment and its flags.
+func runJobInProcess(ctx context.Context, j job, reports bool) jobResult {
+	result := jobResult{Job: j}
+	if result.Err = ctx.Err(); result.Err != nil {
+		return result
+	}
+
+	cmd, _ := command.Find(_generators, j.Args[0])
+
+	_processMu.Lock()
+	defer _processMu.Unlock()
+
+	output, stdout := bytes.Buffer{}, bytes.Buffer{}
+	if err := cmd.RunIn(j.Dir, j.Env, j.args(reports)[1:], &stdout); err != nil {
+		result.Err = fmt.Errorf("run gox %s, err: %w", j.Args[0], err)
+	}
+
+	result.Reports = parseReports(&stdout, &output)
+	result.Output = output.Bytes()
+
+	return result
+}
+
+// execJob runs the generator of the job as the gox process of exe, it's used by the plugins whose
+// executables are run by the generator anyway.
+func execJob(ctx context.Context, exe string, j job, reports bool) jobResult {
+	output, stdout := bytes.Buffer{}, bytes.Buffer{}
+	c := exec.CommandContext(ctx, exe, j.args(reports)...)
+	c.Dir = j.Dir
+	c.Stdout, c.Stderr = &output, &output
+	if reports {
+		c.Stdout = &stdout
+	}
+
+	// the process inherits the environment unchanged by the generators run in the process
+	_processMu.RLock()
+	c.Env = append(os.Environ(), j.Env...)
+	err := c.Start()
+	_processMu.RUnlock()
+
+	if err == nil {
+		err = c.Wait()
+	}
+
+	if err != nil {
+		err = fmt.Errorf("run gox %s, err: %w", j.Args[0], err)
+	}
+
+	result := jobResult{Job: j, Err: err}
+	result.Reports = parseReports(&stdout, &output)
+	result.Output = output.Bytes()
+
+	return result
+}
+
+// packageLoader loads the packages of the domain jobs by gen.LoadMode at once on the first use, so
+// the type checking is shared by the jobs of the same packages and their imports.
+type packageLoader struct {
+	dirs []string
+	once sync.Once
+	pkgs map[string]*packages.Package
+	err  error
+}
+
+func newPackageLoader(jobs []job) *packageLoader {
+	l := &packageLoader{}
+	for _, j := range jobs {
+		if j.Args[0] == domaingen.Command.Name && !slices.Contains(l.dirs, j.Dir) {
+			l.dirs = append(l.dirs, j.Dir)
+		}
+	}
+
+	return l
+}
+
+// Load returns the package in the folder, the packages of all the folders are loaded by the first
+// call, so they're not loaded when the jobs are cached.
+func (l *packageLoader) Load(ctx context.Context, dir string) (*packages.Package, error) {
+	l.once.Do(func() {
+		l.pkgs = map[string]*packages.Package{}
+		for root, dirs := range moduleFolders(l.dirs) {
+			pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: gen.LoadMode, Dir: root}, dirs...)
+			if err != nil {
+				l.err = fmt.Errorf("load packages %s, err: %w", strings.Join(dirs, " "), err)
+				return
+			}
+
+			for _, pkg := range pkgs {
+				if len(pkg.Dir) != 0 && pkg.Types != nil {
+					l.pkgs[filepath.Clean(pkg.Dir)] = pkg
+				}
+			}
+		}
+	})
+
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	pkg, ok := l.pkgs[dir]
+	if !ok {
+		return nil, fmt.Errorf("load package %s, err: package not found", dir)
+	}
+
+	return pkg, nil
+}
+
+// parseReports returns the reports of the JSON lines in the stdout of the generator, the other
+// lines are written to w.
+func parseReports(stdout, w *bytes.Buffer) []report.Report {
+	var result []report.Report
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		rp := report.Report{}
+		if err := json.Unmarshal(line, &rp); err == nil && len(rp.Command) != 0 {
+			result = append(result, rp)
+			continue
+		}
+
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunJobsInProcess(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeTestFile(t, filepath.Join(dir, "base", "base.go"), "package base\n\n//go:generate gox domain -destination=../usecase/closer.go -package=usecase\ntype Closer interface{ Close() error }\n")
+	writeTestFile(t, filepath.Join(dir, "domain", "member.go"), `package domain
+
+import "example.com/app/base"
+
+//go:generate gox domain -destination=../usecase/member.go -package=usecase
+type MemberUsecase interface {
+	base.Closer
+	Get(id int64) (string, error)
+}
+
+//go:generate gox enum -destination=status_enum.go
+type Status int
+
+const (
+	StatusActive Status = iota + 1
+	StatusBanned
+)
+
+//go:generate gox model -destination=member_model.go -package=domain -getters
+type Member struct {
+	ID *int64
+}
+
+//go:generate gox model -unknown
+type Invalid struct{}
+`)
+
+	t.Chdir(dir)
+
+	jobs, err := loadDirectiveJobs(context.Background(), []string{"./..."})
+	if err != nil || len(jobs) != 5 {
+		t.Fatalf("load jobs %+v, err: %+v", jobs, err)
+	}
+
+	results := runJobs(context.Background(), "", jobs, 2, nil, true)
+	for _, r := range results[:4] {
+		if r.Err != nil || len(r.Reports) != 1 || r.Reports[0].Command != r.Job.Args[0] || r.Reports[0].Error != nil {
+			t.Fatalf("%s: result mismatch, reports: %+v, output: %s, err: %+v", r.Job, r.Reports, r.Output, r.Err)
+		}
+	}
+
+	if r := results[4]; r.Err == nil || !strings.Contains(r.Err.Error(), "-unknown") {
+		t.Fatalf("flag error isn't returned: %+v", r.Err)
+	}
+
+	for _, file := range []string{"usecase/closer.go", "usecase/member.go", "domain/status_enum.go", "domain/member_model.go"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Fatalf("%s isn't generated, err: %+v", file, err)
+		}
+	}
+
+	if cwd, _ := os.Getwd(); cwd != dir {
+		t.Fatalf("working directory isn't restored: %s", cwd)
+	}
+
+	if _, ok := os.LookupEnv("GOLINE"); ok {
+		t.Fatal("environment isn't restored")
+	}
+}
@@ -0,0 +1,422 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/report"
+)
+
+const _watchName = "watch"
+
+var _watchFlags = command.NewFlagSet(_watchName)
+
+var (
+	_watchHelp     = _watchFlags.Bool("h", false, "show command help")
+	_watchDebug    = _watchFlags.Bool("v", false, "print the skipped targets and the generator commands")
+	_watchConfig   = _watchFlags.String("config", "gox.yaml", "config file declaring the generation targets, it's optional when the packages are given")
+	_watchWorkers  = _watchFlags.Int("p", runtime.NumCPU(), "number of the generators running in parallel")
+	_watchCache    = _watchFlags.String("cache", ".gox/cache", "folder of the cache recording the inputs of the generated targets")
+	_watchDebounce = _watchFlags.Duration("debounce", 300*time.Millisecond, "wait for the changes to settle before regenerating")
+)
+
+// WatchUsage is a replacement usage function for the flags package.
+func WatchUsage() {
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "%s: regenerate the targets and the directives whose packages are changed\n", _watchName)
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\tusage: gox %s [flags] [packages]\n", _watchName)
+	fmt.Fprintf(os.Stderr, "\n")
+	_watchFlags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\texample:\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\tgox %s ./...\n", _watchName)
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// WatchCommand is the watch command of gox, it regenerates the targets of gox.yaml and the gox
+// directives of the packages when their go files are changed.
+var WatchCommand = command.Command{
+	Name:    _watchName,
+	Summary: "regenerate the targets of gox.yaml and the gox directives when the packages are changed",
+	Run:     watch,
+}
+
+func watch(args []string) error {
+	if err := command.Parse(_watchFlags, WatchUsage, args); err != nil {
+		return err
+	}
+
+	if *_watchHelp {
+		_watchFlags.Usage()
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("get executable of gox, err: %w", err)
+	}
+
+	c, err := newCache(*_watchCache, exe)
+	if err != nil {
+		return err
+	}
+
+	w := &watcher{exe: exe, cache: c, packages: map[string]packageInfo{}, directives: map[string][]job{}}
+	if err := w.load(ctx, *_watchConfig, _watchFlags.Args()); err != nil {
+		return err
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create file watcher, err: %w", err)
+	}
+	defer fsw.Close()
+
+	for _, dir := range w.folders() {
+		if err := fsw.Add(dir); err != nil {
+			return fmt.Errorf("watch %s, err: %w", dir, err)
+		}
+	}
+
+	w.generate(ctx, w.jobs())
+	log.Printf("watching %d targets in %d folders", len(w.jobs()), len(w.folders()))
+
+	changed := map[string]bool{}
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+
+			log.Printf("watch, err: %+v", err)
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			if ev.Has(fsnotify.Chmod) || !w.concerns(ev.Name) {
+				continue
+			}
+
+			changed[filepath.Clean(ev.Name)] = true
+			timer.Reset(*_watchDebounce)
+		case <-timer.C:
+			files := make([]string, 0, len(changed))
+			for file := range changed {
+				files = append(files, file)
+			}
+
+			clear(changed)
+			sort.Strings(files)
+			jobs := w.update(ctx, files)
+
+			// the packages newly imported by the jobs are watched
+			for _, dir := range w.folders() {
+				if err := fsw.Add(dir); err != nil {
+					log.Printf("watch %s, err: %+v", relativePath(dir), err)
+				}
+			}
+
+			w.generate(ctx, jobs)
+		}
+	}
+}
+
+// packageInfo is the package of a watched folder, rescanning the directives of the changed files.
+type packageInfo struct {
+	Name  string
+	GoMod string
+}
+
+// watcher keeps the jobs of the watched folders, the directive jobs are rescanned by the changed
+// files since their lines are moved by the editing.
+type watcher struct {
+	exe   string
+	cache *cache
+
+	config     string
+	targets    []job
+	packages   map[string]packageInfo
+	directives map[string][]job
+	// inputs is the files referenced by the flags of the jobs, e.g. -source of model.
+	inputs map[string]bool
+	// deps is the folders of the module-local packages imported by the folders of the jobs.
+	deps map[string][]string
+}
+
+// load loads the jobs of the config and the packages of the patterns, the config is required
+// without the patterns, and the imports of their packages.
+func (w *watcher) load(ctx context.Context, config string, patterns []string) error {
+	if _, err := os.Stat(config); len(patterns) == 0 || err == nil {
+		abs, err := filepath.Abs(config)
+		if err != nil {
+			return fmt.Errorf("get absolute path of %s, err: %w", config, err)
+		}
+
+		w.config = abs
+		if w.targets, _, err = loadTargetJobs(abs, report.New(_watchName, false)); err != nil {
+			return err
+		}
+	}
+
+	if len(patterns) != 0 {
+		pkgs, err := loadPackages(ctx, patterns)
+		if err != nil {
+			return err
+		}
+
+		for _, pkg := range pkgs {
+			for _, file := range pkg.GoFiles {
+				jobs, err := scanDirectives(file, pkg.Name, packageGoMod(pkg))
+				if err != nil {
+					return err
+				}
+
+				w.packages[filepath.Dir(file)] = packageInfo{Name: pkg.Name, GoMod: packageGoMod(pkg)}
+				if len(jobs) != 0 {
+					w.directives[file] = jobs
+				}
+			}
+		}
+	}
+
+	w.loadInputs()
+	return w.loadDeps(ctx)
+}
+
+// jobs returns the jobs of the config targets and the directives.
+func (w *watcher) jobs() []job {
+	result := append([]job{}, w.targets...)
+
+	files := make([]string, 0, len(w.directives))
+	for file := range w.directives {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+	for _, file := range files {
+		result = append(result, w.directives[file]...)
+	}
+
+	for i := range result {
+		result[i].Deps = w.deps[result[i].Dir]
+	}
+
+	return result
+}
+
+// loadDeps loads the folders of the module-local packages imported by the folders of the jobs.
+func (w *watcher) loadDeps(ctx context.Context) error {
+	dirs := make([]string, 0, len(w.targets)+len(w.directives))
+	for _, j := range w.jobs() {
+		dirs = append(dirs, j.Dir)
+	}
+
+	deps, err := packageDeps(ctx, dirs)
+	if err != nil {
+		return err
+	}
+
+	w.deps = deps
+	return nil
+}
+
+// loadInputs collects the files referenced by the flags of the jobs.
+func (w *watcher) loadInputs() {
+	w.inputs = map[string]bool{}
+	for _, j := range w.jobs() {
+		files, err := j.inputFiles()
+		if err != nil {
+			log.Printf("find inputs of %s, err: %+v", j.Name, err)
+			continue
+		}
+
+		for _, file := range files {
+			w.inputs[file] = true
+		}
+	}
+}
+
+// folders returns the folders to watch, they're the package folders, the folders of the config
+// targets, the folders of the packages they import, the folders of the files referenced by the
+// flags and the folder of the config.
+func (w *watcher) folders() []string {
+	set := map[string]bool{}
+	for dir := range w.packages {
+		set[dir] = true
+	}
+
+	for _, deps := range w.deps {
+		for _, dir := range deps {
+			set[dir] = true
+		}
+	}
+
+	for file := range w.inputs {
+		set[filepath.Dir(file)] = true
+	}
+
+	for _, t := range w.targets {
+		set[t.Dir] = true
+	}
+
+	if len(w.config) != 0 {
+		set[filepath.Dir(w.config)] = true
+	}
+
+	result := make([]string, 0, len(set))
+	for dir := range set {
+		result = append(result, dir)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// concerns reports whether the changed file may affect the jobs, they're the config, the files
+// referenced by the flags and the go files except the tests.
+func (w *watcher) concerns(file string) bool {
+	if file = filepath.Clean(file); file == w.config || w.inputs[file] {
+		return true
+	}
+
+	return strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go")
+}
+
+// update rescans the changed files and returns the affected jobs, they're the jobs in the folders
+// of the changed files or importing their packages, the jobs generating the changed files and the
+// jobs referencing the changed files by the flags.
+func (w *watcher) update(ctx context.Context, files []string) []job {
+	dirs := map[string]bool{}
+	changed := map[string]bool{}
+	reloadConfig := false
+
+	for _, file := range files {
+		if file == w.config {
+			reloadConfig = true
+			continue
+		}
+
+		changed[file] = true
+		dirs[filepath.Dir(file)] = true
+
+		info, ok := w.packages[filepath.Dir(file)]
+		if !ok {
+			continue
+		}
+
+		delete(w.directives, file)
+		jobs, err := scanDirectives(file, info.Name, info.GoMod)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("scan %s, err: %+v", relativePath(file), err)
+			}
+			continue
+		}
+
+		if len(jobs) != 0 {
+			w.directives[file] = jobs
+		}
+	}
+
+	if reloadConfig {
+		targets, _, err := loadTargetJobs(w.config, report.New(_watchName, false))
+		if err != nil {
+			log.Printf("reload config, err: %+v", err)
+		} else {
+			w.targets = targets
+		}
+	}
+
+	// the imports are changed by the edited go files
+	if err := w.loadDeps(ctx); err != nil {
+		log.Printf("reload imports, err: %+v", err)
+	}
+
+	var result []job
+	for i, j := range w.jobs() {
+		if (reloadConfig && i < len(w.targets)) || dirs[j.Dir] || slices.ContainsFunc(j.Deps, func(dir string) bool { return dirs[dir] }) ||
+			changed[j.Destination] || referencesAny(j, changed) {
+			result = append(result, j)
+		}
+	}
+
+	w.loadInputs()
+	return result
+}
+
+// referencesAny reports whether the job references any of the files by the flags.
+func referencesAny(j job, files map[string]bool) bool {
+	inputs, _ := j.inputFiles()
+	for _, file := range inputs {
+		if files[file] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generate runs the jobs whose inputs are changed, and prints the rewritten destinations.
+func (w *watcher) generate(ctx context.Context, jobs []job) {
+	if len(jobs) == 0 {
+		return
+	}
+
+	before := make([]string, len(jobs))
+	for i, j := range jobs {
+		before[i], _ = hashFiles(j.Destination)
+	}
+
+	for i, r := range runJobs(ctx, w.exe, jobs, *_watchWorkers, w.cache.Unchanged, false) {
+		if *_watchDebug {
+			if r.Cached {
+				fmt.Printf("%s (cached)\n", r.Job)
+			} else {
+				fmt.Println(r.Job)
+			}
+		}
+
+		os.Stdout.Write(r.Output)
+
+		if r.Err != nil {
+			log.Printf("generate %s, err: %+v", r.Job.Name, r.Err)
+			continue
+		}
+
+		if r.Cached {
+			continue
+		}
+
+		if err := w.cache.Record(r.Job); err != nil {
+			log.Printf("record cache, err: %+v", err)
+		}
+
+		if after, _ := hashFiles(r.Job.Destination); len(r.Job.Destination) != 0 && after != before[i] {
+			fmt.Printf("%s rewrote %s by %s\n", time.Now().Format(time.TimeOnly), relativePath(r.Job.Destination), r.Job.Name)
+		}
+	}
+}
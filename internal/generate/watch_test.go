@@ -0,0 +1,73 @@
+package generate
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWatcherUpdate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeTestFile(t, filepath.Join(dir, "domain", "member.go"), "package domain\n\n//go:generate gox domain -destination=../usecase/member.go\ntype MemberUsecase interface{}\n")
+	writeTestFile(t, filepath.Join(dir, "domain", "status.go"), "package domain\n\n//go:generate gox enum -destination=status_enum.go\ntype Status int\n")
+	writeTestFile(t, filepath.Join(dir, "entity", "entity.go"), "package entity\n")
+
+	t.Chdir(dir)
+
+	w := &watcher{packages: map[string]packageInfo{}, directives: map[string][]job{}}
+	if err := w.load(context.Background(), "gox.yaml", []string{"./..."}); err != nil {
+		t.Fatalf("load, err: %+v", err)
+	}
+
+	if len(w.jobs()) != 2 || len(w.folders()) != 2 || len(w.config) != 0 {
+		t.Fatalf("load mismatch, jobs: %+v, folders: %+v", w.jobs(), w.folders())
+	}
+
+	member := filepath.Join(dir, "domain", "member.go")
+	writeTestFile(t, member, "package domain\n\n// MemberUsecase is moved by the comment.\n//go:generate gox domain -destination=../usecase/member.go\ntype MemberUsecase interface{}\n")
+
+	jobs := w.update(context.Background(), []string{member})
+	if len(jobs) != 2 {
+		t.Fatalf("jobs of the changed package mismatch: %+v", jobs)
+	}
+
+	if jobs[0].Env[1] != "GOLINE=4" {
+		t.Fatalf("directive isn't rescanned: %+v", jobs[0])
+	}
+
+	if jobs := w.update(context.Background(), []string{filepath.Join(dir, "entity", "entity.go")}); len(jobs) != 0 {
+		t.Fatalf("jobs of the other package mismatch: %+v", jobs)
+	}
+
+	if jobs := w.update(context.Background(), []string{filepath.Join(dir, "usecase", "member.go")}); len(jobs) != 1 || jobs[0].Args[0] != "domain" {
+		t.Fatalf("jobs of the changed destination mismatch: %+v", jobs)
+	}
+
+	embedded := filepath.Join(dir, "base", "base.go")
+	writeTestFile(t, embedded, "package base\n\ntype Usecase interface{}\n")
+	writeTestFile(t, member, "package domain\n\nimport \"example.com/app/base\"\n\n//go:generate gox domain -destination=../usecase/member.go\ntype MemberUsecase interface{ base.Usecase }\n")
+	w.update(context.Background(), []string{member})
+
+	if !slices.Contains(w.folders(), filepath.Dir(embedded)) {
+		t.Fatalf("imported package isn't watched: %+v", w.folders())
+	}
+
+	if jobs := w.update(context.Background(), []string{embedded}); len(jobs) != 2 || jobs[0].Dir != filepath.Join(dir, "domain") {
+		t.Fatalf("jobs of the changed imported package mismatch: %+v", jobs)
+	}
+
+	schema := filepath.Join(dir, "schema", "schema.sql")
+	writeTestFile(t, schema, "CREATE TABLE members (id BIGINT);\n")
+	writeTestFile(t, filepath.Join(dir, "entity", "entity.go"), "package entity\n\n//go:generate gox model -source=../schema/schema.sql -destination=member.go\n")
+	w.update(context.Background(), []string{filepath.Join(dir, "entity", "entity.go")})
+
+	if !w.concerns(schema) {
+		t.Fatal("-source file isn't concerned")
+	}
+
+	if jobs := w.update(context.Background(), []string{schema}); len(jobs) != 1 || jobs[0].Args[0] != "model" {
+		t.Fatalf("jobs of the changed -source mismatch: %+v", jobs)
+	}
+}
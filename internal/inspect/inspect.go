@@ -0,0 +1,147 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+const (
+	_inspectName = "inspect"
+	_checkName   = "check"
+)
+
+var helper = util.Helper{}
+
+// Command is the inspect command of gox, it prints the go:generate environment and the ast of the
+// file of the directive.
+var Command = command.Command{
+	Name:    _inspectName,
+	Alias:   "sample",
+	Summary: "print the go:generate environment and the ast of the file",
+	Run:     inspect,
+}
+
+// CheckCommand is the check command of gox, it reports the declaration below the go:generate
+// directive and the command generating from it.
+var CheckCommand = command.Command{
+	Name:    _checkName,
+	Summary: "check the declaration below the go:generate directive and the command for it",
+	Run:     check,
+}
+
+func inspect(args []string) error {
+	flags := command.NewFlagSet(_inspectName)
+	err := command.Parse(flags, func() {
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "%s: print the go:generate environment and the ast of the file\n", _inspectName)
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "\t//go:generate gox inspect\n")
+		fmt.Fprintf(os.Stderr, "\n")
+	}, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Running %s go on %s\n", os.Args[0], os.Getenv("GOFILE"))
+
+	cwd, file, err := helper.GetDir()
+	if err != nil {
+		return fmt.Errorf("get directory, err: %w", err)
+	}
+
+	fmt.Printf("  cwd = %s\n", cwd)
+	fmt.Printf("  os.Args = %#v\n", os.Args)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse file %s, err: %w", file, err)
+	}
+
+	if err := ast.Print(fset, f); err != nil {
+		return fmt.Errorf("print ast, err: %w", err)
+	}
+
+	helper.EnvironmentPrint()
+	return nil
+}
+
+func check(args []string) error {
+	flags := command.NewFlagSet(_checkName)
+	err := command.Parse(flags, func() {
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "%s: check the declaration below the go:generate directive and the command for it\n", _checkName)
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "\t//go:generate gox check\n")
+		fmt.Fprintf(os.Stderr, "\n")
+	}, args)
+	if err != nil {
+		return err
+	}
+
+	_, file, err := helper.GetDir()
+	if err != nil {
+		return fmt.Errorf("get directory, err: %w", err)
+	}
+
+	goLine, err := strconv.Atoi(os.Getenv("GOLINE"))
+	if err != nil {
+		return fmt.Errorf("parse GOLINE, err: %w", err)
+	}
+
+	if _, err := helper.GetModuleName(); err != nil {
+		return fmt.Errorf("get module name, err: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse file %s, err: %w", file, err)
+	}
+
+	spec, err := findTypeSpec(fset, f, goLine)
+	if err != nil {
+		return err
+	}
+
+	kind, cmd := "type", "enum"
+	switch spec.Type.(type) {
+	case *ast.InterfaceType:
+		kind, cmd = "interface", "domain"
+	case *ast.StructType:
+		kind, cmd = "struct", "model"
+	}
+
+	fmt.Printf("%s: %s %s, generated by gox %s\n", fset.Position(spec.Pos()), kind, spec.Name.Name, cmd)
+	return nil
+}
+
+// findTypeSpec returns the type declared right after the go:generate directive.
+func findTypeSpec(fset *token.FileSet, f *ast.File, goLine int) (*ast.TypeSpec, error) {
+	for _, decl := range f.Decls {
+		if fset.Position(decl.End()).Line <= goLine {
+			continue
+		}
+
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			break
+		}
+
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); fset.Position(ts.Pos()).Line > goLine {
+				return ts, nil
+			}
+		}
+	}
+
+	return nil, errors.New("no type declared below the go:generate directive")
+}
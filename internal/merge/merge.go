@@ -0,0 +1,280 @@
+// Package merge saves the generated declarations into the destination file, the declarations are
+// merged into the existing file instead of overwriting it.
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/goast/kind"
+	"github.com/yanun0323/goast/scope"
+	"github.com/yanun0323/gox/gen"
+)
+
+// Scope is a generated declaration which is going to be saved into the destination.
+type Scope struct {
+	// Key identifies the declaration in the destination, it's the type name for types,
+	// the function name for functions, 'Receiver.Method' for methods and the first
+	// constant name for const blocks.
+	Key  string
+	Text string
+}
+
+// Destination is the file which the generated scopes are saved into.
+type Destination struct {
+	// File is the path of the destination, '.go' is appended when it's missing.
+	File string
+	// Package is the package name of the destination, it's used when the file is created.
+	Package string
+	// Replace replaces the existing declarations with the same keys instead of keeping them.
+	Replace bool
+	// Record records the changes of the destination, it's optional.
+	Record func(kind gen.ActionKind, name string)
+}
+
+// Path returns the path of the destination file.
+func (d Destination) Path() string {
+	if !strings.HasSuffix(d.File, ".go") {
+		return d.File + ".go"
+	}
+
+	return d.File
+}
+
+func (d Destination) record(kind gen.ActionKind, name string) {
+	if d.Record != nil {
+		d.Record(kind, name)
+	}
+}
+
+// Save saves the generated scopes into the destination.
+//
+// If the destination file already exists, the generated scopes are merged into it. The existing
+// declarations with the same keys are kept, unless Replace is set. The import paths are either
+// 'path' or 'alias path'.
+func (d Destination) Save(importPaths []string, generated []Scope) error {
+	destination := d.Path()
+	desAst, err := goast.ParseAst(destination)
+	if err != nil && !errors.Is(err, goast.ErrNotExist) {
+		return fmt.Errorf("parse destination ast, err: %w", err)
+	}
+
+	if desAst == nil {
+		text := strings.Builder{}
+		text.WriteString(fmt.Sprintf("package %s\n", d.Package))
+		text.WriteString(importString(importPaths))
+		for _, g := range generated {
+			text.WriteString("\n")
+			text.WriteString(g.Text)
+		}
+
+		scs, err := goast.ParseScope(0, []byte(text.String()))
+		if err != nil {
+			return fmt.Errorf("parse scope for creating file, err: %w", err)
+		}
+
+		newAst, err := goast.NewAst(scs...)
+		if err != nil {
+			return fmt.Errorf("new ast, err: %w", err)
+		}
+
+		if err := newAst.Save(destination, true); err != nil {
+			return fmt.Errorf("save new ast, err: %w", err)
+		}
+
+		d.record(gen.ActionCreateFile, absPath(destination))
+		for _, g := range generated {
+			d.record(gen.ActionAdd, g.Key)
+		}
+
+		return nil
+	}
+
+	d.record(gen.ActionUpdateFile, absPath(destination))
+
+	var (
+		scopes       []goast.Scope
+		indexTable   = map[string][]int{}
+		existImports = map[string]bool{}
+	)
+
+	desAst.IterScope(func(sc goast.Scope) bool {
+		if sc.Kind() == scope.Import {
+			for _, path := range importPaths {
+				if scopeContains(sc, `"`+importPathOf(path)+`"`) {
+					existImports[path] = true
+				}
+			}
+		}
+
+		if key, ok := scopeKey(sc); ok {
+			indexTable[key] = append(indexTable[key], len(scopes))
+		}
+
+		scopes = append(scopes, sc)
+		return true
+	})
+
+	var missingImports []string
+	for _, path := range importPaths {
+		if !existImports[path] {
+			missingImports = append(missingImports, path)
+		}
+	}
+
+	if len(missingImports) != 0 {
+		scs, err := goast.ParseScope(0, []byte(importString(missingImports)))
+		if err != nil {
+			return fmt.Errorf("parse scope for import, err: %w", err)
+		}
+
+		packageIndex := 0
+		for i, sc := range scopes {
+			if sc.Kind() == scope.Package {
+				packageIndex = i + 1
+				break
+			}
+		}
+
+		scopes = append(scopes[:packageIndex], append(scs, scopes[packageIndex:]...)...)
+		for key, indexes := range indexTable {
+			for i := range indexes {
+				if indexes[i] >= packageIndex {
+					indexes[i] += len(scs)
+				}
+			}
+			indexTable[key] = indexes
+		}
+	}
+
+	replaced := map[int][]goast.Scope{}
+	for _, g := range generated {
+		indexes, exist := indexTable[g.Key]
+		if exist && !d.Replace {
+			d.record(gen.ActionSkip, g.Key)
+			continue
+		}
+
+		scs, err := goast.ParseScope(0, []byte("\n"+g.Text))
+		if err != nil {
+			return fmt.Errorf("parse scope for %s, err: %w", g.Key, err)
+		}
+
+		if !exist {
+			d.record(gen.ActionAdd, g.Key)
+			scopes = append(scopes, scs...)
+			continue
+		}
+
+		d.record(gen.ActionReplace, g.Key)
+
+		for _, i := range indexes {
+			replaced[i] = nil
+
+			// drop the doc comments of the replaced declaration
+			for j := i - 1; j >= 0 && scopes[j].Kind() == scope.Comment && scopes[j].Line()+1 == scopes[j+1].Line(); j-- {
+				replaced[j] = nil
+			}
+		}
+		replaced[indexes[0]] = scs
+	}
+
+	result := make([]goast.Scope, 0, len(scopes))
+	for i, sc := range scopes {
+		scs, ok := replaced[i]
+		if !ok {
+			result = append(result, sc)
+			continue
+		}
+
+		result = append(result, scs...)
+	}
+
+	return desAst.SetScope(result).Save(destination, true)
+}
+
+// scopeKey returns the key of the declaration scope like Scope.Key.
+func scopeKey(sc goast.Scope) (string, bool) {
+	switch sc.Kind() {
+	case scope.Type:
+		return sc.GetTypeName()
+	case scope.Func:
+		if receiverType, ok := sc.GetMethodReceiver(); ok {
+			methodName, _ := sc.GetMethodName()
+			return strings.ReplaceAll(receiverType, "*", "") + "." + methodName, true
+		}
+
+		return sc.GetFuncName()
+	case scope.Const:
+		return firstConstName(sc)
+	}
+
+	return "", false
+}
+
+// importString returns the import declaration of the import paths, it's empty without any path.
+func importString(importPaths []string) string {
+	if len(importPaths) == 0 {
+		return ""
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("\nimport (\n")
+	for _, path := range importPaths {
+		if alias, p, ok := strings.Cut(path, " "); ok {
+			buf.WriteString(fmt.Sprintf("\t%s %q\n", alias, p))
+			continue
+		}
+
+		buf.WriteString(fmt.Sprintf("\t%q\n", path))
+	}
+	buf.WriteString(")\n")
+
+	return buf.String()
+}
+
+// firstConstName returns the name of the first constant declared in the const scope.
+func firstConstName(sc goast.Scope) (string, bool) {
+	var name string
+	sc.Node().IterNext(func(n *goast.Node) bool {
+		switch n.Kind() {
+		case kind.Const, kind.Space, kind.Tab, kind.NewLine, kind.ParenthesisLeft, kind.Comment:
+			return true
+		}
+
+		name = n.Text()
+		return false
+	})
+
+	return name, len(name) != 0
+}
+
+func scopeContains(sc goast.Scope, s string) bool {
+	found := false
+	sc.Node().IterNext(func(n *goast.Node) bool {
+		found = strings.Contains(n.Text(), s)
+		return !found
+	})
+
+	return found
+}
+
+// importPathOf returns the import path of the import entry like 'path' or 'alias path'.
+func importPathOf(entry string) string {
+	if _, path, ok := strings.Cut(entry, " "); ok {
+		return path
+	}
+
+	return entry
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+
+	return path
+}
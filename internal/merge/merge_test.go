@@ -0,0 +1,64 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanun0323/gox/gen"
+)
+
+func TestDestinationSave(t *testing.T) {
+	var actions []gen.Action
+	des := Destination{
+		File:    filepath.Join(t.TempDir(), "member"),
+		Package: "entity",
+		Record:  func(kind gen.ActionKind, name string) { actions = append(actions, gen.Action{Kind: kind, Name: name}) },
+	}
+
+	if err := des.Save([]string{"fmt"}, []Scope{
+		{Key: "Member", Text: "type Member struct{}\n"},
+		{Key: "Member.String", Text: "func (Member) String() string {\n\treturn fmt.Sprint(1)\n}\n"},
+	}); err != nil {
+		t.Fatalf("create, err: %+v", err)
+	}
+
+	des.Replace = true
+	if err := des.Save([]string{"strings"}, []Scope{
+		{Key: "Member.String", Text: "// String returns the name.\nfunc (Member) String() string {\n\treturn strings.ToUpper(\"m\")\n}\n"},
+		{Key: "_kinds", Text: "const (\n\t_kinds = 1\n)\n"},
+	}); err != nil {
+		t.Fatalf("replace, err: %+v", err)
+	}
+
+	des.Replace = false
+	if err := des.Save(nil, []Scope{{Key: "_kinds", Text: "const _kinds = 2\n"}}); err != nil {
+		t.Fatalf("skip, err: %+v", err)
+	}
+
+	data, err := os.ReadFile(des.Path())
+	if err != nil {
+		t.Fatalf("read, err: %+v", err)
+	}
+
+	text := string(data)
+	for _, want := range []string{"package entity", `"strings"`, "// String returns the name.", "strings.ToUpper", "_kinds = 1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("destination misses %q:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "fmt.Sprint") || strings.Contains(text, "_kinds = 2") {
+		t.Fatalf("destination mismatch:\n%s", text)
+	}
+
+	kinds := make([]string, len(actions))
+	for i, a := range actions {
+		kinds[i] = string(a.Kind)
+	}
+
+	if got := strings.Join(kinds, ","); got != "create_file,add,add,update_file,replace,add,update_file,skip" {
+		t.Fatalf("actions mismatch: %s", got)
+	}
+}
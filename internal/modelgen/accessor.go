@@ -0,0 +1,191 @@
+package modelgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+// generateFieldConstants generates the field name constants of the models, the names are derived
+// from the tags of -nametags in order, e.g. the gorm column, then the db and json tags.
+func generateFieldConstants(ms *modelSet) []merge.Scope {
+	var result []merge.Scope
+	for _, m := range ms.Models {
+		var (
+			names    []string
+			values   []string
+			addField func(fields []structField, prefix string, visited map[string]bool)
+		)
+
+		addField = func(fields []structField, prefix string, visited map[string]bool) {
+			for _, f := range fields {
+				if !f.IsExported() {
+					continue
+				}
+
+				if f.Embedded {
+					t := parseTypeExpr(strings.TrimPrefix(f.Type, "*"))
+					if embedded, ok := ms.Lookup(t.Name); ok && !visited[t.Name] {
+						visited[t.Name] = true
+						addField(embedded.Fields, prefix+parseGormTag(f)["EMBEDDEDPREFIX"], visited)
+						continue
+					}
+				}
+
+				name, ok := fieldColumnName(f)
+				if !ok {
+					continue
+				}
+
+				names = append(names, m.Name+"Field"+f.FieldName())
+				values = append(values, prefix+name)
+			}
+		}
+
+		addField(m.Fields, "", map[string]bool{m.Name: true})
+		if len(names) == 0 || !ms.ShouldDeclare(names[0]) {
+			continue
+		}
+
+		buf := strings.Builder{}
+		buf.WriteString(fmt.Sprintf("// The field names of the %s.\n", m.Name))
+		buf.WriteString("const (\n")
+		for i := range names {
+			buf.WriteString(fmt.Sprintf("\t%s = %s\n", names[i], strconv.Quote(values[i])))
+		}
+		buf.WriteString(")\n")
+
+		result = append(result, merge.Scope{Key: names[0], Text: buf.String()})
+	}
+
+	return result
+}
+
+// fieldColumnName returns the name of the field in the first tag of -nametags, the snake case of the
+// field name is returned when none of the tags is declared. It returns false when the field is
+// ignored by the tag.
+func fieldColumnName(f structField) (string, bool) {
+	for _, key := range strings.Split(*_nameTags, ",") {
+		key = strings.TrimSpace(key)
+		if key == "gorm" {
+			setting := parseGormTag(f)
+			if _, ignored := setting["-"]; ignored {
+				return "", false
+			}
+
+			if column := setting["COLUMN"]; len(column) != 0 {
+				return column, true
+			}
+
+			continue
+		}
+
+		value, _, ok := f.TagValue(key)
+		switch {
+		case !ok || len(value) == 0:
+			continue
+		case value == "-":
+			return "", false
+		default:
+			return value, true
+		}
+	}
+
+	return helper.SnakeCase(f.FieldName()), true
+}
+
+// generateAccessors generates the nil-safe getters with -getters and setters with -setters of the
+// exported fields of the models.
+func generateAccessors(ms *modelSet) []merge.Scope {
+	var result []merge.Scope
+	for _, m := range ms.Models {
+		receiver := m.Receiver()
+		for _, f := range m.Fields {
+			if !f.IsExported() {
+				continue
+			}
+
+			name := f.FieldName()
+			if getter := "Get" + name; *_getters && ms.ShouldGenerate(m, getter) {
+				result = append(result, merge.Scope{
+					Key:  m.Name + "." + getter,
+					Text: genGetterString(ms, m, receiver, getter, name, f.Type),
+				})
+			}
+
+			if setter := "Set" + name; *_setters && ms.ShouldGenerate(m, setter) {
+				param := helper.LowerCamelCase(name)
+				if _goKeywords[param] || param == receiver {
+					param += "Value"
+				}
+
+				result = append(result, merge.Scope{
+					Key: m.Name + "." + setter,
+					Text: fmt.Sprintf(`// %s sets the %s of the %s, it does nothing when the %s is nil.
+func (%s *%s) %s(%s %s) {
+	if %s != nil {
+		%s.%s = %s
+	}
+}
+`, setter, name, m.Name, m.Name, receiver, m.Type(), setter, param, f.Type, receiver, receiver, name, param),
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+func genGetterString(ms *modelSet, m *model, receiver, getter, name, typeText string) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s returns the %s of the %s, it returns the zero value when the %s is nil.\n", getter, name, m.Name, m.Name))
+	buf.WriteString(fmt.Sprintf("func (%s *%s) %s() %s {\n", receiver, m.Type(), getter, typeText))
+	buf.WriteString(fmt.Sprintf("\tif %s != nil {\n\t\treturn %s.%s\n\t}\n\n", receiver, receiver, name))
+	if zero, ok := zeroValue(ms, parseTypeExpr(typeText)); ok {
+		buf.WriteString(fmt.Sprintf("\treturn %s\n}\n", zero))
+	} else {
+		buf.WriteString(fmt.Sprintf("\tvar zero %s\n\treturn zero\n}\n", typeText))
+	}
+
+	return buf.String()
+}
+
+// zeroValue returns the zero value literal of the type, it returns false when the type is unknown.
+func zeroValue(ms *modelSet, t *typeExpr) (string, bool) {
+	switch t.Kind {
+	case typePointer, typeSlice, typeMap, typeChan, typeFunc, typeInterface:
+		return "nil", true
+	case typeArray, typeStruct:
+		return t.Raw + "{}", true
+	}
+
+	switch {
+	case t.Name == "string":
+		return `""`, true
+	case t.Name == "bool":
+		return "false", true
+	case t.Name == "any" || t.Name == "error":
+		return "nil", true
+	case _basicTypes[t.Name]:
+		return "0", true
+	case t.Name == "time.Time":
+		return "time.Time{}", true
+	}
+
+	if _, ok := ms.Lookup(t.Name); ok {
+		return t.Raw + "{}", true
+	}
+
+	if underlying, ok := ms.Underlying(t.Name); ok {
+		zero, ok := zeroValue(ms, underlying)
+		if ok && strings.HasSuffix(zero, "{}") {
+			zero = t.Raw + "{}"
+		}
+
+		return zero, ok
+	}
+
+	return "", false
+}
@@ -0,0 +1,104 @@
+package modelgen
+
+import "testing"
+
+func TestFieldColumnName(t *testing.T) {
+	for _, tc := range []struct {
+		field    structField
+		expected string
+		ok       bool
+	}{
+		{structField{Name: "ID", Tag: `gorm:"column:id;primaryKey" json:"identity"`}, "id", true},
+		{structField{Name: "Key", Tag: `gorm:"size:64" json:"key,omitempty"`}, "key", true},
+		{structField{Name: "CreatedAt"}, "created_at", true},
+		{structField{Name: "Ignored", Tag: `gorm:"-"`}, "", false},
+		{structField{Name: "Hidden", Tag: `json:"-"`}, "", false},
+	} {
+		name, ok := fieldColumnName(tc.field)
+		if name != tc.expected || ok != tc.ok {
+			t.Fatalf("column name of %s mismatch: %s, %t", tc.field.Name, name, ok)
+		}
+	}
+}
+
+func TestGenerateAccessors(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+import "time"
+
+//go:generate modelgen -type=Member -relative -fields -getters -setters -destination=../entity/member.go -package=entity
+
+type Member struct {
+	Base      ` + "`gorm:\"embedded;embeddedPrefix:base_\"`" + `
+	ID        int64             ` + "`gorm:\"column:member_id\"`" + `
+	Name      string            ` + "`json:\"name\"`" + `
+	Type      string
+	Status    Status
+	Labels    map[string]string
+	Profile   *Profile
+	CreatedAt time.Time
+	Ignored   string ` + "`gorm:\"-\"`" + `
+	note      string
+}
+
+type Base struct {
+	Version int
+}
+
+type Profile struct {
+	Nick string
+}
+
+type Status int
+`,
+	}, map[string]string{
+		"entity/member_test.go": `package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccessors(t *testing.T) {
+	var empty *Member
+	if empty.GetID() != 0 || empty.GetName() != "" || empty.GetStatus() != 0 || empty.GetLabels() != nil ||
+		empty.GetProfile() != nil || !empty.GetCreatedAt().IsZero() || empty.GetBase() != (Base{}) {
+		t.Fatal("getters of nil should return the zero values")
+	}
+
+	empty.SetID(1)
+	empty.SetType("t")
+
+	m := &Member{}
+	now := time.Now()
+	m.SetID(1)
+	m.SetName("n")
+	m.SetType("t")
+	m.SetStatus(Status(2))
+	m.SetLabels(map[string]string{"k": "v"})
+	m.SetProfile(&Profile{Nick: "p"})
+	m.SetCreatedAt(now)
+	m.SetBase(Base{Version: 3})
+
+	if m.GetID() != 1 || m.GetName() != "n" || m.GetType() != "t" || m.GetStatus() != 2 || m.GetLabels()["k"] != "v" ||
+		m.GetProfile().GetNick() != "p" || !m.GetCreatedAt().Equal(now) || m.GetBase().Version != 3 {
+		t.Fatalf("getters mismatch: %+v", m)
+	}
+
+	for name, field := range map[string]string{
+		"member_id":    MemberFieldID,
+		"name":         MemberFieldName,
+		"type":         MemberFieldType,
+		"created_at":   MemberFieldCreatedAt,
+		"base_version": MemberFieldVersion,
+		"nick":         ProfileFieldNick,
+	} {
+		if name != field {
+			t.Fatalf("field name %s mismatch: %s", name, field)
+		}
+	}
+}
+`,
+	})
+}
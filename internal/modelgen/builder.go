@@ -0,0 +1,177 @@
+package modelgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_tagGox         = "gox"
+	_optionRequired = "required"
+)
+
+// builderField is an exported field of the target struct set by the builder.
+type builderField struct {
+	Name     string
+	Type     string
+	Param    string
+	Required bool
+}
+
+// generateBuilder generates the fluent builder of the target model, and the functional options
+// built on the builder with -options.
+func generateBuilder(ms *modelSet) []merge.Scope {
+	var (
+		m        = ms.Target
+		builder  = m.Name + "Builder"
+		fields   []builderField
+		required bool
+	)
+
+	for _, f := range m.Fields {
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.FieldName()
+		param := helper.LowerCamelCase(name)
+		if _goKeywords[param] || param == "b" {
+			param += "Value"
+		}
+
+		value, options, _ := f.TagValue(_tagGox)
+		isRequired := value == _optionRequired || hasOption(options, _optionRequired)
+		required = required || isRequired
+		fields = append(fields, builderField{
+			Name:     name,
+			Type:     f.Type,
+			Param:    param,
+			Required: isRequired,
+		})
+	}
+
+	var result []merge.Scope
+	add := func(key, text string) {
+		if ms.ShouldDeclare(key) {
+			result = append(result, merge.Scope{Key: key, Text: text})
+		}
+	}
+
+	if *_builder || *_options {
+		add(builder, genBuilderStructString(m, builder, fields))
+		add("New"+builder, fmt.Sprintf(`// New%s returns a builder of the %s.
+func New%s() *%s {
+	return &%s{}
+}
+`, builder, m.Name, builder, builder, builder))
+
+		for _, f := range fields {
+			add(builder+"."+f.Name, genBuilderSetterString(m, builder, f))
+		}
+
+		add(builder+"."+"Build", genBuilderBuildString(ms, m, builder, fields, required))
+	}
+
+	if *_options {
+		option := m.Name + "Option"
+		add(option, fmt.Sprintf(`// %s sets a field of the %s built by New%s.
+type %s func(*%s)
+`, option, m.Name, m.Name, option, builder))
+
+		// the options are prefixed by the type, since the models of a destination share the package scope
+		for _, f := range fields {
+			with := "With" + m.Name + f.Name
+			add(with, fmt.Sprintf(`// %s sets the %s of the %s.
+func %s(%s %s) %s {
+	return func(builder *%s) {
+		builder.%s(%s)
+	}
+}
+`, with, f.Name, m.Name, with, f.Param, f.Type, option, builder, f.Name, f.Param))
+		}
+
+		add("New"+m.Name, genOptionsConstructorString(m, builder, option, required))
+	}
+
+	return result
+}
+
+func genBuilderStructString(m *model, builder string, fields []builderField) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s builds the %s field by field.\n", builder, m.Name))
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", builder))
+	buf.WriteString(fmt.Sprintf("\tvalue %s\n", m.Name))
+	for _, f := range fields {
+		if f.Required {
+			buf.WriteString(fmt.Sprintf("\thas%s bool\n", f.Name))
+		}
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+func genBuilderSetterString(m *model, builder string, f builderField) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s sets the %s of the %s.\n", f.Name, f.Name, m.Name))
+	buf.WriteString(fmt.Sprintf("func (b *%s) %s(%s %s) *%s {\n", builder, f.Name, f.Param, f.Type, builder))
+	buf.WriteString(fmt.Sprintf("\tb.value.%s = %s\n", f.Name, f.Param))
+	if f.Required {
+		buf.WriteString(fmt.Sprintf("\tb.has%s = true\n", f.Name))
+	}
+	buf.WriteString("\treturn b\n}\n")
+
+	return buf.String()
+}
+
+func genBuilderBuildString(ms *modelSet, m *model, builder string, fields []builderField, required bool) string {
+	if !required {
+		return fmt.Sprintf(`// Build returns the %s built by the builder.
+func (b *%s) Build() *%s {
+	value := b.value
+	return &value
+}
+`, m.Name, builder, m.Name)
+	}
+
+	ms.addImport("fmt")
+	ms.addImport("strings")
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// Build returns the %s built by the builder, it returns an error listing the missing fields\n", m.Name))
+	buf.WriteString("// when the required fields are not set.\n")
+	buf.WriteString(fmt.Sprintf("func (b *%s) Build() (*%s, error) {\n", builder, m.Name))
+	buf.WriteString("\tvar missing []string\n")
+	for _, f := range fields {
+		if f.Required {
+			buf.WriteString(fmt.Sprintf("\tif !b.has%s {\n\t\tmissing = append(missing, %q)\n\t}\n", f.Name, f.Name))
+		}
+	}
+	buf.WriteString("\n\tif len(missing) != 0 {\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"build %s, missing required fields: %%s\", strings.Join(missing, \", \"))\n", m.Name))
+	buf.WriteString("\t}\n\n")
+	buf.WriteString("\tvalue := b.value\n")
+	buf.WriteString("\treturn &value, nil\n}\n")
+
+	return buf.String()
+}
+
+func genOptionsConstructorString(m *model, builder, option string, required bool) string {
+	result := "*" + m.Name
+	if required {
+		result = fmt.Sprintf("(*%s, error)", m.Name)
+	}
+
+	return fmt.Sprintf(`// New%s returns the %s set by the options.
+func New%s(opts ...%s) %s {
+	builder := New%s()
+	for _, opt := range opts {
+		opt(builder)
+	}
+
+	return builder.Build()
+}
+`, m.Name, m.Name, m.Name, option, result, builder)
+}
@@ -0,0 +1,47 @@
+package modelgen
+
+import "testing"
+
+func TestGenerateBuilder(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+//go:generate modelgen -type=Member,Item -builder -options -destination=../entity/entity.go -package=entity
+
+type Member struct {
+	ID   int64
+	Name string ` + "`gox:\"required\"`" + `
+}
+
+type Item struct {
+	ID    int64
+	Price float64
+}
+`,
+	}, map[string]string{
+		"entity/entity_test.go": `package entity
+
+import "testing"
+
+func TestOptions(t *testing.T) {
+	member, err := NewMember(WithMemberID(1), WithMemberName("alice"))
+	if err != nil || member.ID != 1 || member.Name != "alice" {
+		t.Fatalf("member mismatch: %+v, err: %+v", member, err)
+	}
+
+	if _, err := NewMember(WithMemberID(2)); err == nil {
+		t.Fatal("missing required name should fail")
+	}
+
+	item := NewItem(WithItemID(3), WithItemPrice(1.5))
+	if item.ID != 3 || item.Price != 1.5 {
+		t.Fatalf("item mismatch: %+v", item)
+	}
+
+	if built := NewItemBuilder().ID(4).Build(); built.ID != 4 {
+		t.Fatalf("built item mismatch: %+v", built)
+	}
+}
+`,
+	})
+}
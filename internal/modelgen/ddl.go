@@ -0,0 +1,751 @@
+package modelgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/goast"
+)
+
+const (
+	_formatSQL = "sql"
+
+	_dialectPostgres = "postgres"
+	_dialectMySQL    = "mysql"
+	_dialectSQLite   = "sqlite"
+)
+
+// sqlTable is the table definition generated from a gorm-tagged struct.
+//
+// It is also the snapshot format used to diff against the previous generation.
+type sqlTable struct {
+	Dialect string      `json:"dialect"`
+	Name    string      `json:"name"`
+	Columns []sqlColumn `json:"columns"`
+	Indexes []sqlIndex  `json:"indexes,omitempty"`
+}
+
+type sqlColumn struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	PrimaryKey    bool   `json:"primaryKey,omitempty"`
+	AutoIncrement bool   `json:"autoIncrement,omitempty"`
+	NotNull       bool   `json:"notNull,omitempty"`
+	Unique        bool   `json:"unique,omitempty"`
+	Default       string `json:"default,omitempty"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+type sqlIndex struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+// sqlBinding binds the column of the same index to the struct field, it's kept out of the
+// snapshot and used by the generated repository.
+type sqlBinding struct {
+	// Field is the selector of the field from the struct, e.g. 'Base.ID'.
+	Field string
+	// Type is the go type of the field.
+	Type string
+	// Pointers is the embedded struct pointers on the way to the field, outer first.
+	Pointers []sqlEmbeddedPointer
+	// Serialized reports whether the field is stored by the gorm serializer.
+	Serialized bool
+}
+
+// sqlEmbeddedPointer is the embedded struct pointer like the 'Meta' of '*Meta'.
+type sqlEmbeddedPointer struct {
+	Field string
+	Type  string
+}
+
+// generateDDLAndSave generates the CREATE TABLE statement of the target struct, or the ALTER
+// statements when the snapshot of the previous generation exists, then saves it to the destination.
+func generateDDLAndSave(ast goast.Ast, targetScope goast.Scope, structName string) error {
+	switch *_dialect {
+	case _dialectPostgres, _dialectMySQL, _dialectSQLite:
+	default:
+		return fmt.Errorf("unsupported dialect: %s", *_dialect)
+	}
+
+	table, _, err := newSQLTable(ast, targetScope, structName)
+	if err != nil {
+		return err
+	}
+
+	var (
+		destination = formatDestination(".sql")
+		previous    *sqlTable
+	)
+
+	if len(*_snapshot) != 0 {
+		previous, err = loadSQLSnapshot(*_snapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	var statements []string
+	if previous == nil {
+		statements = table.CreateStatements()
+	} else {
+		if previous.Dialect != table.Dialect {
+			return fmt.Errorf("snapshot dialect %s mismatch: %s", previous.Dialect, table.Dialect)
+		}
+
+		statements = table.AlterStatements(previous)
+		if len(statements) == 0 {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return fmt.Errorf("make destination directory, err: %w", err)
+	}
+
+	text := fmt.Sprintf("-- Code generated by %s. DO NOT EDIT.\n\n%s\n", _commandName, strings.Join(statements, "\n\n"))
+	helper.recordFile(destination)
+	if err := os.WriteFile(destination, []byte(text), 0o644); err != nil {
+		return fmt.Errorf("save ddl, err: %w", err)
+	}
+
+	if len(*_snapshot) != 0 {
+		if err := saveSQLSnapshot(*_snapshot, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newSQLTable returns the table of the target struct and the bindings of its columns.
+func newSQLTable(ast goast.Ast, targetScope goast.Scope, structName string) (*sqlTable, []sqlBinding, error) {
+	fields, err := parseStructFields(targetScope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse fields of %s, err: %w", structName, err)
+	}
+
+	structs, _ := findRelativeScopes(ast, targetScope)
+
+	name := *_table
+	if len(name) == 0 {
+		name = helper.Pluralize(helper.SnakeCase(*_name))
+	}
+
+	table := &sqlTable{
+		Dialect: *_dialect,
+		Name:    name,
+	}
+
+	builder := ddlBuilder{
+		table:      table,
+		structs:    structs,
+		underlying: findUnderlyingTypes(ast),
+		indexes:    map[string]int{},
+
+		noAutoIncrement: map[string]bool{},
+	}
+
+	if err := builder.addColumns(fields, "", sqlBinding{}); err != nil {
+		return nil, nil, err
+	}
+
+	if len(table.primaryKeys()) == 0 {
+		for i, column := range table.Columns {
+			if column.Name == "id" {
+				table.Columns[i].PrimaryKey = true
+				break
+			}
+		}
+	}
+
+	// like gorm, a single integer primary key is auto increment by default
+	singlePrimaryKey := len(table.primaryKeys()) == 1
+	for i, column := range table.Columns {
+		if !column.PrimaryKey {
+			continue
+		}
+
+		table.Columns[i].NotNull = true
+		if singlePrimaryKey && builder.isInteger(column.Type) && !builder.noAutoIncrement[column.Name] {
+			table.Columns[i].AutoIncrement = true
+		}
+	}
+
+	return table, builder.bindings, nil
+}
+
+type ddlBuilder struct {
+	table      *sqlTable
+	bindings   []sqlBinding
+	structs    map[string]goast.Scope
+	underlying map[string]string
+	indexes    map[string]int
+
+	noAutoIncrement map[string]bool
+}
+
+// parseGormTag parses the gorm tag into upper case keys like gorm does, e.g. 'not null' to 'NOT NULL'.
+func parseGormTag(f structField) map[string]string {
+	result := map[string]string{}
+	value, ok := reflect.StructTag(f.Tag).Lookup("gorm")
+	if !ok {
+		return result
+	}
+
+	for _, setting := range strings.Split(value, ";") {
+		kv := strings.Split(setting, ":")
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if len(key) == 0 {
+			continue
+		}
+
+		result[key] = strings.Join(kv[1:], ":")
+	}
+
+	return result
+}
+
+// addColumns adds the columns of the fields, the parent is the binding of the struct embedding the fields.
+func (b *ddlBuilder) addColumns(fields []structField, prefix string, parent sqlBinding) error {
+	for _, field := range fields {
+		setting := parseGormTag(field)
+		if _, ignored := setting["-"]; ignored || !field.IsExported() {
+			continue
+		}
+
+		t := parseTypeExpr(field.Type)
+		for t.Kind == typePointer {
+			t = t.Elem
+		}
+
+		binding := sqlBinding{
+			Field:    parent.Field + field.FieldName(),
+			Type:     field.Type,
+			Pointers: parent.Pointers,
+		}
+
+		_, isEmbedded := setting["EMBEDDED"]
+		if field.Embedded || isEmbedded {
+			embedded := binding
+			embedded.Field += "."
+			if strings.HasPrefix(field.Type, "*") {
+				embedded.Pointers = append(append([]sqlEmbeddedPointer{}, parent.Pointers...), sqlEmbeddedPointer{
+					Field: binding.Field,
+					Type:  strings.TrimPrefix(field.Type, "*"),
+				})
+			}
+
+			if t.Name == "gorm.Model" {
+				if err := b.addColumns(_gormModelFields, prefix, embedded); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if sc, ok := b.structs[t.Name]; ok {
+				fields, err := parseStructFields(sc)
+				if err != nil {
+					return fmt.Errorf("parse fields of %s, err: %w", t.Name, err)
+				}
+
+				if err := b.addColumns(fields, prefix+setting["EMBEDDEDPREFIX"], embedded); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		column := sqlColumn{
+			Name:    setting["COLUMN"],
+			Type:    setting["TYPE"],
+			Default: setting["DEFAULT"],
+			Comment: setting["COMMENT"],
+		}
+
+		if len(column.Name) == 0 {
+			column.Name = helper.SnakeCase(field.FieldName())
+		}
+		column.Name = prefix + column.Name
+
+		if len(column.Type) == 0 {
+			sqlType, ok := b.sqlType(t, setting)
+			if !ok {
+				// associations and unknown types can't be mapped to a column
+				continue
+			}
+
+			column.Type = sqlType
+		}
+
+		_, column.PrimaryKey = setting["PRIMARYKEY"]
+		_, column.NotNull = setting["NOT NULL"]
+		_, column.Unique = setting["UNIQUE"]
+		if v, ok := setting["AUTOINCREMENT"]; ok && !strings.EqualFold(v, "false") {
+			column.AutoIncrement = true
+		}
+
+		if v, ok := setting["PRIMARYKEY"]; ok && strings.EqualFold(v, "false") {
+			column.PrimaryKey = false
+		}
+
+		if v, ok := setting["AUTOINCREMENT"]; ok && strings.EqualFold(v, "false") {
+			b.noAutoIncrement[column.Name] = true
+		}
+
+		_, binding.Serialized = setting["SERIALIZER"]
+
+		b.table.Columns = append(b.table.Columns, column)
+		b.bindings = append(b.bindings, binding)
+
+		for _, key := range []string{"INDEX", "UNIQUEINDEX"} {
+			value, ok := setting[key]
+			if !ok {
+				continue
+			}
+
+			options := strings.Split(value, ",")
+			unique := key == "UNIQUEINDEX" || hasOption(options[1:], "unique")
+			name := strings.TrimSpace(options[0])
+			if len(name) == 0 {
+				prefix := "idx_"
+				if unique {
+					prefix = "uni_"
+				}
+
+				name = prefix + b.table.Name + "_" + column.Name
+			}
+
+			b.addIndex(name, unique, column.Name)
+		}
+	}
+
+	return nil
+}
+
+func (b *ddlBuilder) addIndex(name string, unique bool, column string) {
+	if i, ok := b.indexes[name]; ok {
+		b.table.Indexes[i].Columns = append(b.table.Indexes[i].Columns, column)
+		b.table.Indexes[i].Unique = b.table.Indexes[i].Unique || unique
+		return
+	}
+
+	b.indexes[name] = len(b.table.Indexes)
+	b.table.Indexes = append(b.table.Indexes, sqlIndex{Name: name, Unique: unique, Columns: []string{column}})
+}
+
+// _gormModelFields is the fields of the embedded gorm.Model.
+var _gormModelFields = []structField{
+	{Name: "ID", Type: "uint", Tag: `gorm:"primarykey"`},
+	{Name: "CreatedAt", Type: "time.Time"},
+	{Name: "UpdatedAt", Type: "time.Time"},
+	{Name: "DeletedAt", Type: "gorm.DeletedAt", Tag: `gorm:"index"`},
+}
+
+// sqlType returns the column type of the go type in the dialect.
+func (b *ddlBuilder) sqlType(t *typeExpr, setting map[string]string) (string, bool) {
+	size, _ := strconv.Atoi(setting["SIZE"])
+
+	if t.Kind == typeSlice && (t.Elem.Name == "byte" || t.Elem.Name == "uint8") {
+		return b.dialectType("bytea", "longblob", "blob"), true
+	}
+
+	if t.Kind != typeIdent {
+		if _, ok := setting["SERIALIZER"]; ok {
+			return b.dialectType("jsonb", "json", "text"), true
+		}
+
+		return "", false
+	}
+
+	name := t.Name
+	if underlying, ok := b.underlying[name]; ok {
+		return b.sqlType(parseTypeExpr(underlying), setting)
+	}
+
+	if strings.HasPrefix(name, "sql.Null") {
+		name = strings.ToLower(strings.TrimPrefix(name, "sql.Null"))
+		if name == "time" {
+			name = "time.Time"
+		}
+	}
+
+	switch name {
+	case "bool":
+		return b.dialectType("boolean", "boolean", "numeric"), true
+	case "int8":
+		return b.dialectType("smallint", "tinyint", "integer"), true
+	case "int16":
+		return b.dialectType("smallint", "smallint", "integer"), true
+	case "int32", "rune":
+		return b.dialectType("integer", "int", "integer"), true
+	case "int", "int64", "time.Duration":
+		return b.dialectType("bigint", "bigint", "integer"), true
+	case "uint8", "byte":
+		return b.dialectType("smallint", "tinyint unsigned", "integer"), true
+	case "uint16":
+		return b.dialectType("integer", "smallint unsigned", "integer"), true
+	case "uint32":
+		return b.dialectType("bigint", "int unsigned", "integer"), true
+	case "uint", "uint64":
+		return b.dialectType("bigint", "bigint unsigned", "integer"), true
+	case "float32":
+		return b.dialectType("real", "float", "real"), true
+	case "float64":
+		return b.dialectType("double precision", "double", "real"), true
+	case "string":
+		if size > 0 {
+			return b.dialectType(fmt.Sprintf("varchar(%d)", size), fmt.Sprintf("varchar(%d)", size), "text"), true
+		}
+
+		_, isPrimaryKey := setting["PRIMARYKEY"]
+		_, isIndex := setting["INDEX"]
+		_, isUniqueIndex := setting["UNIQUEINDEX"]
+		if isPrimaryKey || isIndex || isUniqueIndex {
+			return b.dialectType("text", "varchar(191)", "text"), true
+		}
+
+		return b.dialectType("text", "longtext", "text"), true
+	case "time.Time", "gorm.DeletedAt":
+		return b.dialectType("timestamptz", "datetime(3)", "datetime"), true
+	case "json.RawMessage", "datatypes.JSON":
+		return b.dialectType("jsonb", "json", "text"), true
+	}
+
+	if _, ok := setting["SERIALIZER"]; ok {
+		return b.dialectType("jsonb", "json", "text"), true
+	}
+
+	return "", false
+}
+
+func (b *ddlBuilder) dialectType(postgres, mysql, sqlite string) string {
+	switch b.table.Dialect {
+	case _dialectMySQL:
+		return mysql
+	case _dialectSQLite:
+		return sqlite
+	default:
+		return postgres
+	}
+}
+
+func (b *ddlBuilder) isInteger(sqlType string) bool {
+	return strings.Contains(sqlType, "int")
+}
+
+func (t *sqlTable) quote(name string) string {
+	if t.Dialect == _dialectMySQL {
+		return "`" + name + "`"
+	}
+
+	return `"` + name + `"`
+}
+
+func (t *sqlTable) quoteList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, t.quote(name))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func (t *sqlTable) primaryKeys() []string {
+	var keys []string
+	for _, column := range t.Columns {
+		if column.PrimaryKey {
+			keys = append(keys, column.Name)
+		}
+	}
+
+	return keys
+}
+
+// inlinePrimaryKey reports whether sqlite declares the primary key on the column itself,
+// which is required by AUTOINCREMENT.
+func (t *sqlTable) inlinePrimaryKey(column sqlColumn) bool {
+	return t.Dialect == _dialectSQLite && column.AutoIncrement && len(t.primaryKeys()) == 1
+}
+
+// columnDefinition returns the column definition like '"id" bigserial NOT NULL'.
+func (t *sqlTable) columnDefinition(column sqlColumn) string {
+	sqlType := column.Type
+	if column.AutoIncrement && t.Dialect == _dialectPostgres {
+		switch sqlType {
+		case "smallint":
+			sqlType = "smallserial"
+		case "integer":
+			sqlType = "serial"
+		case "bigint":
+			sqlType = "bigserial"
+		}
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(t.quote(column.Name))
+	buf.WriteString(" ")
+	buf.WriteString(sqlType)
+
+	if t.inlinePrimaryKey(column) {
+		buf.WriteString(" PRIMARY KEY AUTOINCREMENT")
+	} else if column.AutoIncrement && t.Dialect == _dialectMySQL {
+		buf.WriteString(" AUTO_INCREMENT")
+	}
+
+	if column.NotNull {
+		buf.WriteString(" NOT NULL")
+	}
+
+	if column.Unique {
+		buf.WriteString(" UNIQUE")
+	}
+
+	if len(column.Default) != 0 {
+		buf.WriteString(" DEFAULT ")
+		buf.WriteString(column.Default)
+	}
+
+	if len(column.Comment) != 0 && t.Dialect == _dialectMySQL {
+		buf.WriteString(" COMMENT ")
+		buf.WriteString(quoteSQLString(column.Comment))
+	}
+
+	return buf.String()
+}
+
+func (t *sqlTable) indexStatement(index sqlIndex) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+
+	ifNotExists := "IF NOT EXISTS "
+	if t.Dialect == _dialectMySQL {
+		ifNotExists = ""
+	}
+
+	return fmt.Sprintf("CREATE %sINDEX %s%s ON %s (%s);", unique, ifNotExists, t.quote(index.Name), t.quote(t.Name), t.quoteList(index.Columns))
+}
+
+func (t *sqlTable) dropIndexStatement(index sqlIndex) string {
+	if t.Dialect == _dialectMySQL {
+		return fmt.Sprintf("DROP INDEX %s ON %s;", t.quote(index.Name), t.quote(t.Name))
+	}
+
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;", t.quote(index.Name))
+}
+
+func (t *sqlTable) commentStatements(columns []sqlColumn) []string {
+	if t.Dialect != _dialectPostgres {
+		return nil
+	}
+
+	var statements []string
+	for _, column := range columns {
+		if len(column.Comment) != 0 {
+			statements = append(statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", t.quote(t.Name), t.quote(column.Name), quoteSQLString(column.Comment)))
+		}
+	}
+
+	return statements
+}
+
+// CreateStatements returns the CREATE TABLE statement and the statements creating its indexes.
+func (t *sqlTable) CreateStatements() []string {
+	definitions := make([]string, 0, len(t.Columns)+1)
+	inlined := false
+	for _, column := range t.Columns {
+		definitions = append(definitions, "\t"+t.columnDefinition(column))
+		inlined = inlined || t.inlinePrimaryKey(column)
+	}
+
+	if keys := t.primaryKeys(); len(keys) != 0 && !inlined {
+		definitions = append(definitions, fmt.Sprintf("\tPRIMARY KEY (%s)", t.quoteList(keys)))
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);", t.quote(t.Name), strings.Join(definitions, ",\n")),
+	}
+
+	statements = append(statements, t.commentStatements(t.Columns)...)
+
+	for _, index := range t.Indexes {
+		statements = append(statements, t.indexStatement(index))
+	}
+
+	return statements
+}
+
+// AlterStatements returns the statements migrating the previous table to the current one.
+func (t *sqlTable) AlterStatements(previous *sqlTable) []string {
+	var statements []string
+
+	if previous.Name != t.Name {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", t.quote(previous.Name), t.quote(t.Name)))
+	}
+
+	previousColumns := make(map[string]sqlColumn, len(previous.Columns))
+	for _, column := range previous.Columns {
+		previousColumns[column.Name] = column
+	}
+
+	var changedComments []sqlColumn
+	for _, column := range t.Columns {
+		old, ok := previousColumns[column.Name]
+		delete(previousColumns, column.Name)
+
+		if !ok {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", t.quote(t.Name), t.columnDefinition(column)))
+			changedComments = append(changedComments, column)
+			continue
+		}
+
+		if old == column {
+			continue
+		}
+
+		statements = append(statements, t.alterColumnStatements(old, column)...)
+		if old.Comment != column.Comment {
+			changedComments = append(changedComments, column)
+		}
+	}
+
+	statements = append(statements, t.commentStatements(changedComments)...)
+
+	dropped := make([]string, 0, len(previousColumns))
+	for name := range previousColumns {
+		dropped = append(dropped, name)
+	}
+	sort.Strings(dropped)
+
+	for _, name := range dropped {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", t.quote(t.Name), t.quote(name)))
+	}
+
+	previousIndexes := make(map[string]sqlIndex, len(previous.Indexes))
+	for _, index := range previous.Indexes {
+		previousIndexes[index.Name] = index
+	}
+
+	for _, index := range t.Indexes {
+		old, ok := previousIndexes[index.Name]
+		delete(previousIndexes, index.Name)
+
+		if ok && old.Unique == index.Unique && strings.Join(old.Columns, ",") == strings.Join(index.Columns, ",") {
+			continue
+		}
+
+		if ok {
+			statements = append(statements, t.dropIndexStatement(old))
+		}
+
+		statements = append(statements, t.indexStatement(index))
+	}
+
+	for _, index := range previous.Indexes {
+		if _, ok := previousIndexes[index.Name]; ok {
+			statements = append(statements, t.dropIndexStatement(index))
+		}
+	}
+
+	return statements
+}
+
+func (t *sqlTable) alterColumnStatements(old, column sqlColumn) []string {
+	table := t.quote(t.Name)
+	name := t.quote(column.Name)
+
+	switch t.Dialect {
+	case _dialectMySQL:
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", table, t.columnDefinition(column))}
+	case _dialectSQLite:
+		return []string{fmt.Sprintf("-- sqlite can't alter column %s of %s, recreate the table to apply: %s", name, table, t.columnDefinition(column))}
+	}
+
+	var statements []string
+	if old.Type != column.Type {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s;", table, name, column.Type))
+	}
+
+	if old.NotNull != column.NotNull {
+		action := "DROP NOT NULL"
+		if column.NotNull {
+			action = "SET NOT NULL"
+		}
+
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", table, name, action))
+	}
+
+	if old.Default != column.Default {
+		action := "DROP DEFAULT"
+		if len(column.Default) != 0 {
+			action = "SET DEFAULT " + column.Default
+		}
+
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", table, name, action))
+	}
+
+	if old.Unique != column.Unique {
+		constraint := t.quote(t.Name + "_" + column.Name + "_key")
+		if column.Unique {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", table, constraint, name))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, constraint))
+		}
+	}
+
+	return statements
+}
+
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func loadSQLSnapshot(file string) (*sqlTable, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read snapshot, err: %w", err)
+	}
+
+	table := &sqlTable{}
+	if err := json.Unmarshal(buf, table); err != nil {
+		return nil, fmt.Errorf("parse snapshot, err: %w", err)
+	}
+
+	return table, nil
+}
+
+func saveSQLSnapshot(file string, table *sqlTable) error {
+	buf, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot, err: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("make snapshot directory, err: %w", err)
+	}
+
+	helper.recordFile(file)
+
+	if err := os.WriteFile(file, append(buf, '\n'), 0o644); err != nil {
+		return fmt.Errorf("save snapshot, err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package modelgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDDL(t *testing.T) {
+	const (
+		before = `package domain
+
+//go:generate modelgen -type=Member -format=sql -dialect=%[1]s -snapshot=../schema/member.json -destination=../schema/member.sql
+
+type Member struct {
+	ID   int64
+	Name string ` + "`gorm:\"size:64;index\"`" + `
+	Age  int32
+	Note string ` + "`gorm:\"comment:it's note\"`" + `
+}
+`
+		// the note is dropped, the email is added, the age is retyped and the index of the name becomes unique
+		after = `package domain
+
+//go:generate modelgen -type=Member -format=sql -dialect=%[1]s -snapshot=../schema/member.json -destination=../schema/member.sql
+
+type Member struct {
+	ID    int64
+	Name  string ` + "`gorm:\"size:64;uniqueIndex:idx_members_name\"`" + `
+	Age   int64  ` + "`gorm:\"not null;default:0\"`" + `
+	Email string ` + "`gorm:\"size:128;index\"`" + `
+}
+`
+	)
+
+	testCases := []struct {
+		desc    string
+		dialect string
+		create  string
+		alter   string
+	}{
+		{
+			desc:    "postgres",
+			dialect: _dialectPostgres,
+			create: `CREATE TABLE IF NOT EXISTS "members" (
+	"id" bigserial NOT NULL,
+	"name" varchar(64),
+	"age" integer,
+	"note" text,
+	PRIMARY KEY ("id")
+);
+
+COMMENT ON COLUMN "members"."note" IS 'it''s note';
+
+CREATE INDEX IF NOT EXISTS "idx_members_name" ON "members" ("name");`,
+			alter: `ALTER TABLE "members" ALTER COLUMN "age" TYPE bigint;
+
+ALTER TABLE "members" ALTER COLUMN "age" SET NOT NULL;
+
+ALTER TABLE "members" ALTER COLUMN "age" SET DEFAULT 0;
+
+ALTER TABLE "members" ADD COLUMN "email" varchar(128);
+
+ALTER TABLE "members" DROP COLUMN "note";
+
+DROP INDEX IF EXISTS "idx_members_name";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_members_name" ON "members" ("name");
+
+CREATE INDEX IF NOT EXISTS "idx_members_email" ON "members" ("email");`,
+		},
+		{
+			desc:    "mysql",
+			dialect: _dialectMySQL,
+			create: "CREATE TABLE IF NOT EXISTS `members` (\n" +
+				"\t`id` bigint AUTO_INCREMENT NOT NULL,\n" +
+				"\t`name` varchar(64),\n" +
+				"\t`age` int,\n" +
+				"\t`note` longtext COMMENT 'it''s note',\n" +
+				"\tPRIMARY KEY (`id`)\n" +
+				");\n\n" +
+				"CREATE INDEX `idx_members_name` ON `members` (`name`);",
+			alter: "ALTER TABLE `members` MODIFY COLUMN `age` bigint NOT NULL DEFAULT 0;\n\n" +
+				"ALTER TABLE `members` ADD COLUMN `email` varchar(128);\n\n" +
+				"ALTER TABLE `members` DROP COLUMN `note`;\n\n" +
+				"DROP INDEX `idx_members_name` ON `members`;\n\n" +
+				"CREATE UNIQUE INDEX `idx_members_name` ON `members` (`name`);\n\n" +
+				"CREATE INDEX `idx_members_email` ON `members` (`email`);",
+		},
+		{
+			desc:    "sqlite recreates the table to alter the column",
+			dialect: _dialectSQLite,
+			create: `CREATE TABLE IF NOT EXISTS "members" (
+	"id" integer PRIMARY KEY AUTOINCREMENT NOT NULL,
+	"name" text,
+	"age" integer,
+	"note" text
+);
+
+CREATE INDEX IF NOT EXISTS "idx_members_name" ON "members" ("name");`,
+			alter: `-- sqlite can't alter column "age" of "members", recreate the table to apply: "age" integer NOT NULL DEFAULT 0
+
+ALTER TABLE "members" ADD COLUMN "email" text;
+
+ALTER TABLE "members" DROP COLUMN "note";
+
+DROP INDEX IF EXISTS "idx_members_name";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_members_name" ON "members" ("name");
+
+CREATE INDEX IF NOT EXISTS "idx_members_email" ON "members" ("email");`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir, bin := generateModule(t, map[string]string{"domain/member.go": fmt.Sprintf(before, tc.dialect)})
+
+			destination := filepath.Join(dir, "schema", "member.sql")
+			assertDDL(t, destination, tc.create)
+
+			if _, err := os.Stat(filepath.Join(dir, "schema", "member.json")); err != nil {
+				t.Fatalf("snapshot isn't saved, err: %+v", err)
+			}
+
+			writeModuleFile(t, filepath.Join(dir, "domain", "member.go"), fmt.Sprintf(after, tc.dialect))
+			runGo(t, dir, bin, "generate", "./...")
+			assertDDL(t, destination, tc.alter)
+
+			// the unchanged struct keeps the statements of the last change
+			runGo(t, dir, bin, "generate", "./...")
+			assertDDL(t, destination, tc.alter)
+		})
+	}
+}
+
+func assertDDL(t *testing.T, file, statements string) {
+	t.Helper()
+
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read ddl, err: %+v", err)
+	}
+
+	if expected := "-- Code generated by modelgen. DO NOT EDIT.\n\n" + statements + "\n"; string(buf) != expected {
+		t.Fatalf("ddl mismatch:\n%s\nexpected:\n%s", buf, expected)
+	}
+}
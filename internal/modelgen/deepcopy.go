@@ -0,0 +1,213 @@
+package modelgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const _methodDeepCopy = "DeepCopy"
+
+// generateDeepCopy generates the DeepCopy methods of the models.
+func generateDeepCopy(ms *modelSet) ([]merge.Scope, error) {
+	var result []merge.Scope
+	for _, m := range ms.Models {
+		if !ms.ShouldGenerate(m, _methodDeepCopy) {
+			continue
+		}
+
+		text, err := genDeepCopyString(ms, m)
+		if err != nil {
+			return nil, fmt.Errorf("deep copy %s, err: %w", m.Name, err)
+		}
+
+		result = append(result, merge.Scope{
+			Key:  m.Name + "." + _methodDeepCopy,
+			Text: text,
+		})
+	}
+
+	return result, nil
+}
+
+func genDeepCopyString(ms *modelSet, m *model) (string, error) {
+	receiver := m.Receiver()
+	buf := &strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s returns a deep copy of the %s.\n", _methodDeepCopy, m.Name))
+	buf.WriteString(fmt.Sprintf("func (%s *%s) %s() *%s {\n", receiver, m.Type(), _methodDeepCopy, m.Type()))
+	buf.WriteString(fmt.Sprintf("\tif %s == nil {\n\t\treturn nil\n\t}\n\n", receiver))
+	buf.WriteString(fmt.Sprintf("\tresult := &%s{}\n", m.Type()))
+	buf.WriteString(fmt.Sprintf("\t*result = *%s\n", receiver))
+
+	dc := deepCopier{models: ms, buf: buf, inlining: map[string]bool{}}
+	for _, f := range m.Fields {
+		if err := dc.deepen("result."+f.FieldName(), parseTypeExpr(f.Type), "", 1, 0); err != nil {
+			return "", err
+		}
+	}
+
+	buf.WriteString("\n\treturn result\n}\n")
+
+	return buf.String(), nil
+}
+
+// deepCopier writes the statements which replace the shared memory of a shallow copied value
+// with its own copy.
+type deepCopier struct {
+	models *modelSet
+	buf    *strings.Builder
+	// inlining is the source structs whose fields are being copied one by one, so the recursive
+	// source structs are reported instead of inlined endlessly.
+	inlining map[string]bool
+}
+
+// needDeepen reports whether the value of the type shares memory after a shallow copy.
+func (dc deepCopier) needDeepen(t *typeExpr) bool {
+	switch t.Kind {
+	case typePointer, typeSlice, typeMap:
+		return true
+	case typeArray:
+		return dc.needDeepen(t.Elem)
+	case typeIdent:
+		if _, ok := dc.models.MethodResult(t.Name, _methodDeepCopy, *_deepcopy); ok {
+			return true
+		}
+
+		if underlying, ok := dc.models.Underlying(t.Name); ok {
+			return dc.needDeepen(underlying)
+		}
+
+		if source, ok := dc.models.Source(t.Name); ok {
+			for _, f := range source.Instantiate(t) {
+				if dc.needDeepen(parseTypeExpr(f.Type)) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// deepen writes the statements deep copying the variable v of type t, declared is the type name
+// used to make the slices and maps when t is the underlying type of a named type.
+//
+// The structs kept in the source package are copied field by field, it returns an error when
+// their fields sharing memory can't be copied from the destination.
+func (dc deepCopier) deepen(v string, t *typeExpr, declared string, indent, depth int) error {
+	if !dc.needDeepen(t) {
+		return nil
+	}
+
+	var (
+		tab       = strings.Repeat("\t", indent)
+		typeText  = t.Raw
+		suffix    = depthSuffix(depth)
+		writeLine = func(format string, a ...any) {
+			dc.buf.WriteString(tab + fmt.Sprintf(format, a...) + "\n")
+		}
+	)
+
+	if len(declared) != 0 {
+		typeText = declared
+	}
+
+	switch t.Kind {
+	case typePointer:
+		writeLine("if %s != nil {", v)
+		if result, ok := dc.models.MethodResult(t.Elem.Name, _methodDeepCopy, *_deepcopy); ok && t.Elem.Kind == typeIdent {
+			if strings.HasPrefix(result, "*") {
+				writeLine("\t%s = %s.%s()", v, v, _methodDeepCopy)
+			} else {
+				writeLine("\tcloned%s := %s.%s()", suffix, v, _methodDeepCopy)
+				writeLine("\t%s = &cloned%s", v, suffix)
+			}
+		} else {
+			writeLine("\tcloned%s := *%s", suffix, v)
+			if err := dc.deepen("cloned"+suffix, t.Elem, "", indent+1, depth+1); err != nil {
+				return err
+			}
+			writeLine("\t%s = &cloned%s", v, suffix)
+		}
+		writeLine("}")
+	case typeSlice:
+		writeLine("if %s != nil {", v)
+		writeLine("\tcloned%s := make(%s, len(%s))", suffix, typeText, v)
+		writeLine("\tcopy(cloned%s, %s)", suffix, v)
+		if dc.needDeepen(t.Elem) {
+			writeLine("\tfor index%s := range cloned%s {", suffix, suffix)
+			if err := dc.deepen(fmt.Sprintf("cloned%s[index%s]", suffix, suffix), t.Elem, "", indent+2, depth+1); err != nil {
+				return err
+			}
+			writeLine("\t}")
+		}
+		writeLine("\t%s = cloned%s", v, suffix)
+		writeLine("}")
+	case typeArray:
+		writeLine("for index%s := range %s {", suffix, v)
+		if err := dc.deepen(fmt.Sprintf("%s[index%s]", v, suffix), t.Elem, "", indent+1, depth+1); err != nil {
+			return err
+		}
+		writeLine("}")
+	case typeMap:
+		writeLine("if %s != nil {", v)
+		writeLine("\tcloned%s := make(%s, len(%s))", suffix, typeText, v)
+		writeLine("\tfor key%s, elem%s := range %s {", suffix, suffix, v)
+		if err := dc.deepen("elem"+suffix, t.Elem, "", indent+2, depth+1); err != nil {
+			return err
+		}
+		writeLine("\t\tcloned%s[key%s] = elem%s", suffix, suffix, suffix)
+		writeLine("\t}")
+		writeLine("\t%s = cloned%s", v, suffix)
+		writeLine("}")
+	case typeIdent:
+		if result, ok := dc.models.MethodResult(t.Name, _methodDeepCopy, *_deepcopy); ok {
+			if strings.HasPrefix(result, "*") {
+				writeLine("%s = *%s.%s()", v, v, _methodDeepCopy)
+			} else {
+				writeLine("%s = %s.%s()", v, v, _methodDeepCopy)
+			}
+
+			return nil
+		}
+
+		if underlying, ok := dc.models.Underlying(t.Name); ok {
+			return dc.deepen(v, underlying, t.Raw, indent, depth)
+		}
+
+		if source, ok := dc.models.Source(t.Name); ok {
+			return dc.deepenSource(v, t, source, indent, depth)
+		}
+	}
+
+	return nil
+}
+
+// deepenSource writes the statements deep copying the fields of the struct kept in the source
+// package, the unexported fields and the recursive structs require -relative to be copied.
+func (dc deepCopier) deepenSource(v string, t *typeExpr, source *model, indent, depth int) error {
+	if dc.inlining[source.Name] {
+		return fmt.Errorf("recursive struct %s of the source package can't be deep copied field by field, declare it into the destination by -relative", source.Name)
+	}
+
+	dc.inlining[source.Name] = true
+	defer delete(dc.inlining, source.Name)
+
+	for _, f := range source.Instantiate(t) {
+		ft := parseTypeExpr(f.Type)
+		if !dc.needDeepen(ft) {
+			continue
+		}
+
+		if !f.IsExported() {
+			return fmt.Errorf("unexported field %s of %s shares memory after copying, declare it into the destination by -relative", f.FieldName(), source.Name)
+		}
+
+		if err := dc.deepen(v+"."+f.FieldName(), ft, "", indent, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package modelgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDeepCopy(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+import "time"
+
+//go:generate modelgen -type=Order -relative -deepcopy -destination=../entity/order.go -package=entity
+
+type Order struct {
+	ID      int64
+	Tags    []string
+	Matrix  [][]int
+	Labels  map[string][]string
+	Owner   *Item
+	Items   []*Item
+	ByID    map[int64]Item
+	Fixed   [2]*Item
+	Note    *string
+	Raw     []byte
+	Extra   any
+	Created time.Time
+}
+
+type Item struct {
+	Name   string
+	Prices []float64
+}
+`,
+	}, map[string]string{
+		"entity/order_test.go": `package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	note := "n"
+	src := &Order{
+		ID:     1,
+		Tags:   []string{"a"},
+		Matrix: [][]int{{1, 2}},
+		Labels: map[string][]string{"k": {"v"}},
+		Owner:  &Item{Name: "o", Prices: []float64{1}},
+		Items:  []*Item{{Name: "i", Prices: []float64{2}}, nil},
+		ByID:   map[int64]Item{1: {Name: "b", Prices: []float64{3}}},
+		Fixed:  [2]*Item{{Name: "f"}},
+		Note:   &note,
+		Raw:    []byte("raw"),
+	}
+
+	dst := src.DeepCopy()
+	if !reflect.DeepEqual(src, dst) {
+		t.Fatalf("copy mismatch: %+v", dst)
+	}
+
+	dst.Tags[0] = "x"
+	dst.Matrix[0][0] = 9
+	dst.Labels["k"][0] = "x"
+	dst.Owner.Name = "x"
+	dst.Owner.Prices[0] = 9
+	dst.Items[0].Prices[0] = 9
+	dst.ByID[1].Prices[0] = 9
+	dst.Fixed[0].Name = "x"
+	*dst.Note = "x"
+	dst.Raw[0] = 'x'
+
+	if src.Tags[0] != "a" || src.Matrix[0][0] != 1 || src.Labels["k"][0] != "v" || src.Owner.Name != "o" || src.Owner.Prices[0] != 1 ||
+		src.Items[0].Prices[0] != 2 || src.ByID[1].Prices[0] != 3 || src.Fixed[0].Name != "f" || note != "n" || string(src.Raw) != "raw" {
+		t.Fatalf("source is aliased by the copy: %+v", src)
+	}
+
+	if dst.Items[1] != nil || dst.Fixed[1] != nil {
+		t.Fatalf("nil elements mismatch: %+v", dst)
+	}
+
+	var empty *Order
+	if empty.DeepCopy() != nil {
+		t.Fatal("copy of nil should be nil")
+	}
+
+	if copied := (&Order{}).DeepCopy(); copied.Tags != nil || copied.Labels != nil {
+		t.Fatalf("nil fields mismatch: %+v", copied)
+	}
+}
+`,
+	})
+}
+
+func TestGenerateDeepCopyOfSourceStructs(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+//go:generate modelgen -type=Order -deepcopy -destination=../entity/order.go -package=entity
+
+type Order struct {
+	ID    int64
+	Ext   *Ext
+	Value Ext
+	Vals  []Ext
+	ByKey map[string]*Ext
+	Box   Box[Ext]
+}
+
+type Ext struct {
+	Base
+	Tags []string
+	Note string
+}
+
+type Base struct {
+	Labels map[string]string
+}
+
+type Box[T any] struct {
+	Items []T
+}
+`,
+	}, map[string]string{
+		"entity/order_test.go": `package entity
+
+import (
+	"reflect"
+	"testing"
+
+	"example.com/app/domain"
+)
+
+func newExt(tag string) domain.Ext {
+	return domain.Ext{Base: domain.Base{Labels: map[string]string{"k": tag}}, Tags: []string{tag}, Note: tag}
+}
+
+func TestDeepCopy(t *testing.T) {
+	ext := newExt("p")
+	src := &Order{
+		ID:    1,
+		Ext:   &ext,
+		Value: newExt("v"),
+		Vals:  []domain.Ext{newExt("s")},
+		ByKey: map[string]*domain.Ext{"m": &domain.Ext{Tags: []string{"m"}}, "nil": nil},
+		Box:   domain.Box[domain.Ext]{Items: []domain.Ext{newExt("b")}},
+	}
+
+	dst := src.DeepCopy()
+	if !reflect.DeepEqual(src, dst) {
+		t.Fatalf("copy mismatch: %+v", dst)
+	}
+
+	dst.Ext.Tags[0] = "x"
+	dst.Ext.Labels["k"] = "x"
+	dst.Value.Tags[0] = "x"
+	dst.Value.Labels["k"] = "x"
+	dst.Vals[0].Tags[0] = "x"
+	dst.Vals[0].Labels["k"] = "x"
+	dst.ByKey["m"].Tags[0] = "x"
+	dst.Box.Items[0].Tags[0] = "x"
+	dst.Box.Items[0].Labels["k"] = "x"
+
+	if ext.Tags[0] != "p" || ext.Labels["k"] != "p" || src.Value.Tags[0] != "v" || src.Value.Labels["k"] != "v" ||
+		src.Vals[0].Tags[0] != "s" || src.Vals[0].Labels["k"] != "s" || src.ByKey["m"].Tags[0] != "m" ||
+		src.Box.Items[0].Tags[0] != "b" || src.Box.Items[0].Labels["k"] != "b" {
+		t.Fatalf("source is aliased by the copy: %+v", src)
+	}
+
+	if dst.Ext == src.Ext || dst.ByKey["m"] == src.ByKey["m"] || dst.ByKey["nil"] != nil {
+		t.Fatalf("pointers mismatch: %+v", dst)
+	}
+}
+`,
+	})
+}
+
+func TestDeepCopySourceStructError(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		sources map[string]string
+		err     string
+	}{
+		{
+			desc:    "unexported field",
+			sources: map[string]string{"domain.Ext": "struct {\ntags []string\n}"},
+			err:     "unexported field tags of domain.Ext",
+		},
+		{
+			desc:    "recursive struct",
+			sources: map[string]string{"domain.Ext": "struct {\nNext *domain.Ext\n}"},
+			err:     "recursive struct domain.Ext",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ms := &modelSet{sources: map[string]*model{}}
+			for name, text := range tc.sources {
+				fields, err := parseStructFieldsFromText(text)
+				if err != nil {
+					t.Fatalf("parse fields, err: %+v", err)
+				}
+
+				ms.sources[name] = &model{Name: name, Fields: fields}
+			}
+
+			target := &model{Name: "Order", Fields: []structField{{Name: "Ext", Type: "*domain.Ext"}}}
+			if _, err := genDeepCopyString(ms, target); err == nil || !strings.Contains(err.Error(), tc.err) || !strings.Contains(err.Error(), "-relative") {
+				t.Fatalf("error mismatch: %+v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package modelgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_methodEqual     = "Equal"
+	_methodDiff      = "Diff"
+	_typeFieldChange = "FieldChange"
+)
+
+// _basicTypes is the predeclared types which can be compared by the == operator.
+var _basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// generateEqualAndDiff generates the Equal and Diff methods of the models, and the FieldChange type
+// reported by Diff.
+func generateEqualAndDiff(ms *modelSet) []merge.Scope {
+	var result []merge.Scope
+	if ms.ShouldDeclare(_typeFieldChange) {
+		result = append(result, merge.Scope{
+			Key:  _typeFieldChange,
+			Text: genFieldChangeString(),
+		})
+	}
+
+	ignored := map[string]bool{}
+	for _, name := range strings.Split(*_ignore, ",") {
+		if name = strings.TrimSpace(name); len(name) != 0 {
+			ignored[name] = true
+		}
+	}
+
+	for _, m := range ms.Models {
+		var fields []structField
+		for _, f := range m.Fields {
+			name := f.FieldName()
+			if !ignored[name] && !ignored[m.Name+"."+name] {
+				fields = append(fields, f)
+			}
+		}
+
+		c := comparer{models: ms, buf: &strings.Builder{}}
+		if ms.ShouldGenerate(m, _methodEqual) {
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodEqual,
+				Text: c.genEqualString(m, fields),
+			})
+		}
+
+		c.buf.Reset()
+		if ms.ShouldGenerate(m, _methodDiff) {
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodDiff,
+				Text: c.genDiffString(m, fields),
+			})
+		}
+	}
+
+	return result
+}
+
+func genFieldChangeString() string {
+	return fmt.Sprintf(`// %s is a field changed between two values, it's reported by the %s methods.
+type %s struct {
+	// Path is the path of the changed field, e.g. 'Items[0].Name' or 'Labels[key]'.
+	Path string
+	Old  any
+	New  any
+}
+`, _typeFieldChange, _methodDiff, _typeFieldChange)
+}
+
+// compareKind is the way to compare two values of a type.
+type compareKind int
+
+const (
+	compareOperator compareKind = iota
+	compareEqualMethod
+	compareModel
+	compareReflect
+	comparePointer
+	compareSlice
+	compareArray
+	compareMap
+)
+
+// comparer writes the statements comparing two values field by field.
+type comparer struct {
+	models *modelSet
+	buf    *strings.Builder
+}
+
+// kind returns the compare kind of the type and the type used to compare, which is the underlying
+// type of the named types.
+func (c comparer) kind(t *typeExpr) (compareKind, *typeExpr) {
+	switch t.Kind {
+	case typePointer:
+		return comparePointer, t
+	case typeSlice:
+		return compareSlice, t
+	case typeMap:
+		return compareMap, t
+	case typeArray:
+		return compareArray, t
+	case typeIdent:
+		switch {
+		case _basicTypes[t.Name]:
+			return compareOperator, t
+		case t.Name == "time.Time":
+			return compareEqualMethod, t
+		}
+
+		if _, ok := c.models.Lookup(t.Name); ok {
+			return compareModel, t
+		}
+
+		if underlying, ok := c.models.Underlying(t.Name); ok {
+			return c.kind(underlying)
+		}
+	}
+
+	return compareReflect, t
+}
+
+// isComparable reports whether the values of the type can be compared by the == operator.
+func (c comparer) isComparable(t *typeExpr) bool {
+	k, underlying := c.kind(t)
+	switch k {
+	case compareOperator:
+		return true
+	case compareArray:
+		return c.isComparable(underlying.Elem)
+	}
+
+	return false
+}
+
+func (c comparer) writeLine(indent int, format string, a ...any) {
+	c.buf.WriteString(strings.Repeat("\t", indent) + fmt.Sprintf(format, a...) + "\n")
+}
+
+func (c comparer) genEqualString(m *model, fields []structField) string {
+	receiver := m.Receiver()
+	c.writeLine(0, "// %s reports whether the fields of the %s are equal to the other's.", _methodEqual, m.Name)
+	c.writeLine(0, "func (%s *%s) %s(other *%s) bool {", receiver, m.Name, _methodEqual, m.Name)
+	c.writeLine(1, "if %s == nil || other == nil {", receiver)
+	c.writeLine(2, "return %s == other", receiver)
+	c.writeLine(1, "}")
+	c.writeLine(0, "")
+	for _, f := range fields {
+		name := f.FieldName()
+		c.equal(receiver+"."+name, "other."+name, parseTypeExpr(f.Type), 1, 0)
+	}
+	c.writeLine(0, "")
+	c.writeLine(1, "return true")
+	c.writeLine(0, "}")
+
+	return c.buf.String()
+}
+
+// equal writes the statements returning false when a is not equal to b.
+func (c comparer) equal(a, b string, t *typeExpr, indent, depth int) {
+	suffix := depthSuffix(depth)
+	k, t := c.kind(t)
+	switch k {
+	case compareOperator:
+		c.writeLine(indent, "if %s != %s {", a, b)
+	case compareEqualMethod:
+		c.writeLine(indent, "if !%s.Equal(%s) {", a, b)
+	case compareModel:
+		c.writeLine(indent, "if !%s.%s(&%s) {", a, _methodEqual, b)
+	case compareReflect:
+		c.models.addImport("reflect")
+		c.writeLine(indent, "if !reflect.DeepEqual(%s, %s) {", a, b)
+	case comparePointer:
+		if k, _ := c.kind(t.Elem); k == compareModel {
+			c.writeLine(indent, "if !%s.%s(%s) {", a, _methodEqual, b)
+			break
+		}
+
+		c.writeLine(indent, "if (%s == nil) != (%s == nil) {", a, b)
+		c.writeLine(indent+1, "return false")
+		c.writeLine(indent, "}")
+		c.writeLine(indent, "if %s != nil {", a)
+		c.writeLine(indent+1, "a%s, b%s := *%s, *%s", suffix, suffix, a, b)
+		c.equal("a"+suffix, "b"+suffix, t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		return
+	case compareArray:
+		if c.isComparable(t) {
+			c.writeLine(indent, "if %s != %s {", a, b)
+			break
+		}
+
+		c.writeLine(indent, "for index%s := range %s {", suffix, a)
+		c.equal(fmt.Sprintf("%s[index%s]", a, suffix), fmt.Sprintf("%s[index%s]", b, suffix), t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		return
+	case compareSlice:
+		c.writeLine(indent, "if len(%s) != len(%s) {", a, b)
+		c.writeLine(indent+1, "return false")
+		c.writeLine(indent, "}")
+		c.writeLine(indent, "for index%s := range %s {", suffix, a)
+		c.equal(fmt.Sprintf("%s[index%s]", a, suffix), fmt.Sprintf("%s[index%s]", b, suffix), t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		return
+	case compareMap:
+		c.writeLine(indent, "if len(%s) != len(%s) {", a, b)
+		c.writeLine(indent+1, "return false")
+		c.writeLine(indent, "}")
+		c.writeLine(indent, "for key%s, elemA%s := range %s {", suffix, suffix, a)
+		c.writeLine(indent+1, "elemB%s, ok := %s[key%s]", suffix, b, suffix)
+		c.writeLine(indent+1, "if !ok {")
+		c.writeLine(indent+2, "return false")
+		c.writeLine(indent+1, "}")
+		c.equal("elemA"+suffix, "elemB"+suffix, t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		return
+	}
+
+	c.writeLine(indent+1, "return false")
+	c.writeLine(indent, "}")
+}
+
+func (c comparer) genDiffString(m *model, fields []structField) string {
+	receiver := m.Receiver()
+	c.writeLine(0, "// %s returns the fields changed from the %s to the other.", _methodDiff, m.Name)
+	c.writeLine(0, "func (%s *%s) %s(other *%s) []%s {", receiver, m.Name, _methodDiff, m.Name, _typeFieldChange)
+	c.writeLine(1, "if %s == nil || other == nil {", receiver)
+	c.writeLine(2, "if %s != other {", receiver)
+	c.writeLine(3, "return []%s{{Old: %s, New: other}}", _typeFieldChange, receiver)
+	c.writeLine(2, "}")
+	c.writeLine(0, "")
+	c.writeLine(2, "return nil")
+	c.writeLine(1, "}")
+	c.writeLine(0, "")
+	c.writeLine(1, "var changes []%s", _typeFieldChange)
+	for _, f := range fields {
+		name := f.FieldName()
+		c.diff(receiver+"."+name, "other."+name, strconv.Quote(name), parseTypeExpr(f.Type), 1, 0)
+	}
+	c.writeLine(0, "")
+	c.writeLine(1, "return changes")
+	c.writeLine(0, "}")
+
+	return c.buf.String()
+}
+
+// diff writes the statements appending the changes from a to b, path is the expression of the
+// field path.
+func (c comparer) diff(a, b, path string, t *typeExpr, indent, depth int) {
+	var (
+		suffix = depthSuffix(depth)
+		change = func(indent int, path, old, new string) {
+			c.writeLine(indent, "changes = append(changes, %s{Path: %s, Old: %s, New: %s})", _typeFieldChange, path, old, new)
+		}
+	)
+
+	k, t := c.kind(t)
+	switch k {
+	case compareOperator:
+		c.writeLine(indent, "if %s != %s {", a, b)
+	case compareEqualMethod:
+		c.writeLine(indent, "if !%s.Equal(%s) {", a, b)
+	case compareReflect:
+		c.models.addImport("reflect")
+		c.writeLine(indent, "if !reflect.DeepEqual(%s, %s) {", a, b)
+	case compareModel:
+		c.writeLine(indent, "for _, change := range %s.%s(&%s) {", a, _methodDiff, b)
+		c.writeLine(indent+1, "change.Path = %s + change.Path", joinPathExpr(path, "."))
+		c.writeLine(indent+1, "changes = append(changes, change)")
+		c.writeLine(indent, "}")
+		return
+	case comparePointer:
+		c.writeLine(indent, "switch {")
+		c.writeLine(indent, "case %s == nil && %s == nil:", a, b)
+		c.writeLine(indent, "case %s == nil || %s == nil:", a, b)
+		change(indent+1, path, a, b)
+		c.writeLine(indent, "default:")
+		if k, _ := c.kind(t.Elem); k == compareModel {
+			c.writeLine(indent+1, "for _, change := range %s.%s(%s) {", a, _methodDiff, b)
+			c.writeLine(indent+2, "change.Path = %s + change.Path", joinPathExpr(path, "."))
+			c.writeLine(indent+2, "changes = append(changes, change)")
+			c.writeLine(indent+1, "}")
+		} else {
+			c.writeLine(indent+1, "a%s, b%s := *%s, *%s", suffix, suffix, a, b)
+			c.diff("a"+suffix, "b"+suffix, path, t.Elem, indent+1, depth+1)
+		}
+		c.writeLine(indent, "}")
+		return
+	case compareArray:
+		if c.isComparable(t) {
+			c.writeLine(indent, "if %s != %s {", a, b)
+			break
+		}
+
+		c.models.addImport("fmt")
+		c.writeLine(indent, "for index%s := range %s {", suffix, a)
+		c.writeLine(indent+1, "path%s := %s", suffix, sprintfPathExpr(path, "[%d]", "index"+suffix))
+		c.diff(fmt.Sprintf("%s[index%s]", a, suffix), fmt.Sprintf("%s[index%s]", b, suffix), "path"+suffix, t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		return
+	case compareSlice:
+		c.models.addImport("fmt")
+		c.writeLine(indent, "for index%s := 0; index%s < len(%s) || index%s < len(%s); index%s++ {", suffix, suffix, a, suffix, b, suffix)
+		c.writeLine(indent+1, "path%s := %s", suffix, sprintfPathExpr(path, "[%d]", "index"+suffix))
+		c.writeLine(indent+1, "switch {")
+		c.writeLine(indent+1, "case index%s >= len(%s):", suffix, a)
+		change(indent+2, "path"+suffix, "nil", fmt.Sprintf("%s[index%s]", b, suffix))
+		c.writeLine(indent+1, "case index%s >= len(%s):", suffix, b)
+		change(indent+2, "path"+suffix, fmt.Sprintf("%s[index%s]", a, suffix), "nil")
+		c.writeLine(indent+1, "default:")
+		c.diff(fmt.Sprintf("%s[index%s]", a, suffix), fmt.Sprintf("%s[index%s]", b, suffix), "path"+suffix, t.Elem, indent+2, depth+1)
+		c.writeLine(indent+1, "}")
+		c.writeLine(indent, "}")
+		return
+	case compareMap:
+		c.models.addImport("fmt")
+		c.writeLine(indent, "for key%s, elemA%s := range %s {", suffix, suffix, a)
+		c.writeLine(indent+1, "path%s := %s", suffix, sprintfPathExpr(path, "[%v]", "key"+suffix))
+		c.writeLine(indent+1, "elemB%s, ok := %s[key%s]", suffix, b, suffix)
+		c.writeLine(indent+1, "if !ok {")
+		change(indent+2, "path"+suffix, "elemA"+suffix, "nil")
+		c.writeLine(indent+2, "continue")
+		c.writeLine(indent+1, "}")
+		c.diff("elemA"+suffix, "elemB"+suffix, "path"+suffix, t.Elem, indent+1, depth+1)
+		c.writeLine(indent, "}")
+		c.writeLine(indent, "for key%s, elemB%s := range %s {", suffix, suffix, b)
+		c.writeLine(indent+1, "if _, ok := %s[key%s]; !ok {", a, suffix)
+		change(indent+2, sprintfPathExpr(path, "[%v]", "key"+suffix), "nil", "elemB"+suffix)
+		c.writeLine(indent+1, "}")
+		c.writeLine(indent, "}")
+		return
+	}
+
+	change(indent+1, path, a, b)
+	c.writeLine(indent, "}")
+}
+
+func depthSuffix(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(depth)
+}
+
+// joinPathExpr returns the expression of the path followed by the text.
+func joinPathExpr(path, text string) string {
+	if s, err := strconv.Unquote(path); err == nil {
+		return strconv.Quote(s + text)
+	}
+
+	return path + " + " + strconv.Quote(text)
+}
+
+// sprintfPathExpr returns the fmt.Sprintf expression of the path followed by the format of the argument.
+func sprintfPathExpr(path, format, arg string) string {
+	if s, err := strconv.Unquote(path); err == nil {
+		return fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(s+format), arg)
+	}
+
+	return fmt.Sprintf("fmt.Sprintf(%s, %s, %s)", strconv.Quote("%s"+format), path, arg)
+}
@@ -0,0 +1,101 @@
+package modelgen
+
+import "testing"
+
+func TestPathExpr(t *testing.T) {
+	if s := joinPathExpr(`"Owner"`, "."); s != `"Owner."` {
+		t.Fatalf("join literal mismatch: %s", s)
+	}
+
+	if s := joinPathExpr("path1", "."); s != `path1 + "."` {
+		t.Fatalf("join variable mismatch: %s", s)
+	}
+
+	if s := sprintfPathExpr(`"Items"`, "[%d]", "index"); s != `fmt.Sprintf("Items[%d]", index)` {
+		t.Fatalf("sprintf literal mismatch: %s", s)
+	}
+
+	if s := sprintfPathExpr("path", "[%v]", "key1"); s != `fmt.Sprintf("%s[%v]", path, key1)` {
+		t.Fatalf("sprintf variable mismatch: %s", s)
+	}
+}
+
+func TestGenerateEqualAndDiff(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+import "time"
+
+//go:generate modelgen -type=Order -relative -diff -ignore=UpdatedAt -destination=../entity/order.go -package=entity
+
+type Order struct {
+	ID        int64
+	Tags      []string
+	Labels    map[string]int
+	Owner     *Item
+	Items     []Item
+	Created   time.Time
+	UpdatedAt time.Time
+}
+
+type Item struct {
+	Name string
+}
+`,
+	}, map[string]string{
+		"entity/order_test.go": `package entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newOrder() *Order {
+	return &Order{
+		ID:      1,
+		Tags:    []string{"a"},
+		Labels:  map[string]int{"k": 1},
+		Owner:   &Item{Name: "o"},
+		Items:   []Item{{Name: "i"}},
+		Created: time.Unix(1, 0),
+	}
+}
+
+func TestEqualAndDiff(t *testing.T) {
+	a, b := newOrder(), newOrder()
+	b.Created = b.Created.UTC()
+	b.UpdatedAt = time.Unix(2, 0)
+	if !a.Equal(b) || len(a.Diff(b)) != 0 {
+		t.Fatalf("equal orders mismatch: %+v", a.Diff(b))
+	}
+
+	b.Tags[0] = "b"
+	b.Labels["k"] = 2
+	b.Labels["n"] = 3
+	b.Owner = nil
+	b.Items[0].Name = "j"
+	if a.Equal(b) {
+		t.Fatal("different orders should not be equal")
+	}
+
+	expected := []FieldChange{
+		{Path: "Tags[0]", Old: "a", New: "b"},
+		{Path: "Labels[k]", Old: 1, New: 2},
+		{Path: "Labels[n]", Old: nil, New: 3},
+		{Path: "Owner", Old: a.Owner, New: (*Item)(nil)},
+		{Path: "Items[0].Name", Old: "i", New: "j"},
+	}
+
+	if changes := a.Diff(b); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("changes mismatch: %+v", changes)
+	}
+
+	var empty *Order
+	if !empty.Equal(nil) || empty.Equal(a) {
+		t.Fatal("nil orders mismatch")
+	}
+}
+`,
+	})
+}
@@ -0,0 +1,629 @@
+package modelgen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/goast/kind"
+	"github.com/yanun0323/goast/scope"
+)
+
+// structField is a single field declared in a struct scope.
+type structField struct {
+	Name     string
+	Type     string
+	Tag      string
+	Doc      []string
+	Comment  string
+	Embedded bool
+}
+
+// FieldName returns the name used to access the field, including the
+// type name of embedded fields.
+func (f structField) FieldName() string {
+	if !f.Embedded {
+		return f.Name
+	}
+
+	t := parseTypeExpr(f.Type)
+	for t.Kind == typePointer {
+		t = t.Elem
+	}
+
+	return t.BaseName()
+}
+
+// TagValue returns the value of the tag key and its options.
+func (f structField) TagValue(key string) (value string, options []string, ok bool) {
+	v, ok := reflect.StructTag(f.Tag).Lookup(key)
+	if !ok {
+		return "", nil, false
+	}
+
+	span := strings.Split(v, ",")
+	return span[0], span[1:], true
+}
+
+// IsExported reports whether the field is accessible from other packages.
+func (f structField) IsExported() bool {
+	name := f.FieldName()
+	return len(name) != 0 && helper.IsFirstUpperCase(name)
+}
+
+// parseStructFields extracts the fields declared in the struct scope.
+func parseStructFields(sc goast.Scope) ([]structField, error) {
+	head := sc.Node().IterNext(func(n *goast.Node) bool {
+		return n.Kind() != kind.Struct
+	})
+	if head == nil {
+		return nil, errors.New("struct keyword not found")
+	}
+
+	head = head.IterNext(func(n *goast.Node) bool {
+		return n.Kind() != kind.CurlyBracketLeft
+	})
+	if head == nil {
+		return nil, errors.New("struct body not found")
+	}
+
+	var (
+		fields []structField
+		doc    []string
+		line   []*goast.Node
+		depth  int
+	)
+
+	flush := func() {
+		defer func() { line = line[:0] }()
+
+		significant := make([]*goast.Node, 0, len(line))
+		for _, n := range line {
+			switch n.Kind() {
+			case kind.Space, kind.Tab:
+			default:
+				significant = append(significant, n)
+			}
+		}
+
+		if len(significant) == 0 {
+			doc = nil
+			return
+		}
+
+		if len(significant) == 1 && significant[0].Kind() == kind.Comment {
+			doc = append(doc, tidyComment(significant[0].Text()))
+			return
+		}
+
+		fields = append(fields, parseFieldLine(line, doc)...)
+		doc = nil
+	}
+
+	head.Next().IterNext(func(n *goast.Node) bool {
+		switch n.Kind() {
+		case kind.CurlyBracketLeft, kind.ParenthesisLeft, kind.SquareBracketLeft:
+			depth++
+		case kind.CurlyBracketRight, kind.ParenthesisRight, kind.SquareBracketRight:
+			if depth == 0 {
+				flush()
+				return false
+			}
+			depth--
+		case kind.NewLine:
+			if depth == 0 {
+				flush()
+				return true
+			}
+		}
+
+		line = append(line, n)
+		return true
+	})
+
+	return fields, nil
+}
+
+// typeParam is a type parameter declared by a generic struct.
+type typeParam struct {
+	Name       string
+	Constraint string
+}
+
+// parseTypeParams extracts the type parameters declared in the struct scope, e.g. 'T any' and
+// 'K comparable' of 'type Page[T any, K comparable] struct'.
+func parseTypeParams(sc goast.Scope) []typeParam {
+	var (
+		text  strings.Builder
+		depth int
+		found bool
+	)
+
+	sc.Node().IterNext(func(n *goast.Node) bool {
+		switch n.Kind() {
+		case kind.Struct:
+			return false
+		case kind.SquareBracketLeft:
+			depth++
+			if depth == 1 {
+				found = true
+				return true
+			}
+		case kind.SquareBracketRight:
+			depth--
+			if depth == 0 {
+				return false
+			}
+		}
+
+		if depth != 0 {
+			text.WriteString(n.Text())
+		}
+
+		return true
+	})
+
+	if !found {
+		return nil
+	}
+
+	var params []typeParam
+	for _, entry := range splitTopLevel(text.String(), ',') {
+		name, constraint, _ := strings.Cut(entry, " ")
+		params = append(params, typeParam{Name: name, Constraint: strings.TrimSpace(constraint)})
+	}
+
+	// the grouped type parameters like 'K, V any' share the constraint of the last one
+	for i := len(params) - 2; i >= 0; i-- {
+		if len(params[i].Constraint) == 0 {
+			params[i].Constraint = params[i+1].Constraint
+		}
+	}
+
+	return params
+}
+
+// typeParamNames returns the names of the type parameters declared in the struct scope.
+func typeParamNames(sc goast.Scope) map[string]bool {
+	names := map[string]bool{}
+	for _, p := range parseTypeParams(sc) {
+		names[p.Name] = true
+	}
+
+	return names
+}
+
+// parseFieldLine converts the nodes of a single field declaration line into fields.
+func parseFieldLine(line []*goast.Node, doc []string) []structField {
+	var (
+		names    []string
+		typeText strings.Builder
+		tag      string
+		comment  string
+		depth    int
+		i        int
+	)
+
+	for ; i < len(line); i++ {
+		n := line[i]
+		if n.Kind() == kind.Space || n.Kind() == kind.Tab || n.Kind() == kind.Comma {
+			continue
+		}
+
+		if n.Kind() != kind.ParamName {
+			break
+		}
+
+		names = append(names, n.Text())
+	}
+
+	for ; i < len(line); i++ {
+		n := line[i]
+		switch n.Kind() {
+		case kind.CurlyBracketLeft, kind.ParenthesisLeft, kind.SquareBracketLeft:
+			depth++
+		case kind.CurlyBracketRight, kind.ParenthesisRight, kind.SquareBracketRight:
+			depth--
+		}
+
+		if depth == 0 {
+			switch n.Kind() {
+			case kind.String:
+				tag = tidyTag(n.Text())
+				continue
+			case kind.Comment:
+				comment = tidyComment(n.Text())
+				continue
+			}
+		}
+
+		typeText.WriteString(n.Text())
+	}
+
+	typ := strings.TrimSpace(typeText.String())
+
+	// an embedded field followed by a tag is parsed as a param name
+	if len(names) == 1 && len(typ) == 0 {
+		typ, names = names[0], nil
+	}
+
+	if len(names) == 0 {
+		return []structField{{Type: typ, Tag: tag, Doc: doc, Comment: comment, Embedded: true}}
+	}
+
+	result := make([]structField, 0, len(names))
+	for _, name := range names {
+		result = append(result, structField{Name: name, Type: typ, Tag: tag, Doc: doc, Comment: comment})
+	}
+
+	return result
+}
+
+// parseStructFieldsFromText parses the fields of an anonymous struct type text like 'struct { A int }'.
+func parseStructFieldsFromText(text string) ([]structField, error) {
+	// the blank identifier isn't parsed as the type name, the fields would be parsed as embedded ones
+	scs, err := goast.ParseScope(0, []byte(fmt.Sprintf("type T %s\n", text)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scs) == 0 {
+		return nil, errors.New("struct scope not found")
+	}
+
+	return parseStructFields(scs[0])
+}
+
+func tidyTag(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+
+	return strings.Trim(s, "`")
+}
+
+func tidyComment(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "//"):
+		s = strings.TrimPrefix(s, "//")
+	case strings.HasPrefix(s, "/*"):
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "/*"), "*/")
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// typeExprKind is the kind of a parsed type expression.
+type typeExprKind int
+
+const (
+	typeIdent typeExprKind = iota
+	typePointer
+	typeSlice
+	typeArray
+	typeMap
+	typeChan
+	typeFunc
+	typeStruct
+	typeInterface
+)
+
+// typeExpr is a parsed type expression of a struct field.
+type typeExpr struct {
+	Kind typeExprKind
+	Raw  string
+
+	// Name is the identifier of typeIdent including its package qualifier, e.g. 'time.Time'.
+	Name string
+	// Args is the type arguments of an instantiated generic type.
+	Args []*typeExpr
+	// Len is the length of typeArray.
+	Len string
+	// Key is the key of typeMap.
+	Key *typeExpr
+	// Elem is the element of typePointer, typeSlice, typeArray, typeMap and typeChan.
+	Elem *typeExpr
+}
+
+// parseTypeExpr parses a type text like '[]*pkg.Foo' or 'map[string]Page[T]'.
+func parseTypeExpr(text string) *typeExpr {
+	text = strings.TrimSpace(text)
+	t := &typeExpr{Raw: text}
+
+	switch {
+	case strings.HasPrefix(text, "*"):
+		t.Kind = typePointer
+		t.Elem = parseTypeExpr(text[1:])
+	case strings.HasPrefix(text, "[]"):
+		t.Kind = typeSlice
+		t.Elem = parseTypeExpr(text[2:])
+	case strings.HasPrefix(text, "["):
+		end := matchBracket(text, 0)
+		t.Kind = typeArray
+		t.Len = strings.TrimSpace(text[1:end])
+		t.Elem = parseTypeExpr(text[end+1:])
+	case strings.HasPrefix(text, "map["):
+		end := matchBracket(text, 3)
+		t.Kind = typeMap
+		t.Key = parseTypeExpr(text[4:end])
+		t.Elem = parseTypeExpr(text[end+1:])
+	case strings.HasPrefix(text, "chan") || strings.HasPrefix(text, "<-chan"):
+		t.Kind = typeChan
+		t.Elem = parseTypeExpr(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(text, "<-"), "chan"), "<-"))
+	case strings.HasPrefix(text, "func"):
+		t.Kind = typeFunc
+	case strings.HasPrefix(text, "struct"):
+		t.Kind = typeStruct
+	case strings.HasPrefix(text, "interface"):
+		t.Kind = typeInterface
+	default:
+		t.Kind = typeIdent
+		t.Name = text
+		if i := strings.Index(text, "["); i > 0 {
+			t.Name = text[:i]
+			for _, arg := range splitTopLevel(text[i+1:matchBracket(text, i)], ',') {
+				t.Args = append(t.Args, parseTypeExpr(arg))
+			}
+		}
+	}
+
+	return t
+}
+
+// BaseName returns the identifier without package qualifier and type arguments.
+func (t *typeExpr) BaseName() string {
+	if t == nil || t.Kind != typeIdent {
+		return ""
+	}
+
+	name := t.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+// IsQualified reports whether the identifier comes from another package.
+func (t *typeExpr) IsQualified() bool {
+	return t != nil && t.Kind == typeIdent && strings.Contains(t.Name, ".")
+}
+
+// Qualify returns the type text with the exported local identifiers qualified by the package.
+func (t *typeExpr) Qualify(pkg string) string {
+	if t == nil {
+		return ""
+	}
+
+	switch t.Kind {
+	case typePointer:
+		return "*" + t.Elem.Qualify(pkg)
+	case typeSlice:
+		return "[]" + t.Elem.Qualify(pkg)
+	case typeArray:
+		return "[" + t.Len + "]" + t.Elem.Qualify(pkg)
+	case typeMap:
+		return "map[" + t.Key.Qualify(pkg) + "]" + t.Elem.Qualify(pkg)
+	case typeIdent:
+		name := t.Name
+		if !t.IsQualified() && helper.IsFirstUpperCase(name) {
+			name = pkg + "." + name
+		}
+
+		if len(t.Args) == 0 {
+			return name
+		}
+
+		args := make([]string, 0, len(t.Args))
+		for _, arg := range t.Args {
+			args = append(args, arg.Qualify(pkg))
+		}
+
+		return name + "[" + strings.Join(args, ", ") + "]"
+	}
+
+	return t.Raw
+}
+
+// Substitute returns the type text with the identifiers replaced by the types of the mapping, e.g.
+// '[]T' to '[]string' with the type parameter T instantiated by string.
+func (t *typeExpr) Substitute(mapping map[string]string) string {
+	if t == nil {
+		return ""
+	}
+
+	switch t.Kind {
+	case typePointer:
+		return "*" + t.Elem.Substitute(mapping)
+	case typeSlice:
+		return "[]" + t.Elem.Substitute(mapping)
+	case typeArray:
+		return "[" + t.Len + "]" + t.Elem.Substitute(mapping)
+	case typeMap:
+		return "map[" + t.Key.Substitute(mapping) + "]" + t.Elem.Substitute(mapping)
+	case typeIdent:
+		if replaced, ok := mapping[t.Name]; ok && len(t.Args) == 0 {
+			return replaced
+		}
+
+		if len(t.Args) == 0 {
+			return t.Name
+		}
+
+		args := make([]string, 0, len(t.Args))
+		for _, arg := range t.Args {
+			args = append(args, arg.Substitute(mapping))
+		}
+
+		return t.Name + "[" + strings.Join(args, ", ") + "]"
+	}
+
+	return t.Raw
+}
+
+// Idents returns the identifiers referenced by the type, including the type arguments.
+func (t *typeExpr) Idents() []string {
+	if t == nil {
+		return nil
+	}
+
+	var result []string
+	if t.Kind == typeIdent {
+		result = append(result, t.Name)
+	}
+
+	for _, arg := range t.Args {
+		result = append(result, arg.Idents()...)
+	}
+
+	result = append(result, t.Key.Idents()...)
+	return append(result, t.Elem.Idents()...)
+}
+
+// IsAny reports whether the type accepts any value.
+func (t *typeExpr) IsAny() bool {
+	if t == nil {
+		return false
+	}
+
+	switch t.Kind {
+	case typeInterface:
+		return true
+	case typeIdent:
+		return t.Name == "any"
+	}
+
+	return false
+}
+
+// matchBracket returns the index of the bracket closing the one at index start.
+func matchBracket(text string, start int) int {
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(text) - 1
+}
+
+// splitTopLevel splits the text by sep outside any brackets.
+func splitTopLevel(text string, sep byte) []string {
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(text[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if last := strings.TrimSpace(text[start:]); len(last) != 0 {
+		result = append(result, last)
+	}
+
+	return result
+}
+
+// findScopeDoc returns the comment lines declared right above the target scope.
+func findScopeDoc(ast goast.Ast, target goast.Scope) []string {
+	var (
+		doc      []string
+		expected = target.Line() - 1
+	)
+
+	scs := ast.Scope()
+	for i := len(scs) - 1; i >= 0; i-- {
+		if scs[i] != target {
+			continue
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			sc := scs[j]
+			if sc.Kind() != scope.Comment || sc.Line() != expected {
+				break
+			}
+
+			text := sc.Node().Text()
+			if !strings.HasPrefix(text, "//go:") && !strings.Contains(text, "go:generate") {
+				doc = append([]string{tidyComment(text)}, doc...)
+			}
+
+			expected--
+		}
+
+		break
+	}
+
+	return doc
+}
+
+// findUnderlyingTypes returns the underlying type text of the non-struct types declared in the ast,
+// e.g. 'Status' to 'int' for 'type Status int'.
+func findUnderlyingTypes(ast goast.Ast) map[string]string {
+	result := map[string]string{}
+	ast.IterScope(func(sc goast.Scope) bool {
+		if sc.Kind() != scope.Type {
+			return true
+		}
+
+		if _, ok := sc.GetStructName(); ok {
+			return true
+		}
+
+		if _, ok := sc.GetInterfaceName(); ok {
+			return true
+		}
+
+		var (
+			name     string
+			typeText strings.Builder
+		)
+
+		sc.Node().IterNext(func(n *goast.Node) bool {
+			switch {
+			case n.Kind() == kind.NewLine || n.Kind() == kind.Comment:
+				return false
+			case len(name) == 0:
+				if n.Kind() == kind.TypeName {
+					name = n.Text()
+				}
+			default:
+				typeText.WriteString(n.Text())
+			}
+
+			return true
+		})
+
+		underlying := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(typeText.String()), "="))
+		if len(name) != 0 && len(underlying) != 0 {
+			result[name] = underlying
+		}
+
+		return true
+	})
+
+	return result
+}
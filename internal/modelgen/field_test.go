@@ -0,0 +1,111 @@
+package modelgen
+
+import (
+	"testing"
+
+	"github.com/yanun0323/goast"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	{
+		te := parseTypeExpr("map[string][]*pkg.Foo")
+		if te.Kind != typeMap || te.Key.Name != "string" {
+			t.Fatalf("map mismatch: %+v", te)
+		}
+
+		elem := te.Elem
+		if elem.Kind != typeSlice || elem.Elem.Kind != typePointer || elem.Elem.Elem.Name != "pkg.Foo" {
+			t.Fatalf("map elem mismatch: %+v", elem)
+		}
+
+		if elem.Elem.Elem.BaseName() != "Foo" || !elem.Elem.Elem.IsQualified() {
+			t.Fatalf("base name mismatch: %s", elem.Elem.Elem.BaseName())
+		}
+	}
+
+	{
+		te := parseTypeExpr("[3]Page[map[string]int, T]")
+		if te.Kind != typeArray || te.Len != "3" {
+			t.Fatalf("array mismatch: %+v", te)
+		}
+
+		if te.Elem.Name != "Page" || len(te.Elem.Args) != 2 || te.Elem.Args[0].Kind != typeMap || te.Elem.Args[1].Name != "T" {
+			t.Fatalf("generic mismatch: %+v", te.Elem)
+		}
+	}
+
+	{
+		te := parseTypeExpr("map[Key][]*Page[Item, time.Time]")
+		if q := te.Qualify("example"); q != "map[example.Key][]*example.Page[example.Item, time.Time]" {
+			t.Fatalf("qualify mismatch: %s", q)
+		}
+	}
+
+	{
+		te := parseTypeExpr("map[K][]*Page[T, Item]")
+		if s := te.Substitute(map[string]string{"K": "string", "T": "int"}); s != "map[string][]*Page[int, Item]" {
+			t.Fatalf("substitute mismatch: %s", s)
+		}
+	}
+}
+
+func TestParseTypeParams(t *testing.T) {
+	scs, err := goast.ParseScope(0, []byte("type Page[K, V comparable, S ~[]V, N Number | ~string] struct {\n\tItems S\n}\n"))
+	if err != nil {
+		t.Fatalf("parse scope, err: %+v", err)
+	}
+
+	params := parseTypeParams(scs[0])
+	if len(params) != 4 {
+		t.Fatalf("type params mismatch: %+v", params)
+	}
+
+	m := &model{Name: "Page", TypeParams: params}
+	if decl := m.TypeParamsDecl(); decl != "[K comparable, V comparable, S ~[]V, N Number | ~string]" {
+		t.Fatalf("type params declaration mismatch: %s", decl)
+	}
+
+	if typ := m.Type(); typ != "Page[K, V, S, N]" {
+		t.Fatalf("type mismatch: %s", typ)
+	}
+
+	if terms := m.constraintTerms(); len(terms) != 5 || terms[3].Name != "Number" || terms[4].Name != "string" {
+		t.Fatalf("constraint terms mismatch: %+v", terms)
+	}
+}
+
+func TestParseStructFields(t *testing.T) {
+	scs, err := goast.ParseScope(0, []byte("type Example struct {\n\t// ID is the identity\n\tID int64 `json:\"id,omitempty\"`\n\tA, B string // a and b\n\n\t*Embedded\n\tFn func(a int) error\n}\n"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	fields, err := parseStructFields(scs[0])
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(fields) != 5 {
+		t.Fatalf("fields length mismatch: %d, %+v", len(fields), fields)
+	}
+
+	if fields[0].Name != "ID" || fields[0].Type != "int64" || len(fields[0].Doc) != 1 || fields[0].Doc[0] != "ID is the identity" {
+		t.Fatalf("field mismatch: %+v", fields[0])
+	}
+
+	if name, options, ok := fields[0].TagValue("json"); !ok || name != "id" || !hasOption(options, "omitempty") {
+		t.Fatalf("tag mismatch: %s, %v", name, options)
+	}
+
+	if fields[2].Name != "B" || fields[2].Type != "string" || fields[2].Comment != "a and b" {
+		t.Fatalf("field mismatch: %+v", fields[2])
+	}
+
+	if !fields[3].Embedded || fields[3].FieldName() != "Embedded" || len(fields[3].Doc) != 0 {
+		t.Fatalf("embedded field mismatch: %+v", fields[3])
+	}
+
+	if fields[4].Type != "func(a int) error" {
+		t.Fatalf("func field mismatch: %+v", fields[4])
+	}
+}
@@ -0,0 +1,338 @@
+package modelgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_flattenPrefixField = "field"
+	_flattenPrefixType  = "type"
+	_flattenPrefixNone  = "none"
+)
+
+// flatStep is a nested struct field on the way to a flattened field.
+type flatStep struct {
+	Name    string
+	Pointer bool
+	// Type is the struct type of the field without the pointer.
+	Type string
+}
+
+// flatField is a field of the flattened target struct.
+type flatField struct {
+	structField
+	// Source is the field name in the innermost source struct.
+	Source string
+	// Path is the nested struct fields from the source struct to the field.
+	Path []flatStep
+}
+
+// Selector returns the selector of the field from the source struct, e.g. 'Extension.Key'.
+func (f flatField) Selector() string {
+	buf := strings.Builder{}
+	for _, step := range f.Path {
+		buf.WriteString(step.Name + ".")
+	}
+	buf.WriteString(f.Source)
+
+	return buf.String()
+}
+
+// flattenTarget inlines the fields of the relative structs referenced by the target struct into the
+// target model, the relative structs are found before the target fields are qualified.
+func flattenTarget(ms *modelSet, relatives map[string]goast.Scope, structName string) error {
+	switch *_flattenPrefix {
+	case _flattenPrefixField, _flattenPrefixType, _flattenPrefixNone:
+	default:
+		return fmt.Errorf("unsupported flatten prefix: %s", *_flattenPrefix)
+	}
+
+	var (
+		fields []flatField
+		names  = map[string]bool{}
+		walk   func(source []structField, prefix string, path []flatStep, visited map[string]bool) error
+	)
+
+	walk = func(source []structField, prefix string, path []flatStep, visited map[string]bool) error {
+		nested := len(path) != 0
+		for _, f := range source {
+			t := parseTypeExpr(f.Type)
+			elem, pointer := t, false
+			if t.Kind == typePointer {
+				elem, pointer = t.Elem, true
+			}
+
+			name := strings.TrimPrefix(elem.Name, ms.qualifier)
+			if sc, ok := relatives[name]; ok && elem.Kind == typeIdent && len(elem.Args) == 0 && f.IsExported() && !visited[name] {
+				children, err := parseStructFields(sc)
+				if err != nil {
+					return fmt.Errorf("parse fields of %s, err: %w", name, err)
+				}
+
+				childPrefix := prefix
+				switch {
+				case f.Embedded:
+				case *_flattenPrefix == _flattenPrefixField:
+					childPrefix += f.Name
+				case *_flattenPrefix == _flattenPrefixType:
+					childPrefix += name
+				}
+
+				step := flatStep{Name: f.FieldName(), Pointer: pointer, Type: elem.Raw}
+				if nested {
+					step.Type = ms.Qualify(step.Type)
+				}
+
+				next := map[string]bool{name: true}
+				for k := range visited {
+					next[k] = true
+				}
+
+				if err := walk(children, childPrefix, append(append([]flatStep{}, path...), step), next); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			field := flatField{structField: f, Source: f.FieldName(), Path: path}
+			if nested {
+				if !f.IsExported() {
+					continue
+				}
+
+				field.Name, field.Embedded, field.Tag = prefix+f.FieldName(), false, ""
+				field.Type = ms.Qualify(f.Type)
+			}
+
+			flatName := field.FieldName()
+			if names[flatName] {
+				return fmt.Errorf("flattened field %s of %s conflicts, try another -flattenprefix", flatName, ms.Target.Name)
+			}
+
+			names[flatName] = true
+			fields = append(fields, field)
+		}
+
+		return nil
+	}
+
+	if err := walk(ms.Target.Fields, "", nil, map[string]bool{structName: true}); err != nil {
+		return err
+	}
+
+	ms.Target.Fields = make([]structField, 0, len(fields))
+	for _, f := range fields {
+		ms.Target.Fields = append(ms.Target.Fields, f.structField)
+	}
+
+	ms.flattened = fields
+	ms.source = ms.qualifier + structName
+	return nil
+}
+
+// generateFlattenConversions generates the conversions between the source struct and the flattened
+// target struct.
+func generateFlattenConversions(ms *modelSet) []merge.Scope {
+	var (
+		m      = ms.Target
+		r      = m.Receiver()
+		fields []flatField
+		result []merge.Scope
+	)
+
+	// the unexported fields of the source struct are unreachable from another package
+	for _, f := range ms.flattened {
+		if len(ms.qualifier) == 0 || f.IsExported() {
+			fields = append(fields, f)
+		}
+	}
+
+	sourceName := ms.source[strings.LastIndex(ms.source, ".")+1:]
+	if constructor := "New" + m.Name; ms.ShouldDeclare(constructor) {
+		result = append(result, merge.Scope{Key: constructor, Text: genFlattenString(m, ms.source, constructor, fields)})
+	}
+
+	if method := "To" + sourceName; ms.ShouldGenerate(m, method) {
+		result = append(result, merge.Scope{Key: m.Name + "." + method, Text: genUnflattenString(ms, m, r, method, fields)})
+	}
+
+	return result
+}
+
+func genFlattenString(m *model, source, constructor string, fields []flatField) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s returns the %s flattened from the %s, it returns nil when the %s is nil.\n", constructor, m.Name, source, source))
+	buf.WriteString(fmt.Sprintf("func %s(src *%s) *%s {\n", constructor, source, m.Name))
+	buf.WriteString("\tif src == nil {\n\t\treturn nil\n\t}\n\n")
+	buf.WriteString(fmt.Sprintf("\tdst := &%s{\n", m.Name))
+
+	var nested []flatField
+	for _, f := range fields {
+		if flatPointerCondition("src", f.Path) != "" {
+			nested = append(nested, f)
+			continue
+		}
+
+		buf.WriteString(fmt.Sprintf("\t\t%s: src.%s,\n", f.FieldName(), f.Selector()))
+	}
+	buf.WriteString("\t}\n")
+
+	for i := 0; i < len(nested); {
+		condition := flatPointerCondition("src", nested[i].Path)
+		j := i
+		for j < len(nested) && flatPointerCondition("src", nested[j].Path) == condition {
+			j++
+		}
+
+		buf.WriteString(fmt.Sprintf("\n\tif %s {\n", condition))
+		for _, f := range nested[i:j] {
+			buf.WriteString(fmt.Sprintf("\t\tdst.%s = src.%s\n", f.FieldName(), f.Selector()))
+		}
+		buf.WriteString("\t}\n")
+
+		i = j
+	}
+
+	buf.WriteString("\n\treturn dst\n}\n")
+
+	return buf.String()
+}
+
+func genUnflattenString(ms *modelSet, m *model, r, method string, fields []flatField) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s returns the %s restored from the %s, the nested struct pointers are nil when all\n", method, ms.source, m.Name))
+	buf.WriteString("// their flattened fields are zero. It returns nil when the receiver is nil.\n")
+	buf.WriteString(fmt.Sprintf("func (%s *%s) %s() *%s {\n", r, m.Name, method, ms.source))
+	buf.WriteString(fmt.Sprintf("\tif %s == nil {\n\t\treturn nil\n\t}\n\n", r))
+	buf.WriteString(fmt.Sprintf("\tdst := &%s{\n", ms.source))
+
+	var nested []flatField
+	for _, f := range fields {
+		if len(f.Path) != 0 {
+			nested = append(nested, f)
+			continue
+		}
+
+		buf.WriteString(fmt.Sprintf("\t\t%s: %s.%s,\n", f.Source, r, f.FieldName()))
+	}
+	buf.WriteString("\t}\n")
+
+	// allocate the nested struct pointers, the outer ones first
+	allocated := map[string]bool{}
+	for _, f := range nested {
+		selector := "dst"
+		for i, step := range f.Path {
+			selector += "." + step.Name
+			if !step.Pointer || allocated[selector] {
+				continue
+			}
+
+			allocated[selector] = true
+
+			var (
+				conditions []string
+				always     bool
+			)
+
+			for _, other := range nested {
+				if len(other.Path) <= i || flatPathSelector(other.Path[:i+1]) != flatPathSelector(f.Path[:i+1]) {
+					continue
+				}
+
+				condition, ok := nonZeroCondition(ms, parseTypeExpr(other.Type), r+"."+other.FieldName())
+				if !ok {
+					always = true
+					break
+				}
+
+				conditions = append(conditions, condition)
+			}
+
+			if always {
+				buf.WriteString(fmt.Sprintf("\n\t%s = new(%s)\n", selector, step.Type))
+				continue
+			}
+
+			buf.WriteString(fmt.Sprintf("\n\tif %s {\n\t\t%s = new(%s)\n\t}\n", strings.Join(conditions, " || "), selector, step.Type))
+		}
+	}
+
+	for i := 0; i < len(nested); {
+		condition := flatPointerCondition("dst", nested[i].Path)
+		j := i
+		for j < len(nested) && flatPointerCondition("dst", nested[j].Path) == condition {
+			j++
+		}
+
+		indent := "\t"
+		if len(condition) != 0 {
+			buf.WriteString(fmt.Sprintf("\n\tif %s {\n", condition))
+			indent = "\t\t"
+		} else {
+			buf.WriteString("\n")
+		}
+
+		for _, f := range nested[i:j] {
+			buf.WriteString(fmt.Sprintf("%sdst.%s = %s.%s\n", indent, f.Selector(), r, f.FieldName()))
+		}
+
+		if len(condition) != 0 {
+			buf.WriteString("\t}\n")
+		}
+
+		i = j
+	}
+
+	buf.WriteString("\n\treturn dst\n}\n")
+
+	return buf.String()
+}
+
+// flatPointerCondition returns the condition that the nested struct pointers on the path aren't nil.
+func flatPointerCondition(receiver string, path []flatStep) string {
+	var (
+		conditions []string
+		selector   = receiver
+	)
+
+	for _, step := range path {
+		selector += "." + step.Name
+		if step.Pointer {
+			conditions = append(conditions, selector+" != nil")
+		}
+	}
+
+	return strings.Join(conditions, " && ")
+}
+
+func flatPathSelector(path []flatStep) string {
+	names := make([]string, 0, len(path))
+	for _, step := range path {
+		names = append(names, step.Name)
+	}
+
+	return strings.Join(names, ".")
+}
+
+// nonZeroCondition returns the condition that the value isn't zero, it returns false when the zero
+// value of the type can't be compared.
+func nonZeroCondition(ms *modelSet, t *typeExpr, v string) (string, bool) {
+	if t.Kind == typeIdent && t.Name == "time.Time" {
+		return "!" + v + ".IsZero()", true
+	}
+
+	zero, ok := zeroValue(ms, t)
+	switch {
+	case !ok, strings.HasSuffix(zero, "{}"):
+		return "", false
+	case zero == "false":
+		return v, true
+	}
+
+	return v + " != " + zero, true
+}
@@ -0,0 +1,170 @@
+package modelgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yanun0323/goast"
+)
+
+func TestFlattenTarget(t *testing.T) {
+	relatives := map[string]goast.Scope{}
+	for name, text := range map[string]string{
+		"Extension": "type Extension struct {\n\tKey string\n\tMeta *Meta\n}\n",
+		"Meta":      "type Meta struct {\n\tNote string\n\tcount int\n}\n",
+	} {
+		scs, err := goast.ParseScope(0, []byte(text))
+		if err != nil {
+			t.Fatalf("parse scope of %s, err: %+v", name, err)
+		}
+
+		relatives[name] = scs[0]
+	}
+
+	fields, err := parseStructFieldsFromText("struct {\nName string `json:\"name\"`\nExtension *Extension\n}")
+	if err != nil {
+		t.Fatalf("parse fields, err: %+v", err)
+	}
+
+	ms := &modelSet{Target: &model{Name: "ExampleFlat", Fields: fields, Declare: true}}
+	if err := flattenTarget(ms, relatives, "Example"); err != nil {
+		t.Fatalf("flatten target, err: %+v", err)
+	}
+
+	if len(ms.flattened) != 3 || ms.source != "Example" {
+		t.Fatalf("flattened mismatch: %+v", ms.flattened)
+	}
+
+	if name := ms.flattened[0]; name.FieldName() != "Name" || name.Tag == "" || len(name.Path) != 0 {
+		t.Fatalf("flattened name mismatch: %+v", name)
+	}
+
+	if key := ms.flattened[1]; key.FieldName() != "ExtensionKey" || key.Selector() != "Extension.Key" {
+		t.Fatalf("flattened key mismatch: %+v", key)
+	}
+
+	note := ms.flattened[2]
+	if note.FieldName() != "ExtensionMetaNote" || note.Selector() != "Extension.Meta.Note" {
+		t.Fatalf("flattened note mismatch: %+v", note)
+	}
+
+	if condition := flatPointerCondition("src", note.Path); condition != "src.Extension != nil && src.Extension.Meta != nil" {
+		t.Fatalf("pointer condition mismatch: %s", condition)
+	}
+}
+
+func TestGenerateFlatten(t *testing.T) {
+	const roundTrip = `
+
+func TestFlatten(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		desc     string
+		src      *domain.Order
+		expected *domain.Order
+	}{
+		{
+			desc: "nested pointers",
+			src: &domain.Order{
+				ID:        1,
+				Base:      domain.Base{Version: 2},
+				Extension: &domain.Extension{Key: "k", Meta: &domain.Meta{Note: "n", Tags: []string{"t"}, CreatedAt: created}},
+				Shipping:  domain.Shipping{City: "c"},
+			},
+		},
+		{
+			desc: "nil pointers",
+			src:  &domain.Order{ID: 1},
+		},
+		{
+			desc: "nil inner pointer",
+			src:  &domain.Order{ID: 1, Extension: &domain.Extension{Key: "k"}},
+		},
+		{
+			desc:     "zero inner pointer",
+			src:      &domain.Order{ID: 1, Extension: &domain.Extension{Key: "k", Meta: &domain.Meta{}}},
+			expected: &domain.Order{ID: 1, Extension: &domain.Extension{Key: "k"}},
+		},
+		{
+			desc:     "zero outer pointer",
+			src:      &domain.Order{ID: 1, Extension: &domain.Extension{}},
+			expected: &domain.Order{ID: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			flat := NEWFLAT(tc.src)
+			if flat.ID != tc.src.ID || flat.Version != tc.src.Version || flat.ShippingCity != tc.src.Shipping.City {
+				t.Fatalf("flat mismatch: %+v", flat)
+			}
+
+			if ext := tc.src.Extension; ext != nil && flat.ExtensionKey != ext.Key {
+				t.Fatalf("flat extension mismatch: %+v", flat)
+			}
+
+			if meta := tc.src.Extension; meta != nil && meta.Meta != nil && (flat.ExtensionMetaNote != meta.Meta.Note || !flat.ExtensionMetaCreatedAt.Equal(meta.Meta.CreatedAt)) {
+				t.Fatalf("flat meta mismatch: %+v", flat)
+			}
+
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.src
+			}
+
+			if restored := flat.ToOrder(); !reflect.DeepEqual(restored, expected) {
+				t.Fatalf("restored mismatch: %+v, %+v", restored, restored.Extension)
+			}
+		})
+	}
+
+	var empty *FLAT
+	if NEWFLAT(nil) != nil || empty.ToOrder() != nil {
+		t.Fatal("conversions of nil should be nil")
+	}
+}
+`
+
+	header := func(pkg, domainImport string) string {
+		return "package " + pkg + "\n\nimport (\n\t\"reflect\"\n\t\"testing\"\n\t\"time\"\n" + domainImport + ")\n"
+	}
+
+	runModule(t, map[string]string{
+		"domain/order.go": `package domain
+
+import "time"
+
+//go:generate modelgen -type=Order -flatten -name=OrderFlat -destination=order_flat.go -package=domain
+//go:generate modelgen -type=Order -flatten -destination=../entity/order.go -package=entity
+
+type Order struct {
+	ID int64
+	Base
+	Extension *Extension
+	Shipping  Shipping
+}
+
+type Base struct {
+	Version int
+}
+
+type Extension struct {
+	Key  string
+	Meta *Meta
+}
+
+type Meta struct {
+	Note      string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+type Shipping struct {
+	City string
+}
+`,
+	}, map[string]string{
+		"domain/order_flat_test.go": header("domain", "") + strings.NewReplacer("domain.", "", "NEWFLAT", "NewOrderFlat", "FLAT", "OrderFlat").Replace(roundTrip),
+		"entity/order_test.go":      header("entity", "\n\t\"example.com/app/domain\"\n") + strings.NewReplacer("NEWFLAT", "NewOrder", "FLAT", "Order").Replace(roundTrip),
+	})
+}
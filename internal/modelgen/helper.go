@@ -0,0 +1,63 @@
+package modelgen
+
+import (
+	"errors"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+var helper = helperInstance{}
+
+type helperInstance struct {
+	util.Helper
+}
+
+func (helperInstance) setupLog(args []string) error {
+	return command.Parse(_flags, Usage, args)
+}
+
+func (helperInstance) requireDestination() error {
+	if len(*_destination) == 0 {
+		_flags.Usage()
+		return errors.New("entity/use/repo at least one param provide")
+	}
+
+	if len(*_package) == 0 && len(*_format) == 0 {
+		_flags.Usage()
+		return errors.New("package not define")
+	}
+
+	return nil
+}
+
+// _actions is the changes of the files made by the current target, they're reported by -json.
+var _actions []gen.Action
+
+func (helperInstance) record(kind gen.ActionKind, name string) {
+	_actions = append(_actions, gen.Action{Kind: kind, Name: name})
+}
+
+// recordFile records the creation or the update of the file, it's called before the file is written.
+func (h helperInstance) recordFile(file string) {
+	_actions = append(_actions, gen.FileAction(h.AbsPath(file)))
+}
+
+func (helperInstance) debugPrint() {
+	if *_debug {
+		println()
+		println("\t", "replace", "=", *_replace)
+		println("\t", "name", "=", *_destination)
+		println()
+	}
+}
+
+// _goKeywords is the keywords which can't be used as identifiers.
+var _goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true,
+}
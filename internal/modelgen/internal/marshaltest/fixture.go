@@ -0,0 +1,71 @@
+// Package marshaltest declares the structs whose JSON methods generated by modelgen -marshal are
+// compared with encoding/json, the generated copies live in the generated package.
+package marshaltest
+
+import "time"
+
+// Order covers the json tags, omitempty, the string option and the embedded structs.
+//
+//go:generate modelgen -marshal -relative -tagged -destination=generated/fixture.go -package=generated
+type Order struct {
+	ID         int64             `json:"id"`
+	Code       string            `json:"code,omitempty"`
+	Amount     float64           `json:"amount,string"`
+	Rate       float32           `json:"rate"`
+	Count      *int              `json:"count,string,omitempty"`
+	Paid       bool              `json:"paid,string"`
+	Note       *string           `json:"note"`
+	Small      int8              `json:"small"`
+	Tiny       uint8             `json:"tiny,omitempty"`
+	Raw        []byte            `json:"raw"`
+	Tags       []string          `json:"tags"`
+	Grid       [2][2]int         `json:"grid"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	ByID       map[int]*Item     `json:"by_id"`
+	ByU        map[uint16]Status `json:"by_u"`
+	Items      []Item            `json:"items"`
+	Status     Status            `json:"status"`
+	Kind       Kind              `json:"kind,string"`
+	Extra      any               `json:"extra"`
+	At         time.Time         `json:"at"`
+	Ptr        **int             `json:"ptr"`
+	Skip       string            `json:"-"`
+	Dash       string            `json:"-,"`
+	HTML       string            `json:"a<b>"`
+	NoTag      string
+	Quoted     string `json:"quoted,string"`
+	unexported int    //nolint:unused
+	Base
+	*Meta
+	Dup1
+	Dup2
+}
+
+type Item struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price,omitempty"`
+	Sub   *Item   `json:"sub,omitempty"`
+}
+
+type Base struct {
+	Created int64  `json:"created"`
+	Name    string `json:"name"`
+}
+
+type Meta struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+}
+
+type Dup1 struct {
+	Same string
+}
+
+type Dup2 struct {
+	Same string
+}
+
+type Status string
+
+type Kind int
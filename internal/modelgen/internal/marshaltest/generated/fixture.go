@@ -0,0 +1,1968 @@
+package generated
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Order covers the json tags, omitempty, the string option and the embedded structs.
+type Order struct {
+	ID         int64             `json:"id"`
+	Code       string            `json:"code,omitempty"`
+	Amount     float64           `json:"amount,string"`
+	Rate       float32           `json:"rate"`
+	Count      *int              `json:"count,string,omitempty"`
+	Paid       bool              `json:"paid,string"`
+	Note       *string           `json:"note"`
+	Small      int8              `json:"small"`
+	Tiny       uint8             `json:"tiny,omitempty"`
+	Raw        []byte            `json:"raw"`
+	Tags       []string          `json:"tags"`
+	Grid       [2][2]int         `json:"grid"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	ByID       map[int]*Item     `json:"by_id"`
+	ByU        map[uint16]Status `json:"by_u"`
+	Items      []Item            `json:"items"`
+	Status     Status            `json:"status"`
+	Kind       Kind              `json:"kind,string"`
+	Extra      any               `json:"extra"`
+	At         time.Time         `json:"at"`
+	Ptr        **int             `json:"ptr"`
+	Skip       string            `json:"-"`
+	Dash       string            `json:"-,"`
+	HTML       string            `json:"a<b>"`
+	NoTag      string
+	Quoted     string `json:"quoted,string"`
+	unexported int    // nolint:unused
+	Base
+	*Meta
+	Dup1
+	Dup2
+}
+
+type Item struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price,omitempty"`
+	Sub   *Item   `json:"sub,omitempty"`
+}
+
+type Base struct {
+	Created int64  `json:"created"`
+	Name    string `json:"name"`
+}
+
+type Meta struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+}
+
+type Dup1 struct {
+	Same string
+}
+
+type Dup2 struct {
+	Same string
+}
+
+type Status string
+
+type Kind int
+
+// AppendJSON appends the JSON encoding of the Order to the buf.
+func (o Order) AppendJSON(buf []byte) ([]byte, error) {
+	var err error
+	start := len(buf)
+	buf = append(buf, `,"id":`...)
+	buf = strconv.AppendInt(buf, o.ID, 10)
+	if o.Code != "" {
+		buf = append(buf, `,"code":`...)
+		buf = appendJSONString(buf, o.Code, true)
+	}
+	buf = append(buf, `,"amount":`...)
+	buf = append(buf, '"')
+	if buf, err = appendJSONFloat(buf, o.Amount, 64); err != nil {
+		return nil, err
+	}
+	buf = append(buf, '"')
+	buf = append(buf, `,"rate":`...)
+	if buf, err = appendJSONFloat(buf, float64(o.Rate), 32); err != nil {
+		return nil, err
+	}
+	if o.Count != nil {
+		buf = append(buf, `,"count":`...)
+		if o.Count == nil {
+			buf = append(buf, "null"...)
+		} else {
+			buf = append(buf, '"')
+			buf = strconv.AppendInt(buf, int64(*o.Count), 10)
+			buf = append(buf, '"')
+		}
+	}
+	buf = append(buf, `,"paid":`...)
+	buf = append(buf, '"')
+	buf = strconv.AppendBool(buf, o.Paid)
+	buf = append(buf, '"')
+	buf = append(buf, `,"note":`...)
+	if o.Note == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = appendJSONString(buf, *o.Note, true)
+	}
+	buf = append(buf, `,"small":`...)
+	buf = strconv.AppendInt(buf, int64(o.Small), 10)
+	if o.Tiny != 0 {
+		buf = append(buf, `,"tiny":`...)
+		buf = strconv.AppendUint(buf, uint64(o.Tiny), 10)
+	}
+	buf = append(buf, `,"raw":`...)
+	if o.Raw == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '"')
+		buf = base64.StdEncoding.AppendEncode(buf, o.Raw)
+		buf = append(buf, '"')
+	}
+	buf = append(buf, `,"tags":`...)
+	if o.Tags == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '[')
+		for index, elem := range o.Tags {
+			if index > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, elem, true)
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, `,"grid":`...)
+	buf = append(buf, '[')
+	for index, elem := range o.Grid {
+		if index > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '[')
+		for index1, elem1 := range elem {
+			if index1 > 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendInt(buf, int64(elem1), 10)
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, ']')
+	if len(o.Labels) != 0 {
+		buf = append(buf, `,"labels":`...)
+		if o.Labels == nil {
+			buf = append(buf, "null"...)
+		} else {
+			buf = append(buf, '{')
+			for index, key := range jsonStringKeys(o.Labels) {
+				if index > 0 {
+					buf = append(buf, ',')
+				}
+				buf = appendJSONString(buf, key, true)
+				buf = append(buf, ':')
+				buf = appendJSONString(buf, o.Labels[key], true)
+			}
+			buf = append(buf, '}')
+		}
+	}
+	buf = append(buf, `,"by_id":`...)
+	if o.ByID == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '{')
+		for index, key := range jsonIntKeys(o.ByID) {
+			if index > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '"')
+			buf = strconv.AppendInt(buf, int64(key), 10)
+			buf = append(buf, '"')
+			buf = append(buf, ':')
+			if o.ByID[key] == nil {
+				buf = append(buf, "null"...)
+			} else {
+				if buf, err = o.ByID[key].AppendJSON(buf); err != nil {
+					return nil, err
+				}
+			}
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, `,"by_u":`...)
+	if o.ByU == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '{')
+		for index, key := range jsonUintKeys(o.ByU) {
+			if index > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '"')
+			buf = strconv.AppendUint(buf, uint64(key), 10)
+			buf = append(buf, '"')
+			buf = append(buf, ':')
+			buf = appendJSONString(buf, string(o.ByU[key]), true)
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, `,"items":`...)
+	if o.Items == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '[')
+		for index, elem := range o.Items {
+			if index > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = elem.AppendJSON(buf); err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, `,"status":`...)
+	buf = appendJSONString(buf, string(o.Status), true)
+	buf = append(buf, `,"kind":`...)
+	buf = append(buf, '"')
+	buf = strconv.AppendInt(buf, int64(o.Kind), 10)
+	buf = append(buf, '"')
+	buf = append(buf, `,"extra":`...)
+	if buf, err = appendJSONValue(buf, o.Extra); err != nil {
+		return nil, err
+	}
+	buf = append(buf, `,"at":`...)
+	if buf, err = appendJSONValue(buf, o.At); err != nil {
+		return nil, err
+	}
+	buf = append(buf, `,"ptr":`...)
+	if o.Ptr == nil {
+		buf = append(buf, "null"...)
+	} else {
+		if *o.Ptr == nil {
+			buf = append(buf, "null"...)
+		} else {
+			buf = strconv.AppendInt(buf, int64(**o.Ptr), 10)
+		}
+	}
+	buf = append(buf, `,"-":`...)
+	buf = appendJSONString(buf, o.Dash, true)
+	buf = append(buf, `,"a\u003cb\u003e":`...)
+	buf = appendJSONString(buf, o.HTML, true)
+	buf = append(buf, `,"NoTag":`...)
+	buf = appendJSONString(buf, o.NoTag, true)
+	buf = append(buf, `,"quoted":`...)
+	buf = appendJSONString(buf, string(appendJSONString(nil, o.Quoted, true)), false)
+	buf = append(buf, `,"created":`...)
+	buf = strconv.AppendInt(buf, o.Base.Created, 10)
+	if o.Meta != nil {
+		buf = append(buf, `,"version":`...)
+		buf = strconv.AppendInt(buf, int64(o.Meta.Version), 10)
+	}
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (o Order) MarshalJSON() ([]byte, error) {
+	return o.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (o *Order) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := o.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Order.
+func (o *Order) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Order")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "id":
+			field = 0
+		case "code":
+			field = 1
+		case "amount":
+			field = 2
+		case "rate":
+			field = 3
+		case "count":
+			field = 4
+		case "paid":
+			field = 5
+		case "note":
+			field = 6
+		case "small":
+			field = 7
+		case "tiny":
+			field = 8
+		case "raw":
+			field = 9
+		case "tags":
+			field = 10
+		case "grid":
+			field = 11
+		case "labels":
+			field = 12
+		case "by_id":
+			field = 13
+		case "by_u":
+			field = 14
+		case "items":
+			field = 15
+		case "status":
+			field = 16
+		case "kind":
+			field = 17
+		case "extra":
+			field = 18
+		case "at":
+			field = 19
+		case "ptr":
+			field = 20
+		case "-":
+			field = 21
+		case "a<b>":
+			field = 22
+		case "NoTag":
+			field = 23
+		case "quoted":
+			field = 24
+		case "created":
+			field = 25
+		case "version":
+			field = 26
+		default:
+			field = foldJSONField(key, "id", "code", "amount", "rate", "count", "paid", "note", "small", "tiny", "raw", "tags", "grid", "labels", "by_id", "by_u", "items", "status", "kind", "extra", "at", "ptr", "-", "a<b>", "NoTag", "quoted", "created", "version")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+				return err
+			} else if !null {
+				o.ID = value
+			}
+		case 1:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				o.Code = value
+			}
+		case 2:
+			if value, null, err := scan.decodeFloat("float64", 64, true); err != nil {
+				return err
+			} else if !null {
+				o.Amount = value
+			}
+		case 3:
+			if value, null, err := scan.decodeFloat("float32", 32, false); err != nil {
+				return err
+			} else if !null {
+				o.Rate = float32(value)
+			}
+		case 4:
+			if value, null, err := scan.decodeInt("int64", 64, true); err != nil {
+				return err
+			} else if null {
+				o.Count = nil
+			} else {
+				if o.Count == nil {
+					o.Count = new(int)
+				}
+				*o.Count = int(value)
+			}
+		case 5:
+			if value, null, err := scan.decodeBool(true); err != nil {
+				return err
+			} else if !null {
+				o.Paid = value
+			}
+		case 6:
+			if scan.peek() == 'n' {
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Note = nil
+			} else {
+				if o.Note == nil {
+					o.Note = new(string)
+				}
+				if value, null, err := scan.decodeString(false); err != nil {
+					return err
+				} else if !null {
+					*o.Note = value
+				}
+			}
+		case 7:
+			if value, null, err := scan.decodeInt("int8", 8, false); err != nil {
+				return err
+			} else if !null {
+				o.Small = int8(value)
+			}
+		case 8:
+			if value, null, err := scan.decodeUint("uint8", 8, false); err != nil {
+				return err
+			} else if !null {
+				o.Tiny = uint8(value)
+			}
+		case 9:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Raw = nil
+			case '[':
+				scan.pos++
+				index := 0
+				for ; ; index++ {
+					if more, err := scan.more(']', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					if index == len(o.Raw) {
+						if index == cap(o.Raw) {
+							o.Raw = append(o.Raw, make([]byte, 1)...)
+						} else {
+							o.Raw = o.Raw[:index+1]
+						}
+					}
+
+					if value, null, err := scan.decodeUint("uint8", 8, false); err != nil {
+						return err
+					} else if !null {
+						o.Raw[index] = byte(value)
+					}
+				}
+
+				if index == 0 {
+					o.Raw = []byte{}
+				} else {
+					o.Raw = o.Raw[:index]
+				}
+			default:
+				value, err := scan.decodeBytes()
+				if err != nil {
+					return err
+				}
+
+				o.Raw = value
+			}
+		case 10:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Tags = nil
+			case '[':
+				scan.pos++
+				index := 0
+				for ; ; index++ {
+					if more, err := scan.more(']', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					if index == len(o.Tags) {
+						if index == cap(o.Tags) {
+							o.Tags = append(o.Tags, make([]string, 1)...)
+						} else {
+							o.Tags = o.Tags[:index+1]
+						}
+					}
+
+					if value, null, err := scan.decodeString(false); err != nil {
+						return err
+					} else if !null {
+						o.Tags[index] = value
+					}
+				}
+
+				if index == 0 {
+					o.Tags = []string{}
+				} else {
+					o.Tags = o.Tags[:index]
+				}
+			default:
+				return scan.typeError("[]string")
+			}
+		case 11:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+			case '[':
+				scan.pos++
+				index := 0
+				for ; ; index++ {
+					if more, err := scan.more(']', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					if index >= len(o.Grid) {
+						if err := scan.skip(); err != nil {
+							return err
+						}
+						continue
+					}
+
+					switch scan.peek() {
+					case 'n':
+						if err := scan.literal("null"); err != nil {
+							return err
+						}
+					case '[':
+						scan.pos++
+						index1 := 0
+						for ; ; index1++ {
+							if more, err := scan.more(']', index1); err != nil {
+								return err
+							} else if !more {
+								break
+							}
+
+							if index1 >= len(o.Grid[index]) {
+								if err := scan.skip(); err != nil {
+									return err
+								}
+								continue
+							}
+
+							if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+								return err
+							} else if !null {
+								o.Grid[index][index1] = int(value)
+							}
+						}
+
+						for ; index1 < len(o.Grid[index]); index1++ {
+							o.Grid[index][index1] = 0
+						}
+					default:
+						return scan.typeError("[2]int")
+					}
+				}
+
+				for ; index < len(o.Grid); index++ {
+					o.Grid[index] = [2]int{}
+				}
+			default:
+				return scan.typeError("[2][2]int")
+			}
+		case 12:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Labels = nil
+			case '{':
+				scan.pos++
+				if o.Labels == nil {
+					o.Labels = make(map[string]string)
+				}
+
+				for index := 0; ; index++ {
+					if more, err := scan.more('}', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					key, err := scan.readKey()
+					if err != nil {
+						return err
+					}
+
+					var elem string
+					if value, null, err := scan.decodeString(false); err != nil {
+						return err
+					} else if !null {
+						elem = value
+					}
+
+					o.Labels[string(key)] = elem
+				}
+			default:
+				return scan.typeError("map[string]string")
+			}
+		case 13:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.ByID = nil
+			case '{':
+				scan.pos++
+				if o.ByID == nil {
+					o.ByID = make(map[int]*Item)
+				}
+
+				for index := 0; ; index++ {
+					if more, err := scan.more('}', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					key, err := scan.readKey()
+					if err != nil {
+						return err
+					}
+
+					var elem *Item
+					if scan.peek() == 'n' {
+						if err := scan.literal("null"); err != nil {
+							return err
+						}
+						elem = nil
+					} else {
+						if elem == nil {
+							elem = new(Item)
+						}
+						if err := elem.decodeJSON(scan); err != nil {
+							return err
+						}
+					}
+
+					number, err := strconv.ParseInt(string(key), 10, 64)
+					if err != nil {
+						return err
+					}
+
+					o.ByID[int(number)] = elem
+				}
+			default:
+				return scan.typeError("map[int]*Item")
+			}
+		case 14:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.ByU = nil
+			case '{':
+				scan.pos++
+				if o.ByU == nil {
+					o.ByU = make(map[uint16]Status)
+				}
+
+				for index := 0; ; index++ {
+					if more, err := scan.more('}', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					key, err := scan.readKey()
+					if err != nil {
+						return err
+					}
+
+					var elem Status
+					if value, null, err := scan.decodeString(false); err != nil {
+						return err
+					} else if !null {
+						elem = Status(value)
+					}
+
+					number, err := strconv.ParseUint(string(key), 10, 16)
+					if err != nil {
+						return err
+					}
+
+					o.ByU[uint16(number)] = elem
+				}
+			default:
+				return scan.typeError("map[uint16]Status")
+			}
+		case 15:
+			switch scan.peek() {
+			case 'n':
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Items = nil
+			case '[':
+				scan.pos++
+				index := 0
+				for ; ; index++ {
+					if more, err := scan.more(']', index); err != nil {
+						return err
+					} else if !more {
+						break
+					}
+
+					if index == len(o.Items) {
+						if index == cap(o.Items) {
+							o.Items = append(o.Items, make([]Item, 1)...)
+						} else {
+							o.Items = o.Items[:index+1]
+						}
+					}
+
+					if err := o.Items[index].decodeJSON(scan); err != nil {
+						return err
+					}
+				}
+
+				if index == 0 {
+					o.Items = []Item{}
+				} else {
+					o.Items = o.Items[:index]
+				}
+			default:
+				return scan.typeError("[]Item")
+			}
+		case 16:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				o.Status = Status(value)
+			}
+		case 17:
+			if value, null, err := scan.decodeInt("int64", 64, true); err != nil {
+				return err
+			} else if !null {
+				o.Kind = Kind(value)
+			}
+		case 18:
+			if err := scan.decodeValue(&o.Extra); err != nil {
+				return err
+			}
+		case 19:
+			if err := scan.decodeValue(&o.At); err != nil {
+				return err
+			}
+		case 20:
+			if scan.peek() == 'n' {
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				o.Ptr = nil
+			} else {
+				if o.Ptr == nil {
+					o.Ptr = new(*int)
+				}
+				if scan.peek() == 'n' {
+					if err := scan.literal("null"); err != nil {
+						return err
+					}
+					*o.Ptr = nil
+				} else {
+					if *o.Ptr == nil {
+						*o.Ptr = new(int)
+					}
+					if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+						return err
+					} else if !null {
+						**o.Ptr = int(value)
+					}
+				}
+			}
+		case 21:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				o.Dash = value
+			}
+		case 22:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				o.HTML = value
+			}
+		case 23:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				o.NoTag = value
+			}
+		case 24:
+			if value, null, err := scan.decodeString(true); err != nil {
+				return err
+			} else if !null {
+				o.Quoted = value
+			}
+		case 25:
+			if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+				return err
+			} else if !null {
+				o.Base.Created = value
+			}
+		case 26:
+			if o.Meta == nil {
+				o.Meta = new(Meta)
+			}
+			if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+				return err
+			} else if !null {
+				o.Meta.Version = int(value)
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AppendJSON appends the JSON encoding of the Item to the buf.
+func (i Item) AppendJSON(buf []byte) ([]byte, error) {
+	var err error
+	start := len(buf)
+	buf = append(buf, `,"name":`...)
+	buf = appendJSONString(buf, i.Name, true)
+	if i.Price != 0 {
+		buf = append(buf, `,"price":`...)
+		if buf, err = appendJSONFloat(buf, i.Price, 64); err != nil {
+			return nil, err
+		}
+	}
+	if i.Sub != nil {
+		buf = append(buf, `,"sub":`...)
+		if i.Sub == nil {
+			buf = append(buf, "null"...)
+		} else {
+			if buf, err = i.Sub.AppendJSON(buf); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (i Item) MarshalJSON() ([]byte, error) {
+	return i.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := i.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Item.
+func (i *Item) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Item")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "name":
+			field = 0
+		case "price":
+			field = 1
+		case "sub":
+			field = 2
+		default:
+			field = foldJSONField(key, "name", "price", "sub")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				i.Name = value
+			}
+		case 1:
+			if value, null, err := scan.decodeFloat("float64", 64, false); err != nil {
+				return err
+			} else if !null {
+				i.Price = value
+			}
+		case 2:
+			if scan.peek() == 'n' {
+				if err := scan.literal("null"); err != nil {
+					return err
+				}
+				i.Sub = nil
+			} else {
+				if i.Sub == nil {
+					i.Sub = new(Item)
+				}
+				if err := i.Sub.decodeJSON(scan); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AppendJSON appends the JSON encoding of the Base to the buf.
+func (b Base) AppendJSON(buf []byte) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, `,"created":`...)
+	buf = strconv.AppendInt(buf, b.Created, 10)
+	buf = append(buf, `,"name":`...)
+	buf = appendJSONString(buf, b.Name, true)
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (b Base) MarshalJSON() ([]byte, error) {
+	return b.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (b *Base) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := b.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Base.
+func (b *Base) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Base")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "created":
+			field = 0
+		case "name":
+			field = 1
+		default:
+			field = foldJSONField(key, "created", "name")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+				return err
+			} else if !null {
+				b.Created = value
+			}
+		case 1:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				b.Name = value
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AppendJSON appends the JSON encoding of the Meta to the buf.
+func (m Meta) AppendJSON(buf []byte) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, `,"version":`...)
+	buf = strconv.AppendInt(buf, int64(m.Version), 10)
+	buf = append(buf, `,"name":`...)
+	buf = appendJSONString(buf, m.Name, true)
+	buf = append(buf, `,"id":`...)
+	buf = appendJSONString(buf, m.ID, true)
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (m Meta) MarshalJSON() ([]byte, error) {
+	return m.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := m.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Meta.
+func (m *Meta) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Meta")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "version":
+			field = 0
+		case "name":
+			field = 1
+		case "id":
+			field = 2
+		default:
+			field = foldJSONField(key, "version", "name", "id")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeInt("int64", 64, false); err != nil {
+				return err
+			} else if !null {
+				m.Version = int(value)
+			}
+		case 1:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				m.Name = value
+			}
+		case 2:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				m.ID = value
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AppendJSON appends the JSON encoding of the Dup1 to the buf.
+func (d Dup1) AppendJSON(buf []byte) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, `,"Same":`...)
+	buf = appendJSONString(buf, d.Same, true)
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (d Dup1) MarshalJSON() ([]byte, error) {
+	return d.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (d *Dup1) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := d.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Dup1.
+func (d *Dup1) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Dup1")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "Same":
+			field = 0
+		default:
+			field = foldJSONField(key, "Same")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				d.Same = value
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AppendJSON appends the JSON encoding of the Dup2 to the buf.
+func (d Dup2) AppendJSON(buf []byte) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, `,"Same":`...)
+	buf = appendJSONString(buf, d.Same, true)
+
+	if len(buf) == start {
+		return append(buf, '{', '}'), nil
+	}
+
+	// replace the leading comma of the first field
+	buf[start] = '{'
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler without reflection.
+func (d Dup2) MarshalJSON() ([]byte, error) {
+	return d.AppendJSON(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler without reflection.
+func (d *Dup2) UnmarshalJSON(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := d.decodeJSON(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+
+// decodeJSON decodes the JSON object of the scanner into the Dup2.
+func (d *Dup2) decodeJSON(scan *jsonScanner) error {
+	switch scan.peek() {
+	case 'n':
+		return scan.literal("null")
+	case '{':
+		scan.pos++
+	default:
+		return scan.typeError("Dup2")
+	}
+
+	for entry := 0; ; entry++ {
+		if more, err := scan.more('}', entry); err != nil {
+			return err
+		} else if !more {
+			return nil
+		}
+
+		key, err := scan.readKey()
+		if err != nil {
+			return err
+		}
+
+		field := -1
+		switch string(key) {
+		case "Same":
+			field = 0
+		default:
+			field = foldJSONField(key, "Same")
+		}
+
+		switch field {
+		case 0:
+			if value, null, err := scan.decodeString(false); err != nil {
+				return err
+			} else if !null {
+				d.Same = value
+			}
+		default:
+			if err := scan.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// appendJSONString appends the JSON string of s like encoding/json.
+func appendJSONString(buf []byte, s string, escapeHTML bool) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendJSONFloat appends the JSON number of f like encoding/json, it returns an error when f is
+// NaN or infinity.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+
+	return buf, nil
+}
+
+// appendJSONValue appends the JSON encoding of the value encoded by encoding/json.
+func appendJSONValue(buf []byte, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, data...), nil
+}
+
+// jsonStringKeys returns the sorted keys of the map.
+func jsonStringKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a] < keys[b]
+	})
+
+	return keys
+}
+
+// jsonIntKeys returns the keys of the map sorted by their JSON strings.
+func jsonIntKeys[K ~int | ~int8 | ~int16 | ~int32 | ~int64, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return strconv.FormatInt(int64(keys[a]), 10) < strconv.FormatInt(int64(keys[b]), 10)
+	})
+
+	return keys
+}
+
+// jsonUintKeys returns the keys of the map sorted by their JSON strings.
+func jsonUintKeys[K ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return strconv.FormatUint(uint64(keys[a]), 10) < strconv.FormatUint(uint64(keys[b]), 10)
+	})
+
+	return keys
+}
+
+// jsonScanner decodes the JSON values of the data in place for the generated UnmarshalJSON
+// methods, the syntax is checked along the way like encoding/json.
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+// peek skips the spaces and returns the next byte, it returns 0 at the end of the data.
+func (s *jsonScanner) peek() byte {
+	for ; s.pos < len(s.data); s.pos++ {
+		switch c := s.data[s.pos]; c {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return c
+		}
+	}
+
+	return 0
+}
+
+// syntaxError returns the error of the unexpected byte at the position.
+func (s *jsonScanner) syntaxError() error {
+	if s.pos >= len(s.data) {
+		return errors.New("unexpected end of JSON input")
+	}
+
+	return fmt.Errorf("json: invalid character %q at offset %d", s.data[s.pos], s.pos)
+}
+
+// typeError returns the error of decoding the next JSON value into the type.
+func (s *jsonScanner) typeError(typ string) error {
+	value := "number"
+	switch c := s.peek(); c {
+	case 'n':
+		value = "null"
+	case 't', 'f':
+		value = "bool"
+	case '"':
+		value = "string"
+	case '[':
+		value = "array"
+	case '{':
+		value = "object"
+	default:
+		if c != '-' && (c < '0' || c > '9') {
+			return s.syntaxError()
+		}
+	}
+
+	return fmt.Errorf("json: cannot unmarshal %s into Go value of type %s", value, typ)
+}
+
+// end returns an error when anything but the spaces follows the top-level value.
+func (s *jsonScanner) end() error {
+	if s.peek(); s.pos < len(s.data) {
+		return fmt.Errorf("json: invalid character %q after top-level value", s.data[s.pos])
+	}
+
+	return nil
+}
+
+// literal consumes the literal, e.g. null.
+func (s *jsonScanner) literal(lit string) error {
+	s.peek()
+	for i := 0; i < len(lit); i++ {
+		if s.pos >= len(s.data) || s.data[s.pos] != lit[i] {
+			return s.syntaxError()
+		}
+
+		s.pos++
+	}
+
+	return nil
+}
+
+// more reports whether the array or the object has the next element, it consumes the comma
+// before the element or the closing delimiter.
+func (s *jsonScanner) more(end byte, index int) (bool, error) {
+	c := s.peek()
+	if c == end {
+		s.pos++
+		return false, nil
+	}
+
+	if index > 0 {
+		if c != ',' {
+			return false, s.syntaxError()
+		}
+
+		s.pos++
+	}
+
+	return true, nil
+}
+
+// scanString consumes the JSON string and returns its content, the content aliases the data
+// unless it's unescaped or the invalid UTF-8 is replaced.
+func (s *jsonScanner) scanString() ([]byte, error) {
+	if s.peek() != '"' {
+		return nil, s.syntaxError()
+	}
+
+	start := s.pos + 1
+	for i := start; i < len(s.data); {
+		switch c := s.data[i]; {
+		case c == '"':
+			s.pos = i + 1
+			return s.data[start:i], nil
+		case c == '\\':
+			return s.unquote(start)
+		case c < ' ':
+			s.pos = i
+			return nil, s.syntaxError()
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRune(s.data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return s.unquote(start)
+			}
+
+			i += size
+		}
+	}
+
+	s.pos = len(s.data)
+	return nil, s.syntaxError()
+}
+
+// unquote returns the unescaped content of the JSON string starting at the position like
+// encoding/json, the invalid UTF-8 and the invalid surrogates are replaced by U+FFFD.
+func (s *jsonScanner) unquote(start int) ([]byte, error) {
+	buf := make([]byte, 0, 2*utf8.UTFMax)
+	for s.pos = start; s.pos < len(s.data); {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return buf, nil
+		case c == '\\':
+			if s.pos+1 >= len(s.data) {
+				s.pos = len(s.data)
+				return nil, s.syntaxError()
+			}
+
+			s.pos++
+			switch c := s.data[s.pos]; c {
+			case '"', '\\', '/':
+				buf = append(buf, c)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r, ok := s.hex4(s.pos + 1)
+				if !ok {
+					return nil, s.syntaxError()
+				}
+
+				s.pos += 4
+				if utf16.IsSurrogate(r) {
+					// a valid pair is consumed, otherwise the surrogate is replaced
+					pair := utf8.RuneError
+					if s.pos+6 < len(s.data) && s.data[s.pos+1] == '\\' && s.data[s.pos+2] == 'u' {
+						if low, ok := s.hex4(s.pos + 3); ok {
+							if pair = utf16.DecodeRune(r, low); pair != utf8.RuneError {
+								s.pos += 6
+							}
+						}
+					}
+
+					r = pair
+				}
+
+				buf = utf8.AppendRune(buf, r)
+			default:
+				return nil, s.syntaxError()
+			}
+
+			s.pos++
+		case c < ' ':
+			return nil, s.syntaxError()
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			s.pos++
+		default:
+			r, size := utf8.DecodeRune(s.data[s.pos:])
+			buf = utf8.AppendRune(buf, r)
+			s.pos += size
+		}
+	}
+
+	return nil, s.syntaxError()
+}
+
+// hex4 returns the rune of the 4 hex digits at the index.
+func (s *jsonScanner) hex4(index int) (rune, bool) {
+	if index+4 > len(s.data) {
+		return 0, false
+	}
+
+	var r rune
+	for _, c := range s.data[index : index+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			r = r<<4 | rune(c-'0')
+		case 'a' <= c && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			r = r<<4 | rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+
+	return r, true
+}
+
+// readString consumes the JSON string and returns its content.
+func (s *jsonScanner) readString() (string, error) {
+	b, err := s.scanString()
+	return string(b), err
+}
+
+// readKey consumes the key of the object and the colon, the key aliases the data unless it's
+// unescaped.
+func (s *jsonScanner) readKey() ([]byte, error) {
+	key, err := s.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.peek() != ':' {
+		return nil, s.syntaxError()
+	}
+
+	s.pos++
+	return key, nil
+}
+
+// readNumber consumes the JSON number and returns its literal.
+func (s *jsonScanner) readNumber() ([]byte, error) {
+	s.peek()
+	start := s.pos
+	digits := func() int {
+		n := 0
+		for ; s.pos < len(s.data) && '0' <= s.data[s.pos] && s.data[s.pos] <= '9'; s.pos++ {
+			n++
+		}
+
+		return n
+	}
+
+	if s.pos < len(s.data) && s.data[s.pos] == '-' {
+		s.pos++
+	}
+
+	switch {
+	case s.pos < len(s.data) && s.data[s.pos] == '0':
+		s.pos++
+	case digits() == 0:
+		return nil, s.syntaxError()
+	}
+
+	if s.pos < len(s.data) && s.data[s.pos] == '.' {
+		s.pos++
+		if digits() == 0 {
+			return nil, s.syntaxError()
+		}
+	}
+
+	if s.pos < len(s.data) && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.data) && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+
+		if digits() == 0 {
+			return nil, s.syntaxError()
+		}
+	}
+
+	return s.data[start:s.pos], nil
+}
+
+// skip consumes the JSON value.
+func (s *jsonScanner) skip() error {
+	switch s.peek() {
+	case '{', '[':
+		end := byte('}')
+		if s.data[s.pos] == '[' {
+			end = ']'
+		}
+
+		s.pos++
+		for index := 0; ; index++ {
+			if more, err := s.more(end, index); err != nil {
+				return err
+			} else if !more {
+				return nil
+			}
+
+			if end == '}' {
+				if _, err := s.readKey(); err != nil {
+					return err
+				}
+			}
+
+			if err := s.skip(); err != nil {
+				return err
+			}
+		}
+	case '"':
+		_, err := s.scanString()
+		return err
+	case 't':
+		return s.literal("true")
+	case 'f':
+		return s.literal("false")
+	case 'n':
+		return s.literal("null")
+	}
+
+	_, err := s.readNumber()
+	return err
+}
+
+// quoted returns the literal quoted in the JSON string by the ',string' option, it reports true
+// when the literal is null.
+func (s *jsonScanner) quoted(typ string) (string, bool, error) {
+	switch s.peek() {
+	case 'n':
+		return "", true, s.literal("null")
+	case '"':
+		v, err := s.readString()
+		switch {
+		case err != nil:
+			return "", false, err
+		case v == "null":
+			return "", true, nil
+		case len(v) == 0 || v[0] == 'n':
+			return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into %s", v, typ)
+		}
+
+		return v, false, nil
+	}
+
+	return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal unquoted value into %s", typ)
+}
+
+// number returns the number literal of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) number(typ string, quoted bool) ([]byte, bool, error) {
+	if quoted {
+		v, null, err := s.quoted(typ)
+		return []byte(v), null, err
+	}
+
+	switch c := s.peek(); {
+	case c == 'n':
+		return nil, true, s.literal("null")
+	case c != '-' && (c < '0' || c > '9'):
+		return nil, false, s.typeError(typ)
+	}
+
+	number, err := s.readNumber()
+	return number, false, err
+}
+
+// decodeString returns the string of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeString(quoted bool) (string, bool, error) {
+	if !quoted {
+		switch s.peek() {
+		case 'n':
+			return "", true, s.literal("null")
+		case '"':
+			v, err := s.readString()
+			return v, false, err
+		}
+
+		return "", false, s.typeError("string")
+	}
+
+	v, null, err := s.quoted("string")
+	if err != nil || null {
+		return "", null, err
+	}
+
+	// the quoted literal must be exactly one JSON string
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		inner := jsonScanner{data: []byte(v)}
+		if unquoted, err := inner.readString(); err == nil && inner.pos == len(inner.data) {
+			return unquoted, false, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into string", v)
+}
+
+// decodeBool returns the boolean of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeBool(quoted bool) (bool, bool, error) {
+	if !quoted {
+		switch s.peek() {
+		case 'n':
+			return false, true, s.literal("null")
+		case 't':
+			return true, false, s.literal("true")
+		case 'f':
+			return false, false, s.literal("false")
+		}
+
+		return false, false, s.typeError("bool")
+	}
+
+	v, null, err := s.quoted("bool")
+	if err != nil || null {
+		return false, null, err
+	}
+
+	switch v {
+	case "true":
+		return true, false, nil
+	case "false":
+		return false, false, nil
+	}
+
+	return false, false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into bool", v)
+}
+
+// decodeInt returns the integer of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeInt(typ string, bits int, quoted bool) (int64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseInt(string(number), 10, bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+
+// decodeUint returns the unsigned integer of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeUint(typ string, bits int, quoted bool) (uint64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseUint(string(number), 10, bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+
+// decodeFloat returns the float of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeFloat(typ string, bits int, quoted bool) (float64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseFloat(string(number), bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+
+// decodeBytes returns the bytes decoded from the base64 JSON string.
+func (s *jsonScanner) decodeBytes() ([]byte, error) {
+	if s.peek() != '"' {
+		return nil, s.typeError("[]byte")
+	}
+
+	b, err := s.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+	n, err := base64.StdEncoding.Decode(result, b)
+	return result[:n], err
+}
+
+// decodeValue decodes the JSON value into v by encoding/json.
+func (s *jsonScanner) decodeValue(v any) error {
+	s.peek()
+	start := s.pos
+	if err := s.skip(); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(s.data[start:s.pos], v)
+}
+
+// foldJSONField returns the index of the field matching the key case-insensitively like
+// encoding/json, it returns -1 if no field matches.
+func foldJSONField(key []byte, fields ...string) int {
+	for i, field := range fields {
+		if strings.EqualFold(string(key), field) {
+			return i
+		}
+	}
+
+	return -1
+}
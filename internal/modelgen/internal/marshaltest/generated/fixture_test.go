@@ -0,0 +1,120 @@
+package generated
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/yanun0323/gox/internal/modelgen/internal/marshaltest"
+)
+
+// FuzzOrder decodes the same data with encoding/json into marshaltest.Order and with the generated
+// methods into Order, then compares the errors and the encoded results.
+func FuzzOrder(f *testing.F) {
+	for _, s := range []string{
+		`{}`,
+		`null`,
+		`{"id":1,"code":"x<y>&","amount":"1.5","rate":1e-7,"count":"3","paid":"true","note":"n","small":-3,"tiny":2,"raw":"aGVsbG8=","tags":["a",null],"grid":[[1,2],[3]],"labels":{"b":"1","a":"2"},"by_id":{"10":{"name":"x"},"9":null},"by_u":{"3":"s"},"items":[{"name":"i","price":2.5,"sub":{"name":"s"}}],"status":"ok","kind":"7","extra":{"a":[1,2.5,"x"]},"at":"2024-01-02T03:04:05Z","ptr":5,"-":"dash","a<b>":"h","NoTag":"nt","quoted":"\"q\"","created":3,"name":"base","version":2,"Same":"s"}`,
+		`{"ID":2,"CODE":"c","Amount":"null","count":null,"raw":[1,2],"tags":[],"items":null,"VERSION":1}`,
+		`{"amount":1}`,
+		`{"paid":"yes"}`,
+		`{"quoted":"q"}`,
+		`{"id":1.5}`,
+		`{"small":300}`,
+		`{"rate":1e40}`,
+		`{"a":1}{}`,
+		`[]`,
+		` {"unknown" : {"a":[1,{"b":null}],"c":"\u0041"}, "id" : 7 } `,
+		`{"code":"\ud83d\ude00\ud800\udc00\ud800x\n\/\u00e9","note":"\udc00"}`,
+		`{"id":1,}`,
+		`{"id":01}`,
+		`{"tags":["a" "b"]}`,
+		`{"note":"a\u00"}`,
+		`{"by_id":{"x":{}}}`,
+	} {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var want marshaltest.Order
+		wantErr := json.Unmarshal(data, &want)
+
+		var got Order
+		gotErr := got.UnmarshalJSON(data)
+		if (wantErr == nil) != (gotErr == nil) {
+			t.Fatalf("unmarshal %q, want err %v, got err %v", data, wantErr, gotErr)
+		}
+
+		if wantErr != nil {
+			return
+		}
+
+		wantJSON, wantErr := json.Marshal(want)
+		gotJSON, gotErr := got.MarshalJSON()
+		if (wantErr == nil) != (gotErr == nil) {
+			t.Fatalf("marshal %q, want err %v, got err %v", data, wantErr, gotErr)
+		}
+
+		if !bytes.Equal(wantJSON, gotJSON) {
+			t.Fatalf("marshal %q\nwant %s\ngot  %s", data, wantJSON, gotJSON)
+		}
+	})
+}
+
+// _benchmarkOrder is the order of the benchmarks, the fields handled by encoding/json in the
+// generated methods, e.g. time.Time and any, are left out.
+const _benchmarkOrder = `{"id":12345,"code":"ORD-12345","amount":"199.5","rate":0.05,"paid":"true","note":"leave at the door","small":-3,"tags":["express","gift","fragile"],"labels":{"channel":"web","region":"tw"},"by_id":{"1":{"name":"book","price":12.5}},"items":[{"name":"book","price":12.5},{"name":"pen","price":1.25,"sub":{"name":"ink","price":0.5}},{"name":"bag","price":30}],"status":"paid","kind":"2","created":1700000000,"name":"order"}`
+
+func BenchmarkMarshal(b *testing.B) {
+	var want marshaltest.Order
+	var got Order
+	if err := json.Unmarshal([]byte(_benchmarkOrder), &want); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := json.Unmarshal([]byte(_benchmarkOrder), &got); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(want); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := got.MarshalJSON(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data := []byte(_benchmarkOrder)
+
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var order marshaltest.Order
+			if err := json.Unmarshal(data, &order); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var order Order
+			if err := order.UnmarshalJSON(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
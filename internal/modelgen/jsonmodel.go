@@ -0,0 +1,584 @@
+package modelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+// generateFromJSONAndSave generates go model structs from the JSON sample or JSON Schema file,
+// then saves them into the destination.
+func generateFromJSONAndSave(file string) error {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read json file, err: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+
+	root, err := decodeOrderedValue(decoder)
+	if err != nil {
+		return fmt.Errorf("parse json file %s, err: %w", file, err)
+	}
+
+	rootName := *_name
+	if len(rootName) == 0 {
+		rootName = helper.CamelCase(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+	}
+
+	g := &jsonModelGenerator{names: map[string]bool{}, imported: map[string]bool{}}
+
+	if schema, ok := root.(*orderedMap); ok && isJSONSchema(schema) {
+		err = g.generateFromSchema(rootName, schema)
+	} else {
+		err = g.generateFromSample(rootName, root)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return saveGeneratedScopes(g.importPaths, g.generated)
+}
+
+// isJSONSchema reports whether the json object is a JSON Schema document rather than a sample.
+func isJSONSchema(m *orderedMap) bool {
+	if m.Has("$schema") || m.Has("openapi") {
+		return true
+	}
+
+	_, isProperties := m.Get("properties").(*orderedMap)
+	return m.Get("type") == "object" && isProperties
+}
+
+type jsonModelGenerator struct {
+	generated   []merge.Scope
+	names       map[string]bool
+	importPaths []string
+	imported    map[string]bool
+
+	// definitions is the $defs of the JSON Schema.
+	definitions map[string]*orderedMap
+	// definitionNames is the struct names of the generated definitions.
+	definitionNames map[string]string
+	// pending is the queued generations of the referenced definitions.
+	pending []func()
+}
+
+// uniqueName returns the name which isn't used by other generated structs.
+func (g *jsonModelGenerator) uniqueName(name string) string {
+	result := name
+	for i := 2; g.names[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+
+	g.names[result] = true
+	return result
+}
+
+func (g *jsonModelGenerator) addImport(path string) {
+	if !g.imported[path] {
+		g.imported[path] = true
+		g.importPaths = append(g.importPaths, path)
+	}
+}
+
+func (g *jsonModelGenerator) addStruct(name string, doc []string, fields []structField) {
+	g.generated = append(g.generated, merge.Scope{Key: name, Text: genStructString(name, doc, fields)})
+}
+
+// fieldNames returns unique exported field names of the json keys.
+func fieldNames(keys []string) []string {
+	var (
+		result = make([]string, 0, len(keys))
+		used   = map[string]bool{}
+	)
+
+	for _, key := range keys {
+		name := helper.CamelCase(key)
+		for i := 2; used[name]; i++ {
+			name = helper.CamelCase(key) + strconv.Itoa(i)
+		}
+
+		used[name] = true
+		result = append(result, name)
+	}
+
+	return result
+}
+
+// jsonShape is the shape inferred from the json sample values, merged across array elements.
+type jsonShape struct {
+	null, boolean, integer, number, str, timestamp, object, array bool
+
+	// keys is the object keys in the order of the first appearance.
+	keys []string
+	// fields is the shapes of the object values.
+	fields map[string]*jsonShape
+	// occurrences counts the objects containing the key.
+	occurrences map[string]int
+	// objects counts the merged objects.
+	objects int
+	// elem is the shape of the array elements.
+	elem *jsonShape
+}
+
+func (s *jsonShape) merge(value any) {
+	switch v := value.(type) {
+	case nil:
+		s.null = true
+	case bool:
+		s.boolean = true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			s.integer = true
+		} else {
+			s.number = true
+		}
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			s.timestamp = true
+		} else {
+			s.str = true
+		}
+	case *orderedMap:
+		s.object = true
+		s.objects++
+		if s.fields == nil {
+			s.fields = map[string]*jsonShape{}
+			s.occurrences = map[string]int{}
+		}
+
+		for _, key := range v.Keys() {
+			field, ok := s.fields[key]
+			if !ok {
+				field = &jsonShape{}
+				s.fields[key] = field
+				s.keys = append(s.keys, key)
+			}
+
+			field.merge(v.Get(key))
+			s.occurrences[key]++
+		}
+	case []any:
+		s.array = true
+		if s.elem == nil {
+			s.elem = &jsonShape{}
+		}
+
+		for _, elem := range v {
+			s.elem.merge(elem)
+		}
+	}
+}
+
+// optional reports whether the field is missing in some of the merged objects.
+func (s *jsonShape) optional(key string) bool {
+	return s.occurrences[key] < s.objects
+}
+
+func (g *jsonModelGenerator) generateFromSample(rootName string, root any) error {
+	shape := &jsonShape{}
+	shape.merge(root)
+
+	if shape.array && shape.elem != nil {
+		shape = shape.elem
+	}
+
+	if !shape.object || shape.array || shape.str || shape.integer || shape.number || shape.boolean {
+		return fmt.Errorf("json sample should be an object or an array of objects")
+	}
+
+	g.sampleStruct(g.uniqueName(rootName), shape)
+	return nil
+}
+
+// sampleStruct generates the struct of the object shape and the structs of its nested objects.
+func (g *jsonModelGenerator) sampleStruct(name string, shape *jsonShape) {
+	var (
+		names  = fieldNames(shape.keys)
+		fields = make([]structField, 0, len(shape.keys))
+		nested []func()
+	)
+
+	for i, key := range shape.keys {
+		field := shape.fields[key]
+		goType, generate := g.sampleType(name+names[i], key, field)
+		if generate != nil {
+			nested = append(nested, generate)
+		}
+
+		tag := key
+		if shape.optional(key) {
+			tag += ",omitempty"
+		}
+
+		fields = append(fields, structField{Name: names[i], Type: goType, Tag: fmt.Sprintf(`json:"%s"`, tag)})
+	}
+
+	g.addStruct(name, nil, fields)
+	for _, generate := range nested {
+		generate()
+	}
+}
+
+// sampleType returns the go type of the shape, and the function generating the nested struct if needed.
+func (g *jsonModelGenerator) sampleType(structName, key string, shape *jsonShape) (string, func()) {
+	kinds := 0
+	for _, k := range []bool{shape.boolean, shape.integer || shape.number, shape.str || shape.timestamp, shape.object, shape.array} {
+		if k {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return "any", nil
+	}
+
+	pointer := ""
+	if shape.null {
+		pointer = "*"
+	}
+
+	switch {
+	case shape.boolean:
+		return pointer + "bool", nil
+	case shape.number:
+		return pointer + "float64", nil
+	case shape.integer:
+		return pointer + "int64", nil
+	case shape.str:
+		return pointer + "string", nil
+	case shape.timestamp:
+		g.addImport("time")
+		return pointer + "time.Time", nil
+	case shape.object:
+		name := g.uniqueName(structName)
+		return "*" + name, func() { g.sampleStruct(name, shape) }
+	case shape.array:
+		if shape.elem == nil {
+			return "[]any", nil
+		}
+
+		elemType, generate := g.sampleType(helper.Singularize(structName), key, shape.elem)
+		return "[]" + strings.TrimPrefix(elemType, "*"), generate
+	}
+
+	return "any", nil
+}
+
+func (g *jsonModelGenerator) generateFromSchema(rootName string, schema *orderedMap) error {
+	g.definitions = map[string]*orderedMap{}
+	g.definitionNames = map[string]string{}
+
+	for _, key := range []string{"$defs", "definitions"} {
+		defs, ok := schema.Get(key).(*orderedMap)
+		if !ok {
+			continue
+		}
+
+		for _, name := range defs.Keys() {
+			if def, ok := defs.Get(name).(*orderedMap); ok {
+				g.definitions[name] = def
+			}
+		}
+	}
+
+	if components, ok := schema.Get("components").(*orderedMap); ok {
+		if schemas, ok := components.Get("schemas").(*orderedMap); ok {
+			for _, name := range schemas.Keys() {
+				if def, ok := schemas.Get(name).(*orderedMap); ok {
+					g.definitions[name] = def
+				}
+			}
+
+			// an OpenAPI document only contains the component schemas
+			if !schema.Has("properties") {
+				for _, name := range schemas.Keys() {
+					g.definitionType(name)
+				}
+
+				g.generatePending()
+				return nil
+			}
+		}
+	}
+
+	name := g.uniqueName(rootName)
+	if title, ok := schema.Get("title").(string); ok && len(*_name) == 0 && len(title) != 0 {
+		delete(g.names, name)
+		name = g.uniqueName(helper.CamelCase(title))
+	}
+
+	g.schemaStruct(name, schema)
+
+	definitionNames := make([]string, 0, len(g.definitions))
+	for defName := range g.definitions {
+		definitionNames = append(definitionNames, defName)
+	}
+	sort.Strings(definitionNames)
+
+	for _, defName := range definitionNames {
+		g.definitionType(defName)
+	}
+
+	g.generatePending()
+	return nil
+}
+
+// generatePending generates the structs of the referenced definitions.
+func (g *jsonModelGenerator) generatePending() {
+	for len(g.pending) != 0 {
+		generate := g.pending[0]
+		g.pending = g.pending[1:]
+		generate()
+	}
+}
+
+// definitionType returns the go type of the definition, and queues the generation of its struct
+// at the first time.
+func (g *jsonModelGenerator) definitionType(defName string) string {
+	if name, ok := g.definitionNames[defName]; ok {
+		return name
+	}
+
+	def, ok := g.definitions[defName]
+	if !ok {
+		return "any"
+	}
+
+	if def.Get("type") != "object" && !def.Has("properties") && !def.Has("allOf") {
+		g.definitionNames[defName] = "any"
+		goType, generate := g.schemaType(helper.CamelCase(defName), def)
+		g.definitionNames[defName] = goType
+		if generate != nil {
+			g.pending = append(g.pending, generate)
+		}
+
+		return goType
+	}
+
+	name := g.uniqueName(helper.CamelCase(defName))
+	g.definitionNames[defName] = name
+	g.pending = append(g.pending, func() { g.schemaStruct(name, def) })
+
+	return name
+}
+
+// schemaProperties returns the properties and the required property names of the object schema,
+// including the properties of allOf.
+func (g *jsonModelGenerator) schemaProperties(schema *orderedMap) (*orderedMap, map[string]bool) {
+	properties := newOrderedMap()
+	required := map[string]bool{}
+
+	if allOf, ok := schema.Get("allOf").([]any); ok {
+		for _, sub := range allOf {
+			subSchema, ok := sub.(*orderedMap)
+			if !ok {
+				continue
+			}
+
+			if ref, ok := subSchema.Get("$ref").(string); ok {
+				subSchema = g.definitions[refName(ref)]
+				if subSchema == nil {
+					continue
+				}
+			}
+
+			subProperties, subRequired := g.schemaProperties(subSchema)
+			for _, key := range subProperties.Keys() {
+				properties.Set(key, subProperties.Get(key))
+			}
+
+			for key := range subRequired {
+				required[key] = true
+			}
+		}
+	}
+
+	if props, ok := schema.Get("properties").(*orderedMap); ok {
+		for _, key := range props.Keys() {
+			properties.Set(key, props.Get(key))
+		}
+	}
+
+	if requiredList, ok := schema.Get("required").([]any); ok {
+		for _, key := range requiredList {
+			if s, ok := key.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	return properties, required
+}
+
+func (g *jsonModelGenerator) schemaStruct(name string, schema *orderedMap) {
+	properties, required := g.schemaProperties(schema)
+
+	var (
+		names  = fieldNames(properties.Keys())
+		fields = make([]structField, 0, properties.Len())
+		nested []func()
+	)
+
+	for i, key := range properties.Keys() {
+		property, _ := properties.Get(key).(*orderedMap)
+		if property == nil {
+			property = newOrderedMap()
+		}
+
+		goType, generate := g.schemaType(name+names[i], property)
+		if generate != nil {
+			nested = append(nested, generate)
+		}
+
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+			if isScalarType(goType) {
+				goType = "*" + goType
+			}
+		}
+
+		field := structField{Name: names[i], Type: goType, Tag: fmt.Sprintf(`json:"%s"`, tag)}
+		if description, ok := property.Get("description").(string); ok && len(description) != 0 {
+			field.Doc = strings.Split(description, "\n")
+		}
+
+		fields = append(fields, field)
+	}
+
+	var doc []string
+	if description, ok := schema.Get("description").(string); ok && len(description) != 0 {
+		doc = strings.Split(description, "\n")
+	}
+
+	g.addStruct(name, doc, fields)
+	for _, generate := range nested {
+		generate()
+	}
+}
+
+// schemaType returns the go type of the schema, and the function generating the nested struct if needed.
+func (g *jsonModelGenerator) schemaType(structName string, schema *orderedMap) (string, func()) {
+	if ref, ok := schema.Get("$ref").(string); ok {
+		name := g.definitionType(refName(ref))
+		if g.names[name] {
+			return "*" + name, nil
+		}
+
+		return name, nil
+	}
+
+	var (
+		types    []string
+		nullable bool
+	)
+
+	switch t := schema.Get("type").(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				if s == "null" {
+					nullable = true
+					continue
+				}
+
+				types = append(types, s)
+			}
+		}
+	}
+
+	if len(types) == 0 && (schema.Has("properties") || schema.Has("allOf")) {
+		types = []string{"object"}
+	}
+
+	if len(types) != 1 {
+		return "any", nil
+	}
+
+	pointer := ""
+	if nullable {
+		pointer = "*"
+	}
+
+	format, _ := schema.Get("format").(string)
+
+	switch types[0] {
+	case "boolean":
+		return pointer + "bool", nil
+	case "integer":
+		switch format {
+		case "int32":
+			return pointer + "int32", nil
+		default:
+			return pointer + "int64", nil
+		}
+	case "number":
+		if format == "float" {
+			return pointer + "float32", nil
+		}
+
+		return pointer + "float64", nil
+	case "string":
+		switch format {
+		case "date-time":
+			g.addImport("time")
+			return pointer + "time.Time", nil
+		case "byte", "binary":
+			return "[]byte", nil
+		}
+
+		return pointer + "string", nil
+	case "array":
+		items, ok := schema.Get("items").(*orderedMap)
+		if !ok {
+			return "[]any", nil
+		}
+
+		elemType, generate := g.schemaType(helper.Singularize(structName), items)
+		return "[]" + strings.TrimPrefix(elemType, "*"), generate
+	case "object":
+		_, hasProperties := schema.Get("properties").(*orderedMap)
+		if !hasProperties && !schema.Has("allOf") {
+			additional, ok := schema.Get("additionalProperties").(*orderedMap)
+			if !ok {
+				return "map[string]any", nil
+			}
+
+			elemType, generate := g.schemaType(helper.Singularize(structName), additional)
+			return "map[string]" + elemType, generate
+		}
+
+		name := g.uniqueName(structName)
+		return "*" + name, func() { g.schemaStruct(name, schema) }
+	}
+
+	return "any", nil
+}
+
+// refName returns the definition name of the local reference like '#/$defs/Name'.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func isScalarType(goType string) bool {
+	switch strings.TrimPrefix(goType, "*") {
+	case "bool", "int32", "int64", "float32", "float64", "string", "time.Time":
+		return !strings.HasPrefix(goType, "*")
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package modelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONModelGenerator(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		source   string
+		expected []string
+		imports  []string
+	}{
+		{
+			desc:   "nested objects",
+			source: `{"id":1,"profile":{"nick_name":"a","score":1.5,"address":{"city":"x"}}}`,
+			expected: []string{
+				"type Member struct {\n\tID int64 `json:\"id\"`\n\tProfile *MemberProfile `json:\"profile\"`\n}\n",
+				"type MemberProfile struct {\n\tNickName string `json:\"nick_name\"`\n\tScore float64 `json:\"score\"`\n\tAddress *MemberProfileAddress `json:\"address\"`\n}\n",
+				"type MemberProfileAddress struct {\n\tCity string `json:\"city\"`\n}\n",
+			},
+		},
+		{
+			desc:   "arrays",
+			source: `[{"tags":["a"],"items":[{"sku":"x","qty":1},{"sku":"y"}],"empty":[]},{"tags":[],"items":[],"empty":[]}]`,
+			expected: []string{
+				"type Member struct {\n\tTags []string `json:\"tags\"`\n\tItems []MemberItem `json:\"items\"`\n\tEmpty []any `json:\"empty\"`\n}\n",
+				"type MemberItem struct {\n\tSku string `json:\"sku\"`\n\tQty int64 `json:\"qty,omitempty\"`\n}\n",
+			},
+		},
+		{
+			desc:   "null and mixed types",
+			source: `[{"note":null,"mixed":1,"amount":1,"created_at":"2024-01-02T03:04:05Z"},{"note":"n","mixed":"s","amount":1.5}]`,
+			expected: []string{
+				"type Member struct {\n\tNote *string `json:\"note\"`\n\tMixed any `json:\"mixed\"`\n\tAmount float64 `json:\"amount\"`\n\tCreatedAt time.Time `json:\"created_at,omitempty\"`\n}\n",
+			},
+			imports: []string{"time"},
+		},
+		{
+			desc:   "key to field names",
+			source: `{"user_id":1,"user-name":"a","UserName":"b","url":"c","2fa":true}`,
+			expected: []string{
+				"type Member struct {\n\tUserID int64 `json:\"user_id\"`\n\tUserName string `json:\"user-name\"`\n\tUserName2 string `json:\"UserName\"`\n\tURL string `json:\"url\"`\n\tX2fa bool `json:\"2fa\"`\n}\n",
+			},
+		},
+		{
+			desc: "json schema",
+			source: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "member",
+				"type": "object",
+				"required": ["id"],
+				"properties": {
+					"id": {"type": "integer", "format": "int32"},
+					"name": {"type": ["string", "null"], "description": "name of the member"},
+					"address": {"$ref": "#/$defs/address"},
+					"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+				},
+				"$defs": {
+					"address": {"type": "object", "properties": {"city": {"type": "string"}}}
+				}
+			}`,
+			expected: []string{
+				"type Member struct {\n\tID int32 `json:\"id\"`\n\t// name of the member\n\tName *string `json:\"name,omitempty\"`\n\tAddress *Address `json:\"address,omitempty\"`\n\tLabels map[string]string `json:\"labels,omitempty\"`\n}\n",
+				"type Address struct {\n\tCity *string `json:\"city,omitempty\"`\n}\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(tc.source)))
+		decoder.UseNumber()
+
+		root, err := decodeOrderedValue(decoder)
+		if err != nil {
+			t.Fatalf("%s: decode, err: %+v", tc.desc, err)
+		}
+
+		g := &jsonModelGenerator{names: map[string]bool{}, imported: map[string]bool{}}
+		if schema, ok := root.(*orderedMap); ok && isJSONSchema(schema) {
+			err = g.generateFromSchema("Member", schema)
+		} else {
+			err = g.generateFromSample("Member", root)
+		}
+
+		if err != nil {
+			t.Fatalf("%s: generate, err: %+v", tc.desc, err)
+		}
+
+		if len(g.generated) != len(tc.expected) {
+			t.Fatalf("%s: generated length mismatch: %+v", tc.desc, g.generated)
+		}
+
+		for i, expected := range tc.expected {
+			if g.generated[i].Text != expected {
+				t.Fatalf("%s: struct %d mismatch:\n%s", tc.desc, i, g.generated[i].Text)
+			}
+		}
+
+		if strings.Join(g.importPaths, ",") != strings.Join(tc.imports, ",") {
+			t.Fatalf("%s: imports mismatch: %+v", tc.desc, g.importPaths)
+		}
+	}
+}
+
+func TestGenerateFromJSON(t *testing.T) {
+	runModule(t, map[string]string{
+		"entity/entity.go": `package entity
+
+//go:generate modelgen -source=member.json -destination=member.go -package=entity
+//go:generate modelgen -source=order.schema.json -name=Order -destination=order.go -package=entity
+`,
+		"entity/member.json": `[{"id":1,"created_at":"2024-01-02T03:04:05Z","profile":{"nick_name":"a"},"items":[{"sku":"x","qty":2}],"note":null,"mixed":1},{"id":2,"items":[],"note":"n","mixed":"s"}]`,
+		"entity/order.schema.json": `{
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"type": "integer"},
+		"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}}
+	},
+	"definitions": {
+		"line": {"type": "object", "properties": {"price": {"type": "number"}}}
+	}
+}`,
+	}, map[string]string{
+		"entity/entity_test.go": `package entity
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	var members []Member
+	if err := json.Unmarshal([]byte(` + "`" + `[{"id":1,"created_at":"2024-01-02T03:04:05Z","profile":{"nick_name":"a"},"items":[{"sku":"x","qty":2}],"note":null,"mixed":1}]` + "`" + `), &members); err != nil {
+		t.Fatalf("unmarshal members, err: %+v", err)
+	}
+
+	m := members[0]
+	if m.ID != 1 || m.CreatedAt.Year() != 2024 || m.Profile.NickName != "a" || m.Items[0].Qty != 2 || m.Note != nil || m.Mixed != float64(1) {
+		t.Fatalf("member mismatch: %+v", m)
+	}
+
+	var order Order
+	if err := json.Unmarshal([]byte(` + "`" + `{"id":3,"lines":[{"price":1.5}]}` + "`" + `), &order); err != nil {
+		t.Fatalf("unmarshal order, err: %+v", err)
+	}
+
+	if order.ID != 3 || *order.Lines[0].Price != 1.5 {
+		t.Fatalf("order mismatch: %+v", order)
+	}
+}
+`,
+	})
+}
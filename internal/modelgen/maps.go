@@ -0,0 +1,300 @@
+package modelgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_methodToMap   = "ToMap"
+	_methodFromMap = "FromMap"
+)
+
+// mapEntry is a field of the model converted by ToMap and FromMap.
+type mapEntry struct {
+	Key string
+	// Selector is the selector of the field from the model, e.g. 'Base.ID'.
+	Selector string
+	Type     string
+	// Pointers is the selectors and types of the embedded struct pointers on the way to the field.
+	Pointers [][2]string
+}
+
+// generateMaps generates ToMap and FromMap of the models, the keys are the names derived from the
+// tags of -nametags like the field name constants, and the fields of the embedded structs are flattened.
+func generateMaps(ms *modelSet) []merge.Scope {
+	var (
+		result  []merge.Scope
+		numeric bool
+	)
+
+	for _, m := range ms.Models {
+		entries := mapEntries(ms, m)
+		if len(entries) == 0 {
+			continue
+		}
+
+		if ms.ShouldGenerate(m, _methodToMap) {
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodToMap,
+				Text: genToMapString(m, entries),
+			})
+		}
+
+		if ms.ShouldGenerate(m, _methodFromMap) {
+			ms.addImport("fmt")
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodFromMap,
+				Text: genFromMapString(ms, m, entries),
+			})
+
+			for _, e := range entries {
+				if _, _, ok := mapNumberConverter(ms, parseTypeExpr(e.Type)); ok {
+					numeric = true
+				}
+			}
+		}
+	}
+
+	if numeric {
+		ms.addImport("math")
+		for _, h := range _mapHelpers {
+			if ms.ShouldDeclare(h.Key) {
+				result = append(result, h)
+			}
+		}
+	}
+
+	return result
+}
+
+// mapEntries returns the exported fields of the model, the fields of the embedded models are
+// flattened with the gorm embedded prefix.
+func mapEntries(ms *modelSet, m *model) []mapEntry {
+	var (
+		entries []mapEntry
+		add     func(fields []structField, prefix, selector string, pointers [][2]string, visited map[string]bool)
+	)
+
+	add = func(fields []structField, prefix, selector string, pointers [][2]string, visited map[string]bool) {
+		for _, f := range fields {
+			if !f.IsExported() {
+				continue
+			}
+
+			if f.Embedded {
+				t := parseTypeExpr(strings.TrimPrefix(f.Type, "*"))
+				if embedded, ok := ms.Lookup(t.Name); ok && !visited[t.Name] {
+					visited[t.Name] = true
+					next := pointers
+					if strings.HasPrefix(f.Type, "*") {
+						next = append(append([][2]string{}, pointers...), [2]string{selector + f.FieldName(), t.Raw})
+					}
+
+					add(embedded.Instantiate(t), prefix+parseGormTag(f)["EMBEDDEDPREFIX"], selector+f.FieldName()+".", next, visited)
+					continue
+				}
+			}
+
+			name, ok := fieldColumnName(f)
+			if !ok {
+				continue
+			}
+
+			entries = append(entries, mapEntry{
+				Key:      prefix + name,
+				Selector: selector + f.FieldName(),
+				Type:     f.Type,
+				Pointers: pointers,
+			})
+		}
+	}
+
+	add(m.Fields, "", "", nil, map[string]bool{m.Name: true})
+	return entries
+}
+
+func genToMapString(m *model, entries []mapEntry) string {
+	r := m.Receiver()
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// ToMap returns the exported fields of the %s keyed by their names, the fields of the nil\n", m.Name))
+	buf.WriteString("// embedded structs are omitted. It returns nil when the receiver is nil.\n")
+	buf.WriteString(fmt.Sprintf("func (%s *%s) ToMap() map[string]any {\n", r, m.Type()))
+	buf.WriteString(fmt.Sprintf("\tif %s == nil {\n\t\treturn nil\n\t}\n\n", r))
+	buf.WriteString(fmt.Sprintf("\tresult := make(map[string]any, %d)\n", len(entries)))
+
+	for i := 0; i < len(entries); {
+		condition := mapPointersCondition(r, entries[i].Pointers)
+		j := i
+		for j < len(entries) && mapPointersCondition(r, entries[j].Pointers) == condition {
+			j++
+		}
+
+		indent := "\t"
+		if len(condition) != 0 {
+			buf.WriteString(fmt.Sprintf("\tif %s {\n", condition))
+			indent = "\t\t"
+		}
+
+		for _, e := range entries[i:j] {
+			buf.WriteString(fmt.Sprintf("%sresult[%s] = %s.%s\n", indent, strconv.Quote(e.Key), r, e.Selector))
+		}
+
+		if len(condition) != 0 {
+			buf.WriteString("\t}\n")
+		}
+
+		i = j
+	}
+
+	buf.WriteString("\n\treturn result\n}\n")
+
+	return buf.String()
+}
+
+func genFromMapString(ms *modelSet, m *model, entries []mapEntry) string {
+	r := m.Receiver()
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// FromMap sets the fields of the %s from the values keyed like ToMap, the missing keys are kept.\n", m.Name))
+	buf.WriteString("// The numbers are converted into the numeric fields, e.g. the float64 decoded from JSON into an\n")
+	buf.WriteString("// int64 field. It returns an error when a value doesn't fit the field.\n")
+	buf.WriteString(fmt.Sprintf("func (%s *%s) FromMap(values map[string]any) error {\n", r, m.Type()))
+
+	for i, e := range entries {
+		if i != 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(fmt.Sprintf("\tif value, ok := values[%s]; ok {\n", strconv.Quote(e.Key)))
+
+		alloc := strings.Builder{}
+		for _, p := range e.Pointers {
+			alloc.WriteString(fmt.Sprintf("\t\tif %s.%s == nil {\n\t\t\t%s.%s = new(%s)\n\t\t}\n\n", r, p[0], r, p[0], p[1]))
+		}
+
+		t := parseTypeExpr(e.Type)
+		if t.IsAny() {
+			buf.WriteString(alloc.String())
+			buf.WriteString(fmt.Sprintf("\t\t%s.%s = value\n\t}\n", r, e.Selector))
+			continue
+		}
+
+		if zero, _ := zeroValue(ms, t); zero == "nil" {
+			buf.WriteString("\t\tif value == nil {\n")
+			if len(e.Pointers) == 0 {
+				buf.WriteString(fmt.Sprintf("\t\t\t%s.%s = nil\n", r, e.Selector))
+			} else {
+				buf.WriteString(fmt.Sprintf("\t\t\tif %s {\n\t\t\t\t%s.%s = nil\n\t\t\t}\n", mapPointersCondition(r, e.Pointers), r, e.Selector))
+			}
+			buf.WriteString("\t\t} else {\n")
+			buf.WriteString(indentText(genFromMapAssign(ms, r, m.Name, e, alloc.String()), "\t"))
+			buf.WriteString("\t\t}\n")
+		} else {
+			buf.WriteString(genFromMapAssign(ms, r, m.Name, e, alloc.String()))
+		}
+
+		buf.WriteString("\t}\n")
+	}
+
+	if len(entries) != 0 {
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("\treturn nil\n}\n")
+
+	return buf.String()
+}
+
+// genFromMapAssign returns the statements asserting the value to the field type and assigning it,
+// the other numbers are converted into the numeric fields.
+func genFromMapAssign(ms *modelSet, r, modelName string, e mapEntry, alloc string) string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("\t\ttyped, ok := value.(%s)\n", e.Type))
+
+	t := parseTypeExpr(e.Type)
+	if converter, bits, ok := mapNumberConverter(ms, t); ok {
+		buf.WriteString("\t\tif !ok {\n")
+		buf.WriteString(fmt.Sprintf("\t\t\tnumber, ok := %s(value, %d)\n", converter, bits))
+		buf.WriteString(fmt.Sprintf("\t\t\tif !ok {\n\t\t\t\treturn fmt.Errorf(\"unexpected value %%v (%%T) of %s of %s\", value, value)\n\t\t\t}\n\n", e.Key, modelName))
+		if t.Kind == typePointer {
+			buf.WriteString(fmt.Sprintf("\t\t\tconverted := %s(number)\n\t\t\ttyped = &converted\n", t.Elem.Raw))
+		} else {
+			buf.WriteString(fmt.Sprintf("\t\t\ttyped = %s(number)\n", t.Raw))
+		}
+		buf.WriteString("\t\t}\n\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("\t\tif !ok {\n\t\t\treturn fmt.Errorf(\"unexpected type %%T of %s of %s\", value)\n\t\t}\n\n", e.Key, modelName))
+	}
+
+	buf.WriteString(alloc)
+	buf.WriteString(fmt.Sprintf("\t\t%s.%s = typed\n", r, e.Selector))
+
+	return buf.String()
+}
+
+// mapNumberConverter returns the helper converting the numbers into the numeric type or the pointer
+// to it, and the bits of the type, the bits of int and uint are 0. The named types of the numeric
+// underlying types are numeric as well.
+func mapNumberConverter(ms *modelSet, t *typeExpr) (string, int, bool) {
+	if t.Kind == typePointer {
+		t = t.Elem
+	}
+
+	if t.Kind != typeIdent {
+		return "", 0, false
+	}
+
+	switch t.Name {
+	case "int":
+		return _mapHelperInt, 0, true
+	case "int8", "int16", "int32", "int64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(t.Name, "int"))
+		return _mapHelperInt, bits, true
+	case "rune":
+		return _mapHelperInt, 32, true
+	case "uint":
+		return _mapHelperUint, 0, true
+	case "byte":
+		return _mapHelperUint, 8, true
+	case "uint8", "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(t.Name, "uint"))
+		return _mapHelperUint, bits, true
+	case "float32":
+		return _mapHelperFloat, 32, true
+	case "float64":
+		return _mapHelperFloat, 64, true
+	}
+
+	if underlying, ok := ms.Underlying(t.Name); ok && underlying.Kind == typeIdent {
+		return mapNumberConverter(ms, underlying)
+	}
+
+	return "", 0, false
+}
+
+// mapPointersCondition returns the condition that the embedded struct pointers aren't nil.
+func mapPointersCondition(r string, pointers [][2]string) string {
+	conditions := make([]string, 0, len(pointers))
+	for _, p := range pointers {
+		conditions = append(conditions, r+"."+p[0]+" != nil")
+	}
+
+	return strings.Join(conditions, " && ")
+}
+
+// indentText indents the non-empty lines of the text.
+func indentText(text, indent string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if len(strings.TrimSpace(line)) != 0 {
+			lines[i] = indent + line
+		}
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,165 @@
+package modelgen
+
+import "github.com/yanun0323/gox/internal/merge"
+
+const (
+	_mapHelperInt   = "fromMapInt"
+	_mapHelperUint  = "fromMapUint"
+	_mapHelperFloat = "fromMapFloat"
+)
+
+// _mapHelpers is the functions converting the numbers of the generated FromMap methods, they're
+// declared once in the destination package.
+var _mapHelpers = []merge.Scope{
+	{Key: _mapHelperInt, Text: `// fromMapInt converts the number of FromMap into the signed integer of the bits, the bits of 0 is
+// the size of int. The floats must be integral, and the number must not overflow.
+func fromMapInt(value any, bits int) (int64, bool) {
+	if bits == 0 {
+		bits = 32 << (^uint(0) >> 63)
+	}
+
+	var n int64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint, uint8, uint16, uint32, uint64:
+		u, ok := fromMapUint(v, 64)
+		if !ok || u > math.MaxInt64 {
+			return 0, false
+		}
+
+		n = int64(u)
+	case float32:
+		return fromMapInt(float64(v), bits)
+	case float64:
+		if v != math.Trunc(v) || v < -1<<63 || v >= 1<<63 {
+			return 0, false
+		}
+
+		n = int64(v)
+	case interface{ Int64() (int64, error) }:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+
+		n = i
+	default:
+		return 0, false
+	}
+
+	if bits < 64 && (n < -1<<(bits-1) || n >= 1<<(bits-1)) {
+		return 0, false
+	}
+
+	return n, true
+}
+`},
+	{Key: _mapHelperUint, Text: `// fromMapUint converts the number of FromMap into the unsigned integer of the bits, the bits of 0
+// is the size of uint. The floats must be integral, and the number must not overflow.
+func fromMapUint(value any, bits int) (uint64, bool) {
+	if bits == 0 {
+		bits = 32 << (^uint(0) >> 63)
+	}
+
+	var u uint64
+	switch v := value.(type) {
+	case uint:
+		u = uint64(v)
+	case uint8:
+		u = uint64(v)
+	case uint16:
+		u = uint64(v)
+	case uint32:
+		u = uint64(v)
+	case uint64:
+		u = v
+	case int, int8, int16, int32, int64:
+		n, ok := fromMapInt(v, 64)
+		if !ok || n < 0 {
+			return 0, false
+		}
+
+		u = uint64(n)
+	case float32:
+		return fromMapUint(float64(v), bits)
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= 1<<64 {
+			return 0, false
+		}
+
+		u = uint64(v)
+	case interface{ Int64() (int64, error) }:
+		n, err := v.Int64()
+		if err != nil || n < 0 {
+			return 0, false
+		}
+
+		u = uint64(n)
+	default:
+		return 0, false
+	}
+
+	if bits < 64 && u >= 1<<bits {
+		return 0, false
+	}
+
+	return u, true
+}
+`},
+	{Key: _mapHelperFloat, Text: `// fromMapFloat converts the number of FromMap into the float of the bits, the number must not
+// overflow float32.
+func fromMapFloat(value any, bits int) (float64, bool) {
+	var f float64
+	switch v := value.(type) {
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int8:
+		f = float64(v)
+	case int16:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case uint:
+		f = float64(v)
+	case uint8:
+		f = float64(v)
+	case uint16:
+		f = float64(v)
+	case uint32:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case interface{ Float64() (float64, error) }:
+		n, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+
+		f = n
+	default:
+		return 0, false
+	}
+
+	if bits == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, false
+	}
+
+	return f, true
+}
+`},
+}
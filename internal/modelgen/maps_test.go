@@ -0,0 +1,132 @@
+package modelgen
+
+import "testing"
+
+func TestMapEntries(t *testing.T) {
+	ms := &modelSet{
+		byName:              map[string]*model{},
+		underlying:          map[string]string{},
+		methods:             map[string]string{},
+		foreignDeclarations: map[string]bool{},
+	}
+
+	for name, text := range map[string]string{
+		"Member": "Base\n*Meta `gorm:\"embeddedPrefix:meta_\"`\nKey string `gorm:\"column:key\"`\nSkip string `gorm:\"-\"`\nhidden int",
+		"Base":   "ID int64 `gorm:\"column:id\"`",
+		"Meta":   "Note string `json:\"note\"`",
+	} {
+		fields, err := parseStructFieldsFromText("struct {\n" + text + "\n}")
+		if err != nil {
+			t.Fatalf("parse fields of %s, err: %+v", name, err)
+		}
+
+		ms.add(&model{Name: name, Fields: fields})
+	}
+
+	member, _ := ms.Lookup("Member")
+	entries := mapEntries(ms, member)
+	if len(entries) != 3 {
+		t.Fatalf("entries mismatch: %+v", entries)
+	}
+
+	if id := entries[0]; id.Key != "id" || id.Selector != "Base.ID" || len(id.Pointers) != 0 {
+		t.Fatalf("entry of id mismatch: %+v", id)
+	}
+
+	if note := entries[1]; note.Key != "meta_note" || note.Selector != "Meta.Note" || len(note.Pointers) != 1 || note.Pointers[0] != [2]string{"Meta", "Meta"} {
+		t.Fatalf("entry of note mismatch: %+v", note)
+	}
+
+	if key := entries[2]; key.Key != "key" || key.Type != "string" {
+		t.Fatalf("entry of key mismatch: %+v", key)
+	}
+}
+
+func TestGenerateMaps(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+//go:generate modelgen -type=Member -relative -maps -nametags=json -destination=../entity/member.go -package=entity
+
+type Status int8
+
+type Member struct {
+	*Meta  ` + "`gorm:\"embeddedPrefix:meta_\"`" + `
+	ID     int64   ` + "`json:\"id\"`" + `
+	Age    uint8   ` + "`json:\"age\"`" + `
+	Score  float32 ` + "`json:\"score\"`" + `
+	Count  *int    ` + "`json:\"count\"`" + `
+	Status Status  ` + "`json:\"status\"`" + `
+	Name   string  ` + "`json:\"name\"`" + `
+	Tags   []string
+}
+
+type Meta struct {
+	Note string ` + "`json:\"note\"`" + `
+}
+`,
+	}, map[string]string{
+		"entity/member_test.go": `package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMaps(t *testing.T) {
+	count := 3
+	src := &Member{Meta: &Meta{Note: "n"}, ID: 1 << 40, Age: 200, Score: 1.5, Count: &count, Status: 2, Name: "a", Tags: []string{"t"}}
+
+	values := src.ToMap()
+	if len(values) != 8 || values["meta_note"] != "n" || values["status"] != Status(2) {
+		t.Fatalf("map mismatch: %+v", values)
+	}
+
+	var dst Member
+	if err := dst.FromMap(values); err != nil || !reflect.DeepEqual(&dst, src) {
+		t.Fatalf("member from map mismatch: %+v, err: %+v", dst, err)
+	}
+
+	buf, err := json.Marshal(values)
+	if err != nil {
+		t.Fatalf("marshal, err: %+v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		t.Fatalf("unmarshal, err: %+v", err)
+	}
+
+	// the slices are decoded as []any
+	if err := (&Member{}).FromMap(decoded); err == nil {
+		t.Fatal("tags of []any should fail")
+	}
+
+	delete(decoded, "tags")
+	dst = Member{Tags: src.Tags}
+	if err := dst.FromMap(decoded); err != nil || !reflect.DeepEqual(&dst, src) {
+		t.Fatalf("member from json map mismatch: %+v, err: %+v", dst, err)
+	}
+
+	for _, invalid := range []map[string]any{
+		{"age": 256},
+		{"age": -1},
+		{"id": 1.5},
+		{"status": float64(128)},
+		{"score": 1e300},
+		{"name": 1},
+		{"count": "3"},
+	} {
+		if err := dst.FromMap(invalid); err == nil {
+			t.Fatalf("invalid values should fail: %+v", invalid)
+		}
+	}
+
+	if err := dst.FromMap(map[string]any{"count": nil, "age": json.Number("7")}); err != nil || dst.Count != nil || dst.Age != 7 {
+		t.Fatalf("member mismatch: %+v, err: %+v", dst, err)
+	}
+}
+`,
+	})
+}
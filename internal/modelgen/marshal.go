@@ -0,0 +1,925 @@
+package modelgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_tagJSON              = "json"
+	_methodMarshalJSON    = "MarshalJSON"
+	_methodUnmarshalJSON  = "UnmarshalJSON"
+	_methodAppendJSON     = "AppendJSON"
+	_methodDecodeJSON     = "decodeJSON"
+	_methodMarshalText    = "MarshalText"
+	_methodUnmarshalText  = "UnmarshalText"
+	_jsonOptionString     = "string"
+	_jsonOptionOmitEmpty  = "omitempty"
+	_jsonOptionTagNameSep = ","
+)
+
+// jsonKind is the kind of the value handled by the generated JSON methods.
+type jsonKind int
+
+const (
+	// jsonOther is the value encoded and decoded by encoding/json.
+	jsonOther jsonKind = iota
+	jsonString
+	jsonBool
+	jsonInt
+	jsonUint
+	jsonFloat
+	jsonBytes
+	jsonSlice
+	jsonArray
+	jsonMap
+	jsonPointer
+	jsonModel
+)
+
+// jsonType is the resolved type of a value handled by the generated JSON methods.
+type jsonType struct {
+	Kind jsonKind
+	// Bits is the size of jsonInt, jsonUint and jsonFloat.
+	Bits int
+	// Declared is the type text of the value, e.g. the named type.
+	Declared string
+	// Expr is the underlying type expression of the value.
+	Expr *typeExpr
+}
+
+// jsonEmbedded is an embedded struct field on the way to a promoted field.
+type jsonEmbedded struct {
+	Name    string
+	Type    string
+	Pointer bool
+}
+
+// jsonField is a field encoded by the generated JSON methods, the promoted fields of the embedded
+// structs are collected with the same rules as encoding/json.
+type jsonField struct {
+	Name      string
+	Tagged    bool
+	Index     []int
+	Embedded  []jsonEmbedded
+	Field     structField
+	Type      *typeExpr
+	Quoted    bool
+	OmitEmpty bool
+}
+
+// generateJSON generates the reflection-free MarshalJSON, AppendJSON and UnmarshalJSON methods
+// of the models, they produce the same results as encoding/json.
+func generateJSON(ms *modelSet) ([]merge.Scope, error) {
+	codec := jsonCodec{models: ms}
+
+	var result []merge.Scope
+	for _, m := range ms.Models {
+		if codec.hasCustomMethods(m.Name) {
+			continue
+		}
+
+		fields, err := codec.fields(m)
+		if err != nil {
+			return nil, err
+		}
+
+		if ms.ShouldGenerate(m, _methodAppendJSON) {
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodAppendJSON,
+				Text: codec.genAppendJSONString(m, fields),
+			})
+		}
+
+		if ms.ShouldGenerate(m, _methodMarshalJSON) {
+			result = append(result, merge.Scope{
+				Key: m.Name + "." + _methodMarshalJSON,
+				Text: fmt.Sprintf(`// %s implements the json.Marshaler without reflection.
+func (%s %s) %s() ([]byte, error) {
+	return %s.%s(nil)
+}
+`, _methodMarshalJSON, m.Receiver(), m.Name, _methodMarshalJSON, m.Receiver(), _methodAppendJSON),
+			})
+		}
+
+		if ms.ShouldGenerate(m, _methodUnmarshalJSON) {
+			result = append(result, merge.Scope{
+				Key: m.Name + "." + _methodUnmarshalJSON,
+				Text: fmt.Sprintf(`// %s implements the json.Unmarshaler without reflection.
+func (%s *%s) %s(data []byte) error {
+	scan := jsonScanner{data: data}
+	if err := %s.%s(&scan); err != nil {
+		return err
+	}
+
+	return scan.end()
+}
+`, _methodUnmarshalJSON, m.Receiver(), m.Name, _methodUnmarshalJSON, m.Receiver(), _methodDecodeJSON),
+			})
+		}
+
+		if ms.ShouldGenerate(m, _methodDecodeJSON) {
+			result = append(result, merge.Scope{
+				Key:  m.Name + "." + _methodDecodeJSON,
+				Text: codec.genDecodeJSONString(m, fields),
+			})
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	for _, path := range []string{"encoding/base64", "encoding/json", "errors", "fmt", "math", "sort", "strconv", "strings", "unicode/utf16", "unicode/utf8"} {
+		ms.addImport(path)
+	}
+
+	for _, h := range _jsonHelpers {
+		if ms.ShouldDeclare(h.Key) {
+			result = append(result, h)
+		}
+	}
+
+	return result, nil
+}
+
+// jsonCodec resolves the types of the models for the generated JSON methods.
+type jsonCodec struct {
+	models *modelSet
+}
+
+// hasCustomMethods reports whether the type declares its own JSON or text methods, such types are
+// handled by encoding/json.
+func (c jsonCodec) hasCustomMethods(name string) bool {
+	_, isModel := c.models.Lookup(name)
+	for _, method := range []string{_methodMarshalJSON, _methodUnmarshalJSON, _methodMarshalText, _methodUnmarshalText} {
+		key := name + "." + method
+		if _, ok := c.models.methods[key]; ok && (!isModel || c.models.foreignDeclarations[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve returns the resolved type of the type expression.
+func (c jsonCodec) resolve(t *typeExpr) jsonType {
+	result := jsonType{Kind: jsonOther, Declared: t.Raw, Expr: t}
+	switch t.Kind {
+	case typePointer:
+		result.Kind = jsonPointer
+	case typeSlice:
+		result.Kind = jsonSlice
+		if t.Elem.Kind == typeIdent && (t.Elem.Name == "byte" || t.Elem.Name == "uint8") {
+			result.Kind = jsonBytes
+		}
+	case typeArray:
+		result.Kind = jsonArray
+	case typeMap:
+		switch c.resolve(t.Key).Kind {
+		case jsonString, jsonInt, jsonUint:
+			result.Kind = jsonMap
+		}
+	case typeIdent:
+		if len(t.Args) != 0 || c.hasCustomMethods(t.Name) {
+			return result
+		}
+
+		if _, ok := c.models.Lookup(t.Name); ok {
+			result.Kind = jsonModel
+			return result
+		}
+
+		switch t.Name {
+		case "string":
+			result.Kind = jsonString
+		case "bool":
+			result.Kind = jsonBool
+		case "int", "int64":
+			result.Kind, result.Bits = jsonInt, 64
+		case "int8":
+			result.Kind, result.Bits = jsonInt, 8
+		case "int16":
+			result.Kind, result.Bits = jsonInt, 16
+		case "int32", "rune":
+			result.Kind, result.Bits = jsonInt, 32
+		case "uint", "uint64", "uintptr":
+			result.Kind, result.Bits = jsonUint, 64
+		case "uint8", "byte":
+			result.Kind, result.Bits = jsonUint, 8
+		case "uint16":
+			result.Kind, result.Bits = jsonUint, 16
+		case "uint32":
+			result.Kind, result.Bits = jsonUint, 32
+		case "float32":
+			result.Kind, result.Bits = jsonFloat, 32
+		case "float64":
+			result.Kind, result.Bits = jsonFloat, 64
+		default:
+			if underlying, ok := c.models.Underlying(t.Name); ok {
+				result = c.resolve(underlying)
+				result.Declared = t.Raw
+			}
+		}
+	}
+
+	return result
+}
+
+// isQuotable reports whether the ',string' option applies to the type, the option applies to
+// strings, booleans, numbers and the pointers to them.
+func (c jsonCodec) isQuotable(t *typeExpr) bool {
+	if t.Kind == typePointer {
+		t = t.Elem
+	}
+
+	switch c.resolve(t).Kind {
+	case jsonString, jsonBool, jsonInt, jsonUint, jsonFloat:
+		return true
+	}
+
+	return false
+}
+
+// fields returns the fields of the model encoded by encoding/json in order, including the fields
+// promoted from the embedded structs.
+func (c jsonCodec) fields(m *model) ([]jsonField, error) {
+	type candidate struct {
+		model    *model
+		index    []int
+		embedded []jsonEmbedded
+	}
+
+	var (
+		fields    []jsonField
+		next      = []candidate{{model: m}}
+		count     map[string]int
+		nextCount = map[string]int{}
+		visited   = map[string]bool{}
+	)
+
+	for len(next) != 0 {
+		current := next
+		next = nil
+		count, nextCount = nextCount, map[string]int{}
+
+		for _, cand := range current {
+			if visited[cand.model.Name] {
+				continue
+			}
+			visited[cand.model.Name] = true
+
+			for i, f := range cand.model.Fields {
+				t := parseTypeExpr(f.Type)
+				elem := t
+				if elem.Kind == typePointer {
+					elem = elem.Elem
+				}
+
+				embedded, isModel := c.models.Lookup(elem.Name)
+				isModel = isModel && elem.Kind == typeIdent
+				if f.Embedded {
+					if !isModel {
+						if _, ok := c.models.Underlying(elem.Name); !ok {
+							return nil, fmt.Errorf("unsupported embedded type %s of %s", f.Type, cand.model.Name)
+						}
+					}
+
+					if !f.IsExported() && !isModel {
+						continue
+					}
+				} else if !f.IsExported() {
+					continue
+				}
+
+				tag, _ := reflect.StructTag(f.Tag).Lookup(_tagJSON)
+				if tag == "-" {
+					continue
+				}
+
+				name, options, _ := strings.Cut(tag, _jsonOptionTagNameSep)
+				if !isValidJSONTag(name) {
+					name = ""
+				}
+
+				index := append(append([]int{}, cand.index...), i)
+				if len(name) != 0 || !f.Embedded || !isModel {
+					optionList := strings.Split(options, _jsonOptionTagNameSep)
+					field := jsonField{
+						Name:      name,
+						Tagged:    len(name) != 0,
+						Index:     index,
+						Embedded:  cand.embedded,
+						Field:     f,
+						Type:      t,
+						Quoted:    hasOption(optionList, _jsonOptionString) && c.isQuotable(t),
+						OmitEmpty: hasOption(optionList, _jsonOptionOmitEmpty),
+					}
+
+					if !field.Tagged {
+						field.Name = f.FieldName()
+					}
+
+					fields = append(fields, field)
+					if count[cand.model.Name] > 1 {
+						// the fields of the same struct embedded more than once at the same level
+						// annihilate each other
+						fields = append(fields, field)
+					}
+
+					continue
+				}
+
+				nextCount[embedded.Name]++
+				if nextCount[embedded.Name] == 1 {
+					next = append(next, candidate{
+						model: embedded,
+						index: index,
+						embedded: append(append([]jsonEmbedded{}, cand.embedded...), jsonEmbedded{
+							Name:    f.FieldName(),
+							Type:    embedded.Name,
+							Pointer: t.Kind == typePointer,
+						}),
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		switch {
+		case fields[i].Name != fields[j].Name:
+			return fields[i].Name < fields[j].Name
+		case len(fields[i].Index) != len(fields[j].Index):
+			return len(fields[i].Index) < len(fields[j].Index)
+		case fields[i].Tagged != fields[j].Tagged:
+			return fields[i].Tagged
+		}
+
+		return compareIndex(fields[i].Index, fields[j].Index) < 0
+	})
+
+	// keep the dominant field of the same name like the visibility rules of Go
+	var result []jsonField
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].Name == fields[i].Name {
+			j++
+		}
+
+		if j-i == 1 || len(fields[i].Index) != len(fields[i+1].Index) || fields[i].Tagged != fields[i+1].Tagged {
+			result = append(result, fields[i])
+		}
+
+		i = j
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return compareIndex(result[i].Index, result[j].Index) < 0
+	})
+
+	return result, nil
+}
+
+func compareIndex(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+
+	return len(a) - len(b)
+}
+
+// isValidJSONTag reports whether the tag name is accepted by encoding/json.
+func isValidJSONTag(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+
+	return true
+}
+
+// access returns the expression of the field from the receiver, and the embedded pointers which
+// must not be nil to access the field.
+func (f jsonField) access(receiver string) (string, []string) {
+	var (
+		expr     = receiver
+		pointers []string
+	)
+
+	for _, e := range f.Embedded {
+		expr += "." + e.Name
+		if e.Pointer {
+			pointers = append(pointers, expr)
+		}
+	}
+
+	return expr + "." + f.Field.FieldName(), pointers
+}
+
+func (c jsonCodec) genAppendJSONString(m *model, fields []jsonField) string {
+	receiver := m.Receiver()
+	body := &strings.Builder{}
+	enc := &jsonEncoder{codec: c, buf: body}
+	for _, f := range fields {
+		expr, pointers := f.access(receiver)
+		indent := 1
+		for _, p := range pointers {
+			enc.writeLine(indent, "if %s != nil {", p)
+			indent++
+		}
+
+		omitEmpty := ""
+		if f.OmitEmpty {
+			omitEmpty = enc.nonEmpty(expr, c.resolve(f.Type))
+		}
+
+		if len(omitEmpty) != 0 {
+			enc.writeLine(indent, "if %s {", omitEmpty)
+			indent++
+		}
+
+		name, _ := json.Marshal(f.Name)
+		enc.writeLine(indent, "buf = append(buf, `,%s:`...)", name)
+		enc.encode(expr, f.Type, f.Quoted, indent, 0)
+
+		if len(omitEmpty) != 0 {
+			indent--
+			enc.writeLine(indent, "}")
+		}
+
+		for range pointers {
+			indent--
+			enc.writeLine(indent, "}")
+		}
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s appends the JSON encoding of the %s to the buf.\n", _methodAppendJSON, m.Name))
+	buf.WriteString(fmt.Sprintf("func (%s %s) %s(buf []byte) ([]byte, error) {\n", receiver, m.Name, _methodAppendJSON))
+	if enc.usesErr {
+		buf.WriteString("\tvar err error\n")
+	}
+	buf.WriteString("\tstart := len(buf)\n")
+	buf.WriteString(body.String())
+	buf.WriteString("\n\tif len(buf) == start {\n\t\treturn append(buf, '{', '}'), nil\n\t}\n\n")
+	buf.WriteString("\t// replace the leading comma of the first field\n")
+	buf.WriteString("\tbuf[start] = '{'\n")
+	buf.WriteString("\treturn append(buf, '}'), nil\n}\n")
+
+	return buf.String()
+}
+
+func (c jsonCodec) genDecodeJSONString(m *model, fields []jsonField) string {
+	receiver := m.Receiver()
+	buf := &strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s decodes the JSON object of the scanner into the %s.\n", _methodDecodeJSON, m.Name))
+	buf.WriteString(fmt.Sprintf("func (%s *%s) %s(scan *jsonScanner) error {\n", receiver, m.Name, _methodDecodeJSON))
+	buf.WriteString("\tswitch scan.peek() {\n\tcase 'n':\n\t\treturn scan.literal(\"null\")\n\tcase '{':\n\t\tscan.pos++\n\tdefault:\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn scan.typeError(%q)\n\t}\n\n", m.Name))
+	buf.WriteString("\tfor entry := 0; ; entry++ {\n")
+	buf.WriteString("\t\tif more, err := scan.more('}', entry); err != nil {\n\t\t\treturn err\n\t\t} else if !more {\n\t\t\treturn nil\n\t\t}\n\n")
+
+	if len(fields) == 0 {
+		buf.WriteString("\t\tif _, err := scan.readKey(); err != nil {\n\t\t\treturn err\n\t\t}\n\n")
+		buf.WriteString("\t\tif err := scan.skip(); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n}\n")
+		return buf.String()
+	}
+
+	buf.WriteString("\t\tkey, err := scan.readKey()\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\n")
+
+	// the exact match takes precedence over the case-insensitive match like encoding/json
+	names := make([]string, 0, len(fields))
+	buf.WriteString("\t\tfield := -1\n\t\tswitch string(key) {\n")
+	for i, f := range fields {
+		names = append(names, ", "+strconv.Quote(f.Name))
+		buf.WriteString(fmt.Sprintf("\t\tcase %s:\n\t\t\tfield = %d\n", strconv.Quote(f.Name), i))
+	}
+	buf.WriteString("\t\tdefault:\n")
+	buf.WriteString(fmt.Sprintf("\t\t\tfield = foldJSONField(key%s)\n", strings.Join(names, "")))
+	buf.WriteString("\t\t}\n\n")
+
+	dec := &jsonDecoder{codec: c, buf: buf}
+	buf.WriteString("\t\tswitch field {\n")
+	for i, f := range fields {
+		buf.WriteString(fmt.Sprintf("\t\tcase %d:\n", i))
+
+		// allocate the nil embedded pointers like encoding/json
+		expr, _ := f.access(receiver)
+		embedded := receiver
+		for _, e := range f.Embedded {
+			embedded += "." + e.Name
+			if !e.Pointer {
+				continue
+			}
+
+			dec.writeLine(3, "if %s == nil {", embedded)
+			if helper.IsFirstUpperCase(e.Name) {
+				dec.writeLine(4, "%s = new(%s)", embedded, e.Type)
+			} else {
+				dec.writeLine(4, "return errors.New(%q)", "json: cannot set embedded pointer to unexported struct: "+e.Type)
+			}
+			dec.writeLine(3, "}")
+		}
+
+		dec.decode(expr, f.Type, f.Quoted, 3, 0)
+	}
+	buf.WriteString("\t\tdefault:\n")
+	buf.WriteString("\t\t\tif err := scan.skip(); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n}\n")
+
+	return buf.String()
+}
+
+// jsonEncoder writes the statements appending the JSON encoding of the values to the buf.
+type jsonEncoder struct {
+	codec   jsonCodec
+	buf     *strings.Builder
+	usesErr bool
+}
+
+func (e *jsonEncoder) writeLine(indent int, format string, a ...any) {
+	e.buf.WriteString(strings.Repeat("\t", indent) + fmt.Sprintf(format, a...) + "\n")
+}
+
+// nonEmpty returns the condition reporting whether the value is not empty for the omitempty
+// option, it returns empty string when the value is never empty.
+func (e *jsonEncoder) nonEmpty(v string, t jsonType) string {
+	switch t.Kind {
+	case jsonString:
+		return v + ` != ""`
+	case jsonBool:
+		return v
+	case jsonInt, jsonUint, jsonFloat:
+		return v + " != 0"
+	case jsonBytes, jsonSlice, jsonArray, jsonMap:
+		return "len(" + v + ") != 0"
+	case jsonPointer:
+		return v + " != nil"
+	case jsonOther:
+		switch {
+		case t.Expr.IsAny(), t.Expr.Kind == typeFunc, t.Expr.Kind == typeChan, t.Expr.Name == "error":
+			return v + " != nil"
+		case t.Expr.Kind == typeSlice, t.Expr.Kind == typeMap, t.Expr.Name == "json.RawMessage":
+			return "len(" + v + ") != 0"
+		case t.Expr.Name == "json.Number":
+			return v + ` != ""`
+		}
+	}
+
+	return ""
+}
+
+// encode writes the statements appending the JSON encoding of the value v of type t, the value is
+// quoted into a JSON string with the ',string' option.
+func (e *jsonEncoder) encode(v string, t *typeExpr, quoted bool, indent, depth int) {
+	var (
+		r      = e.codec.resolve(t)
+		suffix = depthSuffix(depth)
+		quote  = func() {
+			if quoted {
+				e.writeLine(indent, `buf = append(buf, '"')`)
+			}
+		}
+	)
+
+	switch r.Kind {
+	case jsonString:
+		if quoted {
+			e.writeLine(indent, "buf = appendJSONString(buf, string(appendJSONString(nil, %s, true)), false)", convert("string", v, r.Declared))
+			return
+		}
+
+		e.writeLine(indent, "buf = appendJSONString(buf, %s, true)", convert("string", v, r.Declared))
+	case jsonBool:
+		quote()
+		e.writeLine(indent, "buf = strconv.AppendBool(buf, %s)", convert("bool", v, r.Declared))
+		quote()
+	case jsonInt:
+		quote()
+		e.writeLine(indent, "buf = strconv.AppendInt(buf, %s, 10)", convert("int64", v, r.Declared))
+		quote()
+	case jsonUint:
+		quote()
+		e.writeLine(indent, "buf = strconv.AppendUint(buf, %s, 10)", convert("uint64", v, r.Declared))
+		quote()
+	case jsonFloat:
+		e.usesErr = true
+		quote()
+		e.writeLine(indent, "if buf, err = appendJSONFloat(buf, %s, %d); err != nil {", convert("float64", v, r.Declared), r.Bits)
+		e.writeLine(indent+1, "return nil, err")
+		e.writeLine(indent, "}")
+		quote()
+	case jsonBytes:
+		e.writeLine(indent, "if %s == nil {", v)
+		e.writeLine(indent+1, `buf = append(buf, "null"...)`)
+		e.writeLine(indent, "} else {")
+		e.writeLine(indent+1, `buf = append(buf, '"')`)
+		e.writeLine(indent+1, "buf = base64.StdEncoding.AppendEncode(buf, %s)", convert("[]byte", v, r.Declared))
+		e.writeLine(indent+1, `buf = append(buf, '"')`)
+		e.writeLine(indent, "}")
+	case jsonSlice, jsonArray:
+		inner := indent
+		if r.Kind == jsonSlice {
+			e.writeLine(indent, "if %s == nil {", v)
+			e.writeLine(indent+1, `buf = append(buf, "null"...)`)
+			e.writeLine(indent, "} else {")
+			inner++
+		}
+
+		e.writeLine(inner, "buf = append(buf, '[')")
+		e.writeLine(inner, "for index%s, elem%s := range %s {", suffix, suffix, v)
+		e.writeLine(inner+1, "if index%s > 0 {", suffix)
+		e.writeLine(inner+2, "buf = append(buf, ',')")
+		e.writeLine(inner+1, "}")
+		e.encode("elem"+suffix, r.Expr.Elem, false, inner+1, depth+1)
+		e.writeLine(inner, "}")
+		e.writeLine(inner, "buf = append(buf, ']')")
+
+		if r.Kind == jsonSlice {
+			e.writeLine(indent, "}")
+		}
+	case jsonMap:
+		key := e.codec.resolve(r.Expr.Key)
+		keys := map[jsonKind]string{jsonString: "jsonStringKeys", jsonInt: "jsonIntKeys", jsonUint: "jsonUintKeys"}[key.Kind]
+		e.writeLine(indent, "if %s == nil {", v)
+		e.writeLine(indent+1, `buf = append(buf, "null"...)`)
+		e.writeLine(indent, "} else {")
+		e.writeLine(indent+1, "buf = append(buf, '{')")
+		e.writeLine(indent+1, "for index%s, key%s := range %s(%s) {", suffix, suffix, keys, v)
+		e.writeLine(indent+2, "if index%s > 0 {", suffix)
+		e.writeLine(indent+3, "buf = append(buf, ',')")
+		e.writeLine(indent+2, "}")
+		switch key.Kind {
+		case jsonString:
+			e.writeLine(indent+2, "buf = appendJSONString(buf, %s, true)", convert("string", "key"+suffix, key.Declared))
+		case jsonInt:
+			e.writeLine(indent+2, `buf = append(buf, '"')`)
+			e.writeLine(indent+2, "buf = strconv.AppendInt(buf, %s, 10)", convert("int64", "key"+suffix, key.Declared))
+			e.writeLine(indent+2, `buf = append(buf, '"')`)
+		case jsonUint:
+			e.writeLine(indent+2, `buf = append(buf, '"')`)
+			e.writeLine(indent+2, "buf = strconv.AppendUint(buf, %s, 10)", convert("uint64", "key"+suffix, key.Declared))
+			e.writeLine(indent+2, `buf = append(buf, '"')`)
+		}
+		e.writeLine(indent+2, "buf = append(buf, ':')")
+		e.encode(fmt.Sprintf("%s[key%s]", v, suffix), r.Expr.Elem, false, indent+2, depth+1)
+		e.writeLine(indent+1, "}")
+		e.writeLine(indent+1, "buf = append(buf, '}')")
+		e.writeLine(indent, "}")
+	case jsonPointer:
+		e.writeLine(indent, "if %s == nil {", v)
+		e.writeLine(indent+1, `buf = append(buf, "null"...)`)
+		e.writeLine(indent, "} else {")
+		e.encode(deref(v, e.codec.resolve(r.Expr.Elem).Kind), r.Expr.Elem, quoted, indent+1, depth+1)
+		e.writeLine(indent, "}")
+	case jsonModel:
+		e.usesErr = true
+		e.writeLine(indent, "if buf, err = %s.%s(buf); err != nil {", v, _methodAppendJSON)
+		e.writeLine(indent+1, "return nil, err")
+		e.writeLine(indent, "}")
+	default:
+		e.usesErr = true
+		e.writeLine(indent, "if buf, err = appendJSONValue(buf, %s); err != nil {", v)
+		e.writeLine(indent+1, "return nil, err")
+		e.writeLine(indent, "}")
+	}
+}
+
+// jsonDecoder writes the statements decoding the next JSON value of the scanner 'scan'.
+type jsonDecoder struct {
+	codec jsonCodec
+	buf   *strings.Builder
+}
+
+func (d *jsonDecoder) writeLine(indent int, format string, a ...any) {
+	d.buf.WriteString(strings.Repeat("\t", indent) + fmt.Sprintf(format, a...) + "\n")
+}
+
+// literal returns the scanner method decoding the literal of the kind and the type of its result.
+func (d *jsonDecoder) literal(r jsonType, quoted bool) (call string, result string) {
+	switch r.Kind {
+	case jsonString:
+		return fmt.Sprintf("scan.decodeString(%t)", quoted), "string"
+	case jsonBool:
+		return fmt.Sprintf("scan.decodeBool(%t)", quoted), "bool"
+	case jsonInt:
+		return fmt.Sprintf("scan.decodeInt(\"int%d\", %d, %t)", r.Bits, r.Bits, quoted), "int64"
+	case jsonUint:
+		return fmt.Sprintf("scan.decodeUint(\"uint%d\", %d, %t)", r.Bits, r.Bits, quoted), "uint64"
+	case jsonFloat:
+		return fmt.Sprintf("scan.decodeFloat(\"float%d\", %d, %t)", r.Bits, r.Bits, quoted), "float64"
+	}
+
+	return "", ""
+}
+
+// decode writes the statements decoding the JSON value into the addressable x of type t.
+func (d *jsonDecoder) decode(x string, t *typeExpr, quoted bool, indent, depth int) {
+	var (
+		r      = d.codec.resolve(t)
+		suffix = depthSuffix(depth)
+		check  = func(indent int, call string) {
+			d.writeLine(indent, "if err := %s; err != nil {", call)
+			d.writeLine(indent+1, "return err")
+			d.writeLine(indent, "}")
+		}
+		null = func(indent int) {
+			d.writeLine(indent, "case 'n':")
+			check(indent+1, `scan.literal("null")`)
+		}
+		more = func(indent int, end byte) {
+			d.writeLine(indent, "if more, err := scan.more('%c', index%s); err != nil {", end, suffix)
+			d.writeLine(indent+1, "return err")
+			d.writeLine(indent, "} else if !more {")
+			d.writeLine(indent+1, "break")
+			d.writeLine(indent, "}")
+			d.writeLine(indent, "")
+		}
+	)
+
+	switch r.Kind {
+	case jsonString, jsonBool, jsonInt, jsonUint, jsonFloat:
+		call, result := d.literal(r, quoted)
+		d.writeLine(indent, "if value, null, err := %s; err != nil {", call)
+		d.writeLine(indent+1, "return err")
+		d.writeLine(indent, "} else if !null {")
+		d.writeLine(indent+1, "%s = %s", x, convert(r.Declared, "value", result))
+		d.writeLine(indent, "}")
+	case jsonPointer:
+		elem := d.codec.resolve(r.Expr.Elem)
+		if call, result := d.literal(elem, quoted); quoted && len(call) != 0 {
+			// the literal 'null' quoted by the ',string' option sets the pointer to nil
+			d.writeLine(indent, "if value, null, err := %s; err != nil {", call)
+			d.writeLine(indent+1, "return err")
+			d.writeLine(indent, "} else if null {")
+			d.writeLine(indent+1, "%s = nil", x)
+			d.writeLine(indent, "} else {")
+			d.writeLine(indent+1, "if %s == nil {", x)
+			d.writeLine(indent+2, "%s = new(%s)", x, r.Expr.Elem.Raw)
+			d.writeLine(indent+1, "}")
+			d.writeLine(indent+1, "*%s = %s", x, convert(elem.Declared, "value", result))
+			d.writeLine(indent, "}")
+			return
+		}
+
+		d.writeLine(indent, "if scan.peek() == 'n' {")
+		check(indent+1, `scan.literal("null")`)
+		d.writeLine(indent+1, "%s = nil", x)
+		d.writeLine(indent, "} else {")
+		d.writeLine(indent+1, "if %s == nil {", x)
+		d.writeLine(indent+2, "%s = new(%s)", x, r.Expr.Elem.Raw)
+		d.writeLine(indent+1, "}")
+		d.decode(deref(x, elem.Kind), r.Expr.Elem, false, indent+1, depth+1)
+		d.writeLine(indent, "}")
+	case jsonBytes, jsonSlice:
+		d.writeLine(indent, "switch scan.peek() {")
+		null(indent)
+		d.writeLine(indent+1, "%s = nil", x)
+		d.writeLine(indent, "case '[':")
+		d.writeLine(indent+1, "scan.pos++")
+		d.writeLine(indent+1, "index%s := 0", suffix)
+		d.writeLine(indent+1, "for ; ; index%s++ {", suffix)
+		more(indent+2, ']')
+		d.writeLine(indent+2, "if index%s == len(%s) {", suffix, x)
+		d.writeLine(indent+3, "if index%s == cap(%s) {", suffix, x)
+		d.writeLine(indent+4, "%s = append(%s, make(%s, 1)...)", x, x, r.Declared)
+		d.writeLine(indent+3, "} else {")
+		d.writeLine(indent+4, "%s = %s[:index%s+1]", x, x, suffix)
+		d.writeLine(indent+3, "}")
+		d.writeLine(indent+2, "}")
+		d.writeLine(indent+2, "")
+		d.decode(fmt.Sprintf("%s[index%s]", x, suffix), r.Expr.Elem, false, indent+2, depth+1)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent+1, "")
+		d.writeLine(indent+1, "if index%s == 0 {", suffix)
+		d.writeLine(indent+2, "%s = %s{}", x, r.Declared)
+		d.writeLine(indent+1, "} else {")
+		d.writeLine(indent+2, "%s = %s[:index%s]", x, x, suffix)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent, "default:")
+		if r.Kind == jsonBytes {
+			d.writeLine(indent+1, "value, err := scan.decodeBytes()")
+			d.writeLine(indent+1, "if err != nil {")
+			d.writeLine(indent+2, "return err")
+			d.writeLine(indent+1, "}")
+			d.writeLine(indent+1, "")
+			d.writeLine(indent+1, "%s = %s", x, convert(r.Declared, "value", "[]byte"))
+		} else {
+			d.writeLine(indent+1, "return scan.typeError(%q)", r.Declared)
+		}
+		d.writeLine(indent, "}")
+	case jsonArray:
+		d.writeLine(indent, "switch scan.peek() {")
+		null(indent)
+		d.writeLine(indent, "case '[':")
+		d.writeLine(indent+1, "scan.pos++")
+		d.writeLine(indent+1, "index%s := 0", suffix)
+		d.writeLine(indent+1, "for ; ; index%s++ {", suffix)
+		more(indent+2, ']')
+		d.writeLine(indent+2, "if index%s >= len(%s) {", suffix, x)
+		check(indent+3, "scan.skip()")
+		d.writeLine(indent+3, "continue")
+		d.writeLine(indent+2, "}")
+		d.writeLine(indent+2, "")
+		d.decode(fmt.Sprintf("%s[index%s]", x, suffix), r.Expr.Elem, false, indent+2, depth+1)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent+1, "")
+		zero, ok := zeroValue(d.codec.models, r.Expr.Elem)
+		if !ok {
+			zero = "*new(" + r.Expr.Elem.Raw + ")"
+		}
+		d.writeLine(indent+1, "for ; index%s < len(%s); index%s++ {", suffix, x, suffix)
+		d.writeLine(indent+2, "%s[index%s] = %s", x, suffix, zero)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent, "default:")
+		d.writeLine(indent+1, "return scan.typeError(%q)", r.Declared)
+		d.writeLine(indent, "}")
+	case jsonMap:
+		key := d.codec.resolve(r.Expr.Key)
+		d.writeLine(indent, "switch scan.peek() {")
+		null(indent)
+		d.writeLine(indent+1, "%s = nil", x)
+		d.writeLine(indent, "case '{':")
+		d.writeLine(indent+1, "scan.pos++")
+		d.writeLine(indent+1, "if %s == nil {", x)
+		d.writeLine(indent+2, "%s = make(%s)", x, r.Declared)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent+1, "")
+		d.writeLine(indent+1, "for index%s := 0; ; index%s++ {", suffix, suffix)
+		more(indent+2, '}')
+		d.writeLine(indent+2, "key%s, err := scan.readKey()", suffix)
+		d.writeLine(indent+2, "if err != nil {")
+		d.writeLine(indent+3, "return err")
+		d.writeLine(indent+2, "}")
+		d.writeLine(indent+2, "")
+		d.writeLine(indent+2, "var elem%s %s", suffix, r.Expr.Elem.Raw)
+		d.decode("elem"+suffix, r.Expr.Elem, false, indent+2, depth+1)
+		d.writeLine(indent+2, "")
+		mapKey := fmt.Sprintf("string(key%s)", suffix)
+		switch key.Kind {
+		case jsonString:
+			mapKey = convert(key.Declared, mapKey, "string")
+		case jsonInt, jsonUint:
+			parse, result := "strconv.ParseInt", "int64"
+			if key.Kind == jsonUint {
+				parse, result = "strconv.ParseUint", "uint64"
+			}
+
+			d.writeLine(indent+2, "number%s, err := %s(%s, 10, %d)", suffix, parse, mapKey, key.Bits)
+			d.writeLine(indent+2, "if err != nil {")
+			d.writeLine(indent+3, "return err")
+			d.writeLine(indent+2, "}")
+			d.writeLine(indent+2, "")
+			mapKey = convert(key.Declared, "number"+suffix, result)
+		}
+		d.writeLine(indent+2, "%s[%s] = elem%s", x, mapKey, suffix)
+		d.writeLine(indent+1, "}")
+		d.writeLine(indent, "default:")
+		d.writeLine(indent+1, "return scan.typeError(%q)", r.Declared)
+		d.writeLine(indent, "}")
+	case jsonModel:
+		check(indent, fmt.Sprintf("%s.%s(scan)", x, _methodDecodeJSON))
+	default:
+		check(indent, fmt.Sprintf("scan.decodeValue(&%s)", x))
+	}
+}
+
+// convert returns the conversion of v from the type to the target type.
+func convert(target, v, from string) string {
+	if target == from {
+		return v
+	}
+
+	return target + "(" + v + ")"
+}
+
+// deref returns the expression of the value pointed by the pointer p, the models are accessed
+// through the pointer directly.
+func deref(p string, elem jsonKind) string {
+	switch elem {
+	case jsonModel:
+		return p
+	case jsonString, jsonBool, jsonInt, jsonUint, jsonFloat, jsonPointer:
+		return "*" + p
+	}
+
+	return "(*" + p + ")"
+}
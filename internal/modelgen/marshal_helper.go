@@ -0,0 +1,664 @@
+package modelgen
+
+import "github.com/yanun0323/gox/internal/merge"
+
+// _jsonHelpers is the functions shared by the generated JSON methods, they're declared once in the
+// destination package.
+var _jsonHelpers = []merge.Scope{
+	{Key: "appendJSONString", Text: `// appendJSONString appends the JSON string of s like encoding/json.
+func appendJSONString(buf []byte, s string, escapeHTML bool) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, ` + "`\\ufffd`" + `...)
+			i += size
+			start = i
+			continue
+		}
+
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+`},
+	{Key: "appendJSONFloat", Text: `// appendJSONFloat appends the JSON number of f like encoding/json, it returns an error when f is
+// NaN or infinity.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+
+	return buf, nil
+}
+`},
+	{Key: "appendJSONValue", Text: `// appendJSONValue appends the JSON encoding of the value encoded by encoding/json.
+func appendJSONValue(buf []byte, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, data...), nil
+}
+`},
+	{Key: "jsonStringKeys", Text: `// jsonStringKeys returns the sorted keys of the map.
+func jsonStringKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a] < keys[b]
+	})
+
+	return keys
+}
+`},
+	{Key: "jsonIntKeys", Text: `// jsonIntKeys returns the keys of the map sorted by their JSON strings.
+func jsonIntKeys[K ~int | ~int8 | ~int16 | ~int32 | ~int64, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return strconv.FormatInt(int64(keys[a]), 10) < strconv.FormatInt(int64(keys[b]), 10)
+	})
+
+	return keys
+}
+`},
+	{Key: "jsonUintKeys", Text: `// jsonUintKeys returns the keys of the map sorted by their JSON strings.
+func jsonUintKeys[K ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return strconv.FormatUint(uint64(keys[a]), 10) < strconv.FormatUint(uint64(keys[b]), 10)
+	})
+
+	return keys
+}
+`},
+	{Key: "jsonScanner", Text: `// jsonScanner decodes the JSON values of the data in place for the generated UnmarshalJSON
+// methods, the syntax is checked along the way like encoding/json.
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+`},
+	{Key: "jsonScanner.peek", Text: `// peek skips the spaces and returns the next byte, it returns 0 at the end of the data.
+func (s *jsonScanner) peek() byte {
+	for ; s.pos < len(s.data); s.pos++ {
+		switch c := s.data[s.pos]; c {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return c
+		}
+	}
+
+	return 0
+}
+`},
+	{Key: "jsonScanner.syntaxError", Text: `// syntaxError returns the error of the unexpected byte at the position.
+func (s *jsonScanner) syntaxError() error {
+	if s.pos >= len(s.data) {
+		return errors.New("unexpected end of JSON input")
+	}
+
+	return fmt.Errorf("json: invalid character %q at offset %d", s.data[s.pos], s.pos)
+}
+`},
+	{Key: "jsonScanner.typeError", Text: `// typeError returns the error of decoding the next JSON value into the type.
+func (s *jsonScanner) typeError(typ string) error {
+	value := "number"
+	switch c := s.peek(); c {
+	case 'n':
+		value = "null"
+	case 't', 'f':
+		value = "bool"
+	case '"':
+		value = "string"
+	case '[':
+		value = "array"
+	case '{':
+		value = "object"
+	default:
+		if c != '-' && (c < '0' || c > '9') {
+			return s.syntaxError()
+		}
+	}
+
+	return fmt.Errorf("json: cannot unmarshal %s into Go value of type %s", value, typ)
+}
+`},
+	{Key: "jsonScanner.end", Text: `// end returns an error when anything but the spaces follows the top-level value.
+func (s *jsonScanner) end() error {
+	if s.peek(); s.pos < len(s.data) {
+		return fmt.Errorf("json: invalid character %q after top-level value", s.data[s.pos])
+	}
+
+	return nil
+}
+`},
+	{Key: "jsonScanner.literal", Text: `// literal consumes the literal, e.g. null.
+func (s *jsonScanner) literal(lit string) error {
+	s.peek()
+	for i := 0; i < len(lit); i++ {
+		if s.pos >= len(s.data) || s.data[s.pos] != lit[i] {
+			return s.syntaxError()
+		}
+
+		s.pos++
+	}
+
+	return nil
+}
+`},
+	{Key: "jsonScanner.more", Text: `// more reports whether the array or the object has the next element, it consumes the comma
+// before the element or the closing delimiter.
+func (s *jsonScanner) more(end byte, index int) (bool, error) {
+	c := s.peek()
+	if c == end {
+		s.pos++
+		return false, nil
+	}
+
+	if index > 0 {
+		if c != ',' {
+			return false, s.syntaxError()
+		}
+
+		s.pos++
+	}
+
+	return true, nil
+}
+`},
+	{Key: "jsonScanner.scanString", Text: `// scanString consumes the JSON string and returns its content, the content aliases the data
+// unless it's unescaped or the invalid UTF-8 is replaced.
+func (s *jsonScanner) scanString() ([]byte, error) {
+	if s.peek() != '"' {
+		return nil, s.syntaxError()
+	}
+
+	start := s.pos + 1
+	for i := start; i < len(s.data); {
+		switch c := s.data[i]; {
+		case c == '"':
+			s.pos = i + 1
+			return s.data[start:i], nil
+		case c == '\\':
+			return s.unquote(start)
+		case c < ' ':
+			s.pos = i
+			return nil, s.syntaxError()
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRune(s.data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return s.unquote(start)
+			}
+
+			i += size
+		}
+	}
+
+	s.pos = len(s.data)
+	return nil, s.syntaxError()
+}
+`},
+	{Key: "jsonScanner.unquote", Text: `// unquote returns the unescaped content of the JSON string starting at the position like
+// encoding/json, the invalid UTF-8 and the invalid surrogates are replaced by U+FFFD.
+func (s *jsonScanner) unquote(start int) ([]byte, error) {
+	buf := make([]byte, 0, 2*utf8.UTFMax)
+	for s.pos = start; s.pos < len(s.data); {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return buf, nil
+		case c == '\\':
+			if s.pos+1 >= len(s.data) {
+				s.pos = len(s.data)
+				return nil, s.syntaxError()
+			}
+
+			s.pos++
+			switch c := s.data[s.pos]; c {
+			case '"', '\\', '/':
+				buf = append(buf, c)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r, ok := s.hex4(s.pos + 1)
+				if !ok {
+					return nil, s.syntaxError()
+				}
+
+				s.pos += 4
+				if utf16.IsSurrogate(r) {
+					// a valid pair is consumed, otherwise the surrogate is replaced
+					pair := utf8.RuneError
+					if s.pos+6 < len(s.data) && s.data[s.pos+1] == '\\' && s.data[s.pos+2] == 'u' {
+						if low, ok := s.hex4(s.pos + 3); ok {
+							if pair = utf16.DecodeRune(r, low); pair != utf8.RuneError {
+								s.pos += 6
+							}
+						}
+					}
+
+					r = pair
+				}
+
+				buf = utf8.AppendRune(buf, r)
+			default:
+				return nil, s.syntaxError()
+			}
+
+			s.pos++
+		case c < ' ':
+			return nil, s.syntaxError()
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			s.pos++
+		default:
+			r, size := utf8.DecodeRune(s.data[s.pos:])
+			buf = utf8.AppendRune(buf, r)
+			s.pos += size
+		}
+	}
+
+	return nil, s.syntaxError()
+}
+`},
+	{Key: "jsonScanner.hex4", Text: `// hex4 returns the rune of the 4 hex digits at the index.
+func (s *jsonScanner) hex4(index int) (rune, bool) {
+	if index+4 > len(s.data) {
+		return 0, false
+	}
+
+	var r rune
+	for _, c := range s.data[index : index+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			r = r<<4 | rune(c-'0')
+		case 'a' <= c && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			r = r<<4 | rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+
+	return r, true
+}
+`},
+	{Key: "jsonScanner.readString", Text: `// readString consumes the JSON string and returns its content.
+func (s *jsonScanner) readString() (string, error) {
+	b, err := s.scanString()
+	return string(b), err
+}
+`},
+	{Key: "jsonScanner.readKey", Text: `// readKey consumes the key of the object and the colon, the key aliases the data unless it's
+// unescaped.
+func (s *jsonScanner) readKey() ([]byte, error) {
+	key, err := s.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.peek() != ':' {
+		return nil, s.syntaxError()
+	}
+
+	s.pos++
+	return key, nil
+}
+`},
+	{Key: "jsonScanner.readNumber", Text: `// readNumber consumes the JSON number and returns its literal.
+func (s *jsonScanner) readNumber() ([]byte, error) {
+	s.peek()
+	start := s.pos
+	digits := func() int {
+		n := 0
+		for ; s.pos < len(s.data) && '0' <= s.data[s.pos] && s.data[s.pos] <= '9'; s.pos++ {
+			n++
+		}
+
+		return n
+	}
+
+	if s.pos < len(s.data) && s.data[s.pos] == '-' {
+		s.pos++
+	}
+
+	switch {
+	case s.pos < len(s.data) && s.data[s.pos] == '0':
+		s.pos++
+	case digits() == 0:
+		return nil, s.syntaxError()
+	}
+
+	if s.pos < len(s.data) && s.data[s.pos] == '.' {
+		s.pos++
+		if digits() == 0 {
+			return nil, s.syntaxError()
+		}
+	}
+
+	if s.pos < len(s.data) && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.data) && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+
+		if digits() == 0 {
+			return nil, s.syntaxError()
+		}
+	}
+
+	return s.data[start:s.pos], nil
+}
+`},
+	{Key: "jsonScanner.skip", Text: `// skip consumes the JSON value.
+func (s *jsonScanner) skip() error {
+	switch s.peek() {
+	case '{', '[':
+		end := byte('}')
+		if s.data[s.pos] == '[' {
+			end = ']'
+		}
+
+		s.pos++
+		for index := 0; ; index++ {
+			if more, err := s.more(end, index); err != nil {
+				return err
+			} else if !more {
+				return nil
+			}
+
+			if end == '}' {
+				if _, err := s.readKey(); err != nil {
+					return err
+				}
+			}
+
+			if err := s.skip(); err != nil {
+				return err
+			}
+		}
+	case '"':
+		_, err := s.scanString()
+		return err
+	case 't':
+		return s.literal("true")
+	case 'f':
+		return s.literal("false")
+	case 'n':
+		return s.literal("null")
+	}
+
+	_, err := s.readNumber()
+	return err
+}
+`},
+	{Key: "jsonScanner.quoted", Text: `// quoted returns the literal quoted in the JSON string by the ',string' option, it reports true
+// when the literal is null.
+func (s *jsonScanner) quoted(typ string) (string, bool, error) {
+	switch s.peek() {
+	case 'n':
+		return "", true, s.literal("null")
+	case '"':
+		v, err := s.readString()
+		switch {
+		case err != nil:
+			return "", false, err
+		case v == "null":
+			return "", true, nil
+		case len(v) == 0 || v[0] == 'n':
+			return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into %s", v, typ)
+		}
+
+		return v, false, nil
+	}
+
+	return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal unquoted value into %s", typ)
+}
+`},
+	{Key: "jsonScanner.number", Text: `// number returns the number literal of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) number(typ string, quoted bool) ([]byte, bool, error) {
+	if quoted {
+		v, null, err := s.quoted(typ)
+		return []byte(v), null, err
+	}
+
+	switch c := s.peek(); {
+	case c == 'n':
+		return nil, true, s.literal("null")
+	case c != '-' && (c < '0' || c > '9'):
+		return nil, false, s.typeError(typ)
+	}
+
+	number, err := s.readNumber()
+	return number, false, err
+}
+`},
+	{Key: "jsonScanner.decodeString", Text: `// decodeString returns the string of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeString(quoted bool) (string, bool, error) {
+	if !quoted {
+		switch s.peek() {
+		case 'n':
+			return "", true, s.literal("null")
+		case '"':
+			v, err := s.readString()
+			return v, false, err
+		}
+
+		return "", false, s.typeError("string")
+	}
+
+	v, null, err := s.quoted("string")
+	if err != nil || null {
+		return "", null, err
+	}
+
+	// the quoted literal must be exactly one JSON string
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		inner := jsonScanner{data: []byte(v)}
+		if unquoted, err := inner.readString(); err == nil && inner.pos == len(inner.data) {
+			return unquoted, false, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into string", v)
+}
+`},
+	{Key: "jsonScanner.decodeBool", Text: `// decodeBool returns the boolean of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeBool(quoted bool) (bool, bool, error) {
+	if !quoted {
+		switch s.peek() {
+		case 'n':
+			return false, true, s.literal("null")
+		case 't':
+			return true, false, s.literal("true")
+		case 'f':
+			return false, false, s.literal("false")
+		}
+
+		return false, false, s.typeError("bool")
+	}
+
+	v, null, err := s.quoted("bool")
+	if err != nil || null {
+		return false, null, err
+	}
+
+	switch v {
+	case "true":
+		return true, false, nil
+	case "false":
+		return false, false, nil
+	}
+
+	return false, false, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into bool", v)
+}
+`},
+	{Key: "jsonScanner.decodeInt", Text: `// decodeInt returns the integer of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeInt(typ string, bits int, quoted bool) (int64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseInt(string(number), 10, bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+`},
+	{Key: "jsonScanner.decodeUint", Text: `// decodeUint returns the unsigned integer of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeUint(typ string, bits int, quoted bool) (uint64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseUint(string(number), 10, bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+`},
+	{Key: "jsonScanner.decodeFloat", Text: `// decodeFloat returns the float of the JSON value, it reports true when the value is null.
+func (s *jsonScanner) decodeFloat(typ string, bits int, quoted bool) (float64, bool, error) {
+	number, null, err := s.number(typ, quoted)
+	if err != nil || null {
+		return 0, null, err
+	}
+
+	v, err := strconv.ParseFloat(string(number), bits)
+	if err != nil {
+		return 0, false, fmt.Errorf("json: cannot unmarshal number %s into Go value of type %s", number, typ)
+	}
+
+	return v, false, nil
+}
+`},
+	{Key: "jsonScanner.decodeBytes", Text: `// decodeBytes returns the bytes decoded from the base64 JSON string.
+func (s *jsonScanner) decodeBytes() ([]byte, error) {
+	if s.peek() != '"' {
+		return nil, s.typeError("[]byte")
+	}
+
+	b, err := s.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+	n, err := base64.StdEncoding.Decode(result, b)
+	return result[:n], err
+}
+`},
+	{Key: "jsonScanner.decodeValue", Text: `// decodeValue decodes the JSON value into v by encoding/json.
+func (s *jsonScanner) decodeValue(v any) error {
+	s.peek()
+	start := s.pos
+	if err := s.skip(); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(s.data[start:s.pos], v)
+}
+`},
+	{Key: "foldJSONField", Text: `// foldJSONField returns the index of the field matching the key case-insensitively like
+// encoding/json, it returns -1 if no field matches.
+func foldJSONField(key []byte, fields ...string) int {
+	for i, field := range fields {
+		if strings.EqualFold(string(key), field) {
+			return i
+		}
+	}
+
+	return -1
+}
+`},
+}
@@ -0,0 +1,49 @@
+package modelgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONCodecFields(t *testing.T) {
+	ms := &modelSet{
+		byName:              map[string]*model{},
+		underlying:          map[string]string{},
+		methods:             map[string]string{},
+		foreignDeclarations: map[string]bool{},
+	}
+
+	for name, text := range map[string]string{
+		"Outer": "ID int64 `json:\"id\"`\nSkip string `json:\"-\"`\nDash string `json:\"-,\"`\nhidden int\nBase\n*Meta\nA\nB",
+		"Base":  "Name string `json:\"name\"`\nCreated int64",
+		"Meta":  "Name string\nID string `json:\"id\"`\nVersion int `json:\"version,string,omitempty\"`",
+		"A":     "Same string",
+		"B":     "Same string",
+	} {
+		fields, err := parseStructFieldsFromText("struct {\n" + text + "\n}")
+		if err != nil {
+			t.Fatalf("parse fields of %s, err: %+v", name, err)
+		}
+
+		ms.add(&model{Name: name, Fields: fields})
+	}
+
+	outer, _ := ms.Lookup("Outer")
+	fields, err := jsonCodec{models: ms}.fields(outer)
+	if err != nil {
+		t.Fatalf("fields, err: %+v", err)
+	}
+
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+
+	if got := strings.Join(names, ","); got != "id,-,name,Created,Name,version" {
+		t.Fatalf("fields mismatch: %s", got)
+	}
+
+	if version := fields[5]; !version.Quoted || !version.OmitEmpty || len(version.Embedded) != 1 || !version.Embedded[0].Pointer {
+		t.Fatalf("promoted field mismatch: %+v", version)
+	}
+}
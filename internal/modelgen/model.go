@@ -0,0 +1,645 @@
+package modelgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/goast/kind"
+	"github.com/yanun0323/goast/scope"
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+// saveGeneratedScopes saves the generated scopes into the destination of the flags.
+func saveGeneratedScopes(importPaths []string, generated []merge.Scope) error {
+	des := merge.Destination{File: *_destination, Package: *_package, Replace: *_replace, Record: helper.record}
+	return des.Save(importPaths, generated)
+}
+
+// genStructString generates the struct declaration of the fields, the name of the generic struct
+// includes its type parameter declaration.
+func genStructString(name string, doc []string, fields []structField) string {
+	buf := strings.Builder{}
+	for _, line := range doc {
+		buf.WriteString("// " + line + "\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	for _, field := range fields {
+		for _, line := range field.Doc {
+			buf.WriteString("\t// " + line + "\n")
+		}
+
+		buf.WriteString("\t")
+		if !field.Embedded {
+			buf.WriteString(field.Name + " ")
+		}
+
+		buf.WriteString(field.Type)
+		if len(field.Tag) != 0 {
+			buf.WriteString(" `" + field.Tag + "`")
+		}
+
+		if len(field.Comment) != 0 {
+			buf.WriteString(" // " + field.Comment)
+		}
+
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+// model is a struct handled by the generators.
+type model struct {
+	// Name is the struct name in the destination.
+	Name   string
+	Doc    []string
+	Fields []structField
+	// TypeParams is the type parameters of the generic struct.
+	TypeParams []typeParam
+	// Declare reports whether the struct declaration is generated into the destination,
+	// it's false when the struct is already declared in the destination package.
+	Declare bool
+}
+
+// modelSet is the target struct and its relative structs handled by the generators.
+type modelSet struct {
+	Target      *model
+	Models      []*model
+	ImportPaths []string
+	// NamedTypes is the non-struct types declared into the destination.
+	NamedTypes []string
+	// Table is the table of the target struct and Bindings binds its columns to the fields,
+	// they're only resolved with -repository.
+	Table    *sqlTable
+	Bindings []sqlBinding
+
+	// flattened is the fields of the target flattened with -flatten, source is the type of the
+	// source struct they're flattened from.
+	flattened []flatField
+	source    string
+
+	// constraints is the interfaces constraining the type parameters declared into the destination.
+	constraints []merge.Scope
+
+	// qualifier is the source package qualifier of the types copied into another folder.
+	qualifier string
+	// sources is the relative structs kept in the source package when the types are qualified,
+	// they're keyed by the qualified names and their field types are qualified as well.
+	sources    map[string]*model
+	byName     map[string]*model
+	underlying map[string]string
+	// methods is the declared methods visible from the destination, it maps 'Type.Method'
+	// to the result type of the method.
+	methods map[string]string
+	// foreignDeclarations is the types and methods declared outside the destination file.
+	foreignDeclarations map[string]bool
+}
+
+// newModelSet collects the target struct and its relative structs.
+//
+// The relative structs are declared into the destination with -relative when the destination is
+// in another folder, otherwise the types of the target struct are qualified by the source package.
+func newModelSet(ast goast.Ast, targetScope goast.Scope, structName, pkg, curDir string) (*modelSet, error) {
+	ms := &modelSet{
+		sources:             map[string]*model{},
+		byName:              map[string]*model{},
+		underlying:          map[string]string{},
+		methods:             map[string]string{},
+		foreignDeclarations: map[string]bool{},
+	}
+
+	var (
+		isSameFolder                        = isDestinationSameFolderToSource(curDir)
+		relativeScopes, relativeScopesNames = findRelativeScopes(ast, targetScope)
+		qualifier                           string
+	)
+
+	if !isSameFolder && !*_relative {
+		qualifier = pkg + "."
+		ms.qualifier = qualifier
+		if addPackageNameInFrontOfParamType(targetScope, pkg) {
+			alias, importPath, err := helper.GetSourceImportString()
+			if err != nil {
+				return nil, fmt.Errorf("get source import string, err: %w", err)
+			}
+
+			if len(alias) != 0 {
+				importPath = alias + " " + importPath
+			}
+
+			ms.addImport(importPath)
+		}
+	}
+
+	for name, underlying := range findUnderlyingTypes(ast) {
+		if len(qualifier) != 0 {
+			ms.underlying[qualifier+name] = parseTypeExpr(underlying).Qualify(pkg)
+			continue
+		}
+
+		ms.underlying[name] = underlying
+	}
+
+	// the relative structs declared into another folder don't have the methods of the source structs
+	if isSameFolder || !*_relative {
+		if err := ms.addDeclarations(curDir, qualifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isSameFolder {
+		if err := ms.addDeclarations(filepath.Dir(helper.AbsPath(*_destination)), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := newModel(ast, targetScope, *_name, !isSameFolder || *_name != structName)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.Target = target
+	ms.add(target)
+
+	if !isSameFolder && !*_relative {
+		for _, name := range relativeScopesNames {
+			m, err := newModel(ast, relativeScopes[name], qualifier+name, false)
+			if err != nil {
+				return nil, err
+			}
+
+			ms.addSource(m, pkg)
+		}
+
+		return ms, nil
+	}
+
+	for _, name := range relativeScopesNames {
+		m, err := newModel(ast, relativeScopes[name], name, !isSameFolder)
+		if err != nil {
+			return nil, err
+		}
+
+		ms.add(m)
+	}
+
+	if !isSameFolder {
+		ms.addNamedTypes()
+		ms.addConstraints(ast)
+	}
+
+	return ms, nil
+}
+
+// addNamedTypes collects the non-struct types referenced by the models, they are declared into the
+// destination along with the relative structs.
+func (ms *modelSet) addNamedTypes() {
+	var (
+		visited = map[string]bool{}
+		visit   func(t *typeExpr)
+	)
+
+	visit = func(t *typeExpr) {
+		for _, name := range t.Idents() {
+			underlying, ok := ms.underlying[name]
+			if !ok || visited[name] {
+				continue
+			}
+
+			visited[name] = true
+			ms.NamedTypes = append(ms.NamedTypes, name)
+			visit(parseTypeExpr(underlying))
+		}
+	}
+
+	for _, m := range ms.Models {
+		for _, f := range m.Fields {
+			visit(parseTypeExpr(f.Type))
+		}
+
+		for _, term := range m.constraintTerms() {
+			visit(term)
+		}
+	}
+}
+
+// addConstraints collects the interfaces constraining the type parameters of the models, they are
+// declared into the destination along with the relative structs.
+func (ms *modelSet) addConstraints(ast goast.Ast) {
+	names := map[string]bool{}
+	for _, m := range ms.Models {
+		for _, term := range m.constraintTerms() {
+			for _, name := range term.Idents() {
+				names[name] = true
+			}
+		}
+	}
+
+	ast.IterScope(func(sc goast.Scope) bool {
+		name, ok := sc.GetInterfaceName()
+		if !ok || !names[name] {
+			return true
+		}
+
+		text := strings.Builder{}
+		sc.Node().IterNext(func(n *goast.Node) bool {
+			text.WriteString(n.Text())
+			return true
+		})
+
+		ms.constraints = append(ms.constraints, merge.Scope{Key: name, Text: strings.TrimSpace(text.String()) + "\n"})
+		return true
+	})
+}
+
+func newModel(ast goast.Ast, sc goast.Scope, name string, declare bool) (*model, error) {
+	fields, err := parseStructFields(sc)
+	if err != nil {
+		return nil, fmt.Errorf("parse fields of %s, err: %w", name, err)
+	}
+
+	return &model{
+		Name:       name,
+		Doc:        findScopeDoc(ast, sc),
+		Fields:     fields,
+		TypeParams: parseTypeParams(sc),
+		Declare:    declare,
+	}, nil
+}
+
+func (ms *modelSet) add(m *model) {
+	ms.Models = append(ms.Models, m)
+	ms.byName[m.Name] = m
+}
+
+// addSource adds the relative struct kept in the source package, the field types are qualified
+// by the package except the type parameters of the struct.
+func (ms *modelSet) addSource(m *model, pkg string) {
+	params := make(map[string]string, len(m.TypeParams))
+	for _, p := range m.TypeParams {
+		params[pkg+"."+p.Name] = p.Name
+	}
+
+	for i, f := range m.Fields {
+		m.Fields[i].Type = parseTypeExpr(parseTypeExpr(f.Type).Qualify(pkg)).Substitute(params)
+	}
+
+	ms.sources[m.Name] = m
+}
+
+func (ms *modelSet) addImport(importPath string) {
+	for _, p := range ms.ImportPaths {
+		if p == importPath {
+			return
+		}
+	}
+
+	ms.ImportPaths = append(ms.ImportPaths, importPath)
+}
+
+// addDeclarations collects the types and methods declared in the go files of the folder, the types
+// are prefixed with the qualifier.
+func (ms *modelSet) addDeclarations(dir, qualifier string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("read dir %s, err: %w", dir, err)
+	}
+
+	destination := helper.AbsPath(strings.TrimSuffix(*_destination, ".go") + ".go")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file := filepath.Join(dir, name)
+		ast, err := goast.ParseAst(file)
+		if err != nil {
+			return fmt.Errorf("parse ast of %s, err: %w", file, err)
+		}
+
+		isForeign := helper.AbsPath(file) != destination
+		ast.IterScope(func(sc goast.Scope) bool {
+			if sc.Kind() == scope.Type {
+				if name, ok := sc.GetTypeName(); ok && isForeign {
+					ms.foreignDeclarations[qualifier+name] = true
+				}
+
+				return true
+			}
+
+			if sc.Kind() != scope.Func {
+				return true
+			}
+
+			receiver, ok := sc.GetMethodReceiver()
+			if !ok {
+				if name, ok := sc.GetFuncName(); ok && isForeign {
+					ms.foreignDeclarations[qualifier+name] = true
+				}
+
+				return true
+			}
+
+			method, _ := sc.GetMethodName()
+			key := qualifier + helper.TidyString(receiver, '*') + "." + method
+			ms.methods[key] = funcResultType(sc, method)
+			if isForeign {
+				ms.foreignDeclarations[key] = true
+			}
+
+			return true
+		})
+	}
+
+	return nil
+}
+
+// Lookup returns the model of the type name in the destination.
+func (ms *modelSet) Lookup(name string) (*model, bool) {
+	m, ok := ms.byName[name]
+	return m, ok
+}
+
+// Source returns the relative struct of the qualified type name kept in the source package.
+func (ms *modelSet) Source(name string) (*model, bool) {
+	m, ok := ms.sources[name]
+	return m, ok
+}
+
+// Underlying returns the underlying type of the named type which is not a struct.
+func (ms *modelSet) Underlying(name string) (*typeExpr, bool) {
+	underlying, ok := ms.underlying[name]
+	if !ok {
+		return nil, false
+	}
+
+	return parseTypeExpr(underlying), true
+}
+
+// MethodResult returns the result type of the method of the type name, the models
+// are treated as having the methods which are going to be generated.
+func (ms *modelSet) MethodResult(typeName, method string, generated bool) (string, bool) {
+	if result, ok := ms.methods[typeName+"."+method]; ok {
+		return result, true
+	}
+
+	if _, ok := ms.byName[typeName]; ok && generated {
+		return "*" + typeName, true
+	}
+
+	return "", false
+}
+
+// Qualify returns the type text of the source struct field with the local identifiers qualified by
+// the source package when the types aren't copied into the destination.
+func (ms *modelSet) Qualify(typeText string) string {
+	if len(ms.qualifier) == 0 {
+		return typeText
+	}
+
+	return parseTypeExpr(typeText).Qualify(strings.TrimSuffix(ms.qualifier, "."))
+}
+
+// ShouldGenerate reports whether the method of the model should be generated, it's false when
+// the method is already declared outside the destination file.
+func (ms *modelSet) ShouldGenerate(m *model, method string) bool {
+	return !ms.foreignDeclarations[m.Name+"."+method]
+}
+
+// ShouldDeclare reports whether the type or function should be declared into the destination, it's
+// false when it's already declared outside the destination file.
+func (ms *modelSet) ShouldDeclare(name string) bool {
+	return !ms.foreignDeclarations[name]
+}
+
+// checkGenerics returns an error when the generators not supporting the type parameters are enabled
+// for the generic structs.
+func (ms *modelSet) checkGenerics() error {
+	for _, m := range ms.Models {
+		if len(m.TypeParams) == 0 {
+			continue
+		}
+
+		flags := map[string]bool{"diff": *_diff, "validate": *_validate, "marshal": *_marshal}
+		if m == ms.Target {
+			flags["builder"], flags["options"] = *_builder, *_options
+			flags["repository"], flags["flatten"] = *_repository, *_flatten
+		}
+
+		for _, flag := range []string{"diff", "validate", "marshal", "builder", "options", "repository", "flatten"} {
+			if flags[flag] {
+				return fmt.Errorf("-%s doesn't support the generic struct %s", flag, m.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Receiver returns the receiver name of the model methods.
+func (m *model) Receiver() string {
+	return string(helper.FirstLowerCase(m.Name)[0])
+}
+
+// Type returns the type of the model used by its methods, e.g. 'Page[T, K]' for the generic struct.
+func (m *model) Type() string {
+	if len(m.TypeParams) == 0 {
+		return m.Name
+	}
+
+	names := make([]string, 0, len(m.TypeParams))
+	for _, p := range m.TypeParams {
+		names = append(names, p.Name)
+	}
+
+	return m.Name + "[" + strings.Join(names, ", ") + "]"
+}
+
+// Instantiate returns the fields of the model with the type parameters replaced by the type
+// arguments of the instantiated type, e.g. 'Optional[int]'.
+func (m *model) Instantiate(t *typeExpr) []structField {
+	if len(m.TypeParams) == 0 || len(t.Args) != len(m.TypeParams) {
+		return m.Fields
+	}
+
+	mapping := make(map[string]string, len(m.TypeParams))
+	for i, p := range m.TypeParams {
+		mapping[p.Name] = t.Args[i].Raw
+	}
+
+	fields := make([]structField, len(m.Fields))
+	for i, f := range m.Fields {
+		f.Type = parseTypeExpr(f.Type).Substitute(mapping)
+		fields[i] = f
+	}
+
+	return fields
+}
+
+// constraintTerms returns the terms of the type parameter constraints, e.g. 'Number' and 'string'
+// of 'Number | ~string'.
+func (m *model) constraintTerms() []*typeExpr {
+	var terms []*typeExpr
+	for _, p := range m.TypeParams {
+		for _, term := range splitTopLevel(p.Constraint, '|') {
+			terms = append(terms, parseTypeExpr(strings.TrimPrefix(term, "~")))
+		}
+	}
+
+	return terms
+}
+
+// TypeParamsDecl returns the type parameter declaration of the generic struct, e.g. '[T any, K comparable]'.
+func (m *model) TypeParamsDecl() string {
+	if len(m.TypeParams) == 0 {
+		return ""
+	}
+
+	params := make([]string, 0, len(m.TypeParams))
+	for _, p := range m.TypeParams {
+		params = append(params, p.Name+" "+p.Constraint)
+	}
+
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// funcResultType returns the result type text of the method scope.
+func funcResultType(sc goast.Scope, method string) string {
+	signature := strings.Builder{}
+	sc.Node().IterNext(func(n *goast.Node) bool {
+		if n.Kind() == kind.CurlyBracketLeft {
+			return false
+		}
+
+		signature.WriteString(n.Text())
+		return true
+	})
+
+	text := signature.String()
+	start := strings.Index(text, ")")
+	if start < 0 {
+		return ""
+	}
+
+	i := strings.Index(text[start:], method+"(")
+	if i < 0 {
+		return ""
+	}
+
+	end := matchBracket(text, start+i+len(method))
+	return strings.TrimSpace(text[end+1:])
+}
+
+// generateModelAndSave generates the model structs and the methods of the enabled generators.
+func generateModelAndSave(ms *modelSet) error {
+	if err := ms.checkGenerics(); err != nil {
+		return err
+	}
+
+	var generated []merge.Scope
+	for _, m := range ms.Models {
+		if !m.Declare {
+			continue
+		}
+
+		fields := m.Fields
+		if !*_tagged {
+			fields = make([]structField, len(m.Fields))
+			for i, f := range m.Fields {
+				f.Tag = ""
+				fields[i] = f
+			}
+		}
+
+		generated = append(generated, merge.Scope{
+			Key:  m.Name,
+			Text: genStructString(m.Name+m.TypeParamsDecl(), m.Doc, fields),
+		})
+	}
+
+	if *_flatten {
+		generated = append(generated, generateFlattenConversions(ms)...)
+	}
+
+	for _, name := range ms.NamedTypes {
+		generated = append(generated, merge.Scope{
+			Key:  name,
+			Text: fmt.Sprintf("type %s %s\n", name, ms.underlying[name]),
+		})
+	}
+
+	generated = append(generated, ms.constraints...)
+
+	if *_deepcopy {
+		scs, err := generateDeepCopy(ms)
+		if err != nil {
+			return err
+		}
+
+		generated = append(generated, scs...)
+	}
+
+	if *_diff {
+		generated = append(generated, generateEqualAndDiff(ms)...)
+	}
+
+	if *_builder || *_options {
+		generated = append(generated, generateBuilder(ms)...)
+	}
+
+	if *_fields {
+		generated = append(generated, generateFieldConstants(ms)...)
+	}
+
+	if *_getters || *_setters {
+		generated = append(generated, generateAccessors(ms)...)
+	}
+
+	if *_maps {
+		generated = append(generated, generateMaps(ms)...)
+	}
+
+	if *_validate {
+		scs, err := generateValidate(ms)
+		if err != nil {
+			return err
+		}
+
+		generated = append(generated, scs...)
+	}
+
+	if *_marshal {
+		scs, err := generateJSON(ms)
+		if err != nil {
+			return err
+		}
+
+		generated = append(generated, scs...)
+	}
+
+	if *_repository {
+		scs, err := generateRepository(ms)
+		if err != nil {
+			return err
+		}
+
+		generated = append(generated, scs...)
+	}
+
+	if len(generated) == 0 {
+		return errors.New("nothing to generate, the destination is the same struct in the same folder")
+	}
+
+	return saveGeneratedScopes(ms.ImportPaths, generated)
+}
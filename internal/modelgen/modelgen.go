@@ -0,0 +1,547 @@
+package modelgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/goast"
+	"github.com/yanun0323/goast/kind"
+	"github.com/yanun0323/goast/scope"
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/report"
+)
+
+const (
+	_commandName    = "modelgen"
+	_subcommandName = "model"
+)
+
+var _flags = command.NewFlagSet(_commandName)
+
+var (
+	_help          = _flags.Bool("h", false, "show command help")
+	_debug         = _flags.Bool("v", false, "show debug information")
+	_replace       = _flags.Bool("replace", false, "replace all structure and method if there's already a same structure")
+	_relative      = _flags.Bool("relative", false, "target model structure name")
+	_tagged        = _flags.Bool("tagged", false, "keep struct's tags or not")
+	_destination   = _flags.String("destination", "", "target file name to generate model")
+	_package       = _flags.String("package", "", "target model structure name")
+	_name          = _flags.String("name", "", "target model structure name")
+	_type          = _flags.String("type", "", "comma separated target struct names in the package, instead of the struct below the go:generate directive")
+	_function      = _flags.String("function", "", "target model structure name")
+	_format        = _flags.String("format", "", "generate schema instead of go model (jsonschema, openapi, sql)")
+	_dialect       = _flags.String("dialect", "postgres", "sql dialect of -format=sql (postgres, mysql, sqlite)")
+	_table         = _flags.String("table", "", "table name of -format=sql or the only table generated from -source=*.sql")
+	_source        = _flags.String("source", "", "generate go model from the file instead of the struct (*.sql, *.json)")
+	_nullable      = _flags.String("nullable", "pointer", "go type of nullable columns of -source=*.sql (pointer, sql)")
+	_tags          = _flags.String("tags", "gorm,db,json", "struct tags of the fields generated from -source=*.sql")
+	_snapshot      = _flags.String("snapshot", "", "schema snapshot file of -format=sql, generate ALTER statements if the snapshot exists")
+	_deepcopy      = _flags.Bool("deepcopy", false, "generate DeepCopy methods for the target struct and its relative structs")
+	_diff          = _flags.Bool("diff", false, "generate Equal and Diff methods for the target struct and its relative structs")
+	_ignore        = _flags.String("ignore", "", "fields ignored by Equal and Diff, e.g. CreatedAt,Example.UpdatedAt")
+	_builder       = _flags.Bool("builder", false, "generate a fluent builder for the target struct")
+	_options       = _flags.Bool("options", false, "generate functional options for the target struct")
+	_fields        = _flags.Bool("fields", false, "generate field name constants for the target struct and its relative structs")
+	_nameTags      = _flags.String("nametags", "gorm,db,json", "tags deriving the names of -fields and the keys of -maps in order, the gorm tag uses the column setting")
+	_getters       = _flags.Bool("getters", false, "generate nil-safe getters for the target struct and its relative structs")
+	_setters       = _flags.Bool("setters", false, "generate nil-safe setters for the target struct and its relative structs")
+	_validate      = _flags.Bool("validate", false, "generate Validate methods from the validate tags for the target struct and its relative structs")
+	_marshal       = _flags.Bool("marshal", false, "generate reflection-free MarshalJSON, AppendJSON and UnmarshalJSON methods for the target struct and its relative structs")
+	_flatten       = _flags.Bool("flatten", false, "inline the fields of the nested relative structs into the target struct, and generate the conversions between them")
+	_flattenPrefix = _flags.String("flattenprefix", "field", "prefix of the fields inlined by -flatten (field, type, none), e.g. ExtensionKey with field")
+	_maps          = _flags.Bool("maps", false, "generate ToMap and FromMap methods keyed by the names of -nametags for the target struct and its relative structs")
+	_repository    = _flags.Bool("repository", false, "generate a database/sql CRUD repository of the target struct from the gorm tags, the queries follow -dialect and -table")
+	_json          = _flags.Bool("json", false, "print the report of each target struct as a JSON line")
+)
+
+// Usage is a replacement usage function for the flags package.
+func Usage() {
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "%s: generate a model, its methods or schemas from the struct\n", _commandName)
+	fmt.Fprintf(_flags.Output(), "\n")
+	_flags.PrintDefaults()
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "\texample:\n")
+	fmt.Fprintf(_flags.Output(), "\n")
+	fmt.Fprintf(_flags.Output(), "\t//go:generate %s -destination=../../entity/member.go -package=entity -deepcopy\n", _commandName)
+	fmt.Fprintf(_flags.Output(), "\n")
+}
+
+// Command is the model command of gox, it's also installed as modelgen.
+var Command = command.Command{
+	Name:    _subcommandName,
+	Alias:   _commandName,
+	Summary: "generate a model, its methods or schemas from the struct",
+	Run:     run,
+}
+
+func run(args []string) error {
+	if err := helper.setupLog(args); err != nil {
+		return err
+	}
+
+	helper.debugPrint()
+
+	if *_help {
+		_flags.Usage()
+		return nil
+	}
+
+	rep := report.New(_subcommandName, *_json)
+
+	return rep.Finish(generate(rep))
+}
+
+func generate(rep *report.Reporter) error {
+	if err := helper.requireDestination(); err != nil {
+		return err
+	}
+
+	if len(*_source) != 0 {
+		_actions = nil
+		err := generateFromSourceAndSave()
+		rep.Report(targetReport("", report.NewPosition(*_source, 0)), err)
+
+		return err
+	}
+
+	targets, pkg, curDir, err := findTargets()
+	if err != nil {
+		return err
+	}
+
+	if len(targets) > 1 && len(*_name) != 0 {
+		return errors.New("-name can't be used with multiple -type")
+	}
+
+	// the targets are generated one by one, so the models in the same destination are merged
+	name := *_name
+	for _, t := range targets {
+		*_name = name
+		_actions = nil
+		structName, _ := t.scope.GetStructName()
+		err := generateTarget(t.ast, t.scope, pkg, curDir)
+		rep.Report(targetReport(structName, report.TypePosition(curDir, structName)), err)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// targetReport returns the report of the target with the actions made, the destination is the first
+// file written by the target.
+func targetReport(typeName string, source *report.Position) report.Report {
+	rp := report.Report{Type: typeName, Source: source, Destination: helper.AbsPath(*_destination), Actions: _actions}
+	for _, a := range _actions {
+		if a.Kind == gen.ActionCreateFile || a.Kind == gen.ActionUpdateFile {
+			rp.Destination = a.Name
+			break
+		}
+	}
+
+	return rp
+}
+
+// target is a struct to generate from.
+type target struct {
+	ast   goast.Ast
+	scope goast.Scope
+}
+
+// findTargets returns the structs of -type declared in the package folder, or the struct below the
+// go:generate directive.
+func findTargets() (targets []target, pkg string, curDir string, err error) {
+	ast, goLine, pkg, curDir, err := parseAstFromGoGenerator()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	names := helper.SplitList(*_type)
+	if len(names) == 0 {
+		targetScope, err := findTargetStruct(ast, goLine)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		pkgAst, err := parsePackageAst(ast)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return []target{{ast: pkgAst, scope: targetScope}}, pkg, curDir, nil
+	}
+
+	for _, name := range names {
+		file, _, _, err := helper.FindTypeSpec(curDir, name)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		// every target parses its own ast, the types of the ast are qualified by the generation
+		fileAst, err := goast.ParseAst(file)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("parse ast, err: %w", err)
+		}
+
+		targetScope, err := findStructScope(fileAst, name)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		pkgAst, err := parsePackageAst(fileAst)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		targets = append(targets, target{ast: pkgAst, scope: targetScope})
+	}
+
+	return targets, pkg, curDir, nil
+}
+
+// parsePackageAst returns the ast of the go files in the package folder of the file ast, so the
+// relative structs and the named types declared in the other files are found. The scopes of the
+// file come first, the test files are skipped.
+func parsePackageAst(fileAst goast.Ast) (goast.Ast, error) {
+	entries, err := os.ReadDir(filepath.Dir(fileAst.File()))
+	if err != nil {
+		return nil, fmt.Errorf("read dir of %s, err: %w", fileAst.File(), err)
+	}
+
+	scopes := append([]goast.Scope{}, fileAst.Scope()...)
+	for _, entry := range entries {
+		name := entry.Name()
+		file := filepath.Join(filepath.Dir(fileAst.File()), name)
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") || file == filepath.Clean(fileAst.File()) {
+			continue
+		}
+
+		ast, err := goast.ParseAst(file)
+		if err != nil {
+			return nil, fmt.Errorf("parse ast of %s, err: %w", file, err)
+		}
+
+		scopes = append(scopes, ast.Scope()...)
+	}
+
+	result, err := goast.NewAst(scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("new ast of package, err: %w", err)
+	}
+
+	return result, nil
+}
+
+// generateTarget generates the model or the schema from the target struct.
+func generateTarget(ast goast.Ast, targetScope goast.Scope, pkg, curDir string) error {
+	structName, err := findStructNameAndSetImplementName(targetScope)
+	if err != nil {
+		return err
+	}
+
+	if len(*_format) != 0 {
+		return generateFormatAndSave(ast, targetScope, structName)
+	}
+
+	var (
+		table     *sqlTable
+		bindings  []sqlBinding
+		relatives map[string]goast.Scope
+	)
+
+	// the relative structs are found before the model set qualifies the types of the target struct
+	if *_flatten {
+		isSameFolder := isDestinationSameFolderToSource(curDir)
+		if isSameFolder && *_name == structName {
+			return errors.New("-flatten requires -name when the destination is in the same folder")
+		}
+
+		if !isSameFolder && *_relative {
+			return errors.New("-flatten can't be used with -relative when the destination is in another folder")
+		}
+
+		relatives, _ = findRelativeScopes(ast, targetScope)
+	}
+
+	// the table is resolved before the model set qualifies the types of the target struct
+	if *_repository {
+		table, bindings, err = newSQLTable(ast, targetScope, structName)
+		if err != nil {
+			return err
+		}
+	}
+
+	models, err := newModelSet(ast, targetScope, structName, pkg, curDir)
+	if err != nil {
+		return err
+	}
+
+	models.Table, models.Bindings = table, bindings
+
+	if *_flatten {
+		if err := flattenTarget(models, relatives, structName); err != nil {
+			return err
+		}
+	}
+
+	return generateModelAndSave(models)
+}
+
+func parseAstFromGoGenerator() (ast goast.Ast, goLine int, pkg string, curDir string, err error) {
+	dir, file, err := helper.GetDir()
+	if err != nil {
+		return nil, 0, "", "", fmt.Errorf("get directory, err: %w", err)
+	}
+
+	astObj, err := goast.ParseAst(file)
+	if err != nil {
+		return nil, 0, "", "", fmt.Errorf("parse ast, err: %w", err)
+	}
+
+	// the target is found by -type without the directive line, e.g. the targets of gox.yaml
+	goLineNum, err := strconv.Atoi(os.Getenv("GOLINE"))
+	if err != nil && len(*_type) == 0 {
+		return nil, 0, "", "", fmt.Errorf("parse GOLINE, err: %w", err)
+	}
+
+	pkgName := os.Getenv("GOPACKAGE")
+
+	return astObj, goLineNum, pkgName, dir, nil
+}
+
+func findTargetStruct(ast goast.Ast, goLine int) (goast.Scope, error) {
+	var (
+		lineMatched bool
+		targetScope goast.Scope
+	)
+
+	ast.IterScope(func(s goast.Scope) bool {
+		if lineMatched {
+			if s.Line() != goLine {
+				return false
+			}
+
+			goLine++
+
+			switch s.Kind() {
+			case scope.Type:
+				if _, ok := s.GetStructName(); ok {
+					targetScope = s
+				}
+
+				return false
+			case scope.Comment:
+				return true
+			default:
+				return false
+			}
+		} else {
+			lineMatched = s.Line() == goLine
+			if lineMatched {
+				goLine++
+			}
+		}
+
+		return true
+	})
+
+	if targetScope == nil {
+		return nil, errors.New("target struct not found")
+	}
+
+	return targetScope, nil
+}
+
+// findStructScope returns the struct scope of the name.
+func findStructScope(ast goast.Ast, name string) (goast.Scope, error) {
+	var result goast.Scope
+	ast.IterScope(func(sc goast.Scope) bool {
+		if structName, ok := sc.GetStructName(); ok && structName == name {
+			result = sc
+			return false
+		}
+
+		return true
+	})
+
+	if result == nil {
+		return nil, fmt.Errorf("target struct %s not found", name)
+	}
+
+	return result, nil
+}
+
+func generateFormatAndSave(ast goast.Ast, targetScope goast.Scope, structName string) error {
+	switch *_format {
+	case _formatJSONSchema, _formatOpenAPI:
+		return generateSchemaAndSave(ast, targetScope, structName)
+	case _formatSQL:
+		return generateDDLAndSave(ast, targetScope, structName)
+	default:
+		return fmt.Errorf("unsupported format: %s", *_format)
+	}
+}
+
+func generateFromSourceAndSave() error {
+	switch ext := filepath.Ext(*_source); ext {
+	case ".sql":
+		return generateFromSQLAndSave(*_source)
+	case ".json":
+		return generateFromJSONAndSave(*_source)
+	default:
+		return fmt.Errorf("unsupported source file type: %s", ext)
+	}
+}
+
+// formatDestination returns the destination with the file extension of the format.
+func formatDestination(ext string) string {
+	destination := *_destination
+	if filepath.Ext(destination) != ext {
+		destination = strings.TrimSuffix(destination, ".go") + ext
+	}
+
+	return destination
+}
+
+func findStructNameAndSetImplementName(targetScope goast.Scope) (string, error) {
+	structName, ok := targetScope.GetTypeName()
+	if !ok || len(structName) == 0 {
+		return "", errors.New("target struct name not found")
+	}
+
+	if len(*_name) == 0 {
+		*_name = structName
+	}
+
+	return structName, nil
+}
+
+func isDestinationSameFolderToSource(curDir string) bool {
+	targetDir := filepath.Dir(helper.AbsPath(*_destination))
+	return curDir == targetDir
+}
+
+func addPackageNameInFrontOfParamType(targetScope goast.Scope, pkg string) (importPkg bool) {
+	var (
+		params = typeParamNames(targetScope)
+		depth  int
+		isName = true
+	)
+
+	targetScope.Node().IterNext(func(n *goast.Node) bool {
+		text := n.Text()
+
+		// the local constraints of the type parameters, e.g. 'Number' of '[T Number]'
+		switch n.Kind() {
+		case kind.Struct:
+			depth = -1
+		case kind.SquareBracketLeft, kind.CurlyBracketLeft, kind.ParenthesisLeft:
+			if depth >= 0 {
+				depth++
+				isName = depth == 1
+			}
+		case kind.SquareBracketRight, kind.CurlyBracketRight, kind.ParenthesisRight:
+			if depth > 0 {
+				depth--
+			}
+		case kind.Comma:
+			isName = depth == 1
+		case kind.Raw:
+			if depth != 1 {
+				break
+			}
+
+			if isName {
+				isName = false
+				break
+			}
+
+			name := strings.TrimPrefix(text, "~")
+			if helper.IsFirstUpperCase(name) && !strings.Contains(name, ".") && !params[name] {
+				importPkg = true
+				n.SetText(helper.InsertString(text, "~", pkg+"."))
+			}
+		}
+
+		if len(text) == 0 || n.Kind() != kind.ParamType || !helper.IsFirstUpperCase(text, '*') || params[strings.TrimLeft(text, "*")] {
+			return true
+		}
+
+		importPkg = true
+		n.SetText(helper.InsertString(text, "*", pkg+"."))
+		return true
+	})
+
+	return importPkg
+}
+
+func findRelativeScopes(ast goast.Ast, targetScope goast.Scope) (map[string]goast.Scope, []string) {
+	var (
+		astScopeLength = len(ast.Scope())
+
+		isUnhandledStructScope = make(map[string]goast.Scope, astScopeLength)
+		resultScopes           = make(map[string]goast.Scope, astScopeLength)
+		resultScopeNames       = make([]string, 0, astScopeLength)
+
+		findRelativeScope func(goast.Scope)
+	)
+
+	ast.IterScope(func(sc goast.Scope) bool {
+		if sc.Kind() != scope.Type {
+			return true
+		}
+
+		structName, ok := sc.GetStructName()
+		if ok {
+			isUnhandledStructScope[structName] = sc
+		}
+
+		return true
+	})
+
+	findRelativeScope = func(target goast.Scope) {
+		// the type parameters aren't the struct types even though they're parsed as param types
+		params := typeParamNames(target)
+		target.Node().IterNext(func(n *goast.Node) bool {
+			text := helper.TidyString(n.Text(), '*')
+			if len(text) == 0 || !(n.Kind() == kind.ParamType || isEmbeddedParamName(n)) || !helper.IsFirstUpperCase(text) || params[text] {
+				return true
+			}
+
+			structScope, ok := isUnhandledStructScope[text]
+			delete(isUnhandledStructScope, text)
+			if ok {
+				resultScopes[text] = structScope
+				resultScopeNames = append(resultScopeNames, text)
+				findRelativeScope(structScope)
+			}
+
+			return true
+		})
+	}
+
+	findRelativeScope(targetScope)
+
+	return resultScopes, resultScopeNames
+}
+
+// isEmbeddedParamName reports whether the param name node is an embedded field followed by a tag.
+func isEmbeddedParamName(n *goast.Node) bool {
+	if n.Kind() != kind.ParamName {
+		return false
+	}
+
+	next := n.Next()
+	for next != nil && (next.Kind() == kind.Space || next.Kind() == kind.Tab) {
+		next = next.Next()
+	}
+
+	return next != nil && next.Kind() == kind.String
+}
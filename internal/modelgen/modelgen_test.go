@@ -0,0 +1,55 @@
+package modelgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTypeRelativeInOtherFile(t *testing.T) {
+	dir := runModule(t, map[string]string{
+		"domain/generate.go": `package domain
+
+//go:generate modelgen -type=Member -relative -destination=../entity/member.go -package=entity
+`,
+		"domain/member.go": `package domain
+
+type Member struct {
+	ID  int64
+	Ext Ext
+}
+`,
+		"domain/ext.go": `package domain
+
+// Ext is declared in another file of the package.
+type Ext struct {
+	Key    string
+	Status Status
+}
+
+type Status int
+`,
+	}, map[string]string{
+		"entity/member_test.go": `package entity
+
+import "testing"
+
+func TestMember(t *testing.T) {
+	m := Member{ID: 1, Ext: Ext{Key: "k", Status: Status(2)}}
+	if m.Ext.Key != "k" || m.Ext.Status != 2 {
+		t.Fatalf("member mismatch: %+v", m)
+	}
+}
+`,
+	})
+
+	content, err := os.ReadFile(filepath.Join(dir, "entity", "member.go"))
+	if err != nil {
+		t.Fatalf("read generated file, err: %+v", err)
+	}
+
+	if !strings.Contains(string(content), "// Ext is declared in another file of the package.\ntype Ext struct {") {
+		t.Fatalf("relative struct isn't copied:\n%s", content)
+	}
+}
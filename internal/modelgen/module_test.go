@@ -0,0 +1,72 @@
+package modelgen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runModule writes the sources into a temporary module and runs their modelgen directives, then
+// writes the tests and runs them against the generated code. It returns the folder of the module.
+func runModule(t *testing.T, sources, tests map[string]string) string {
+	t.Helper()
+
+	dir, bin := generateModule(t, sources)
+	for name, content := range tests {
+		writeModuleFile(t, filepath.Join(dir, name), content)
+	}
+
+	runGo(t, dir, bin, "test", "./...")
+
+	return dir
+}
+
+// generateModule writes the sources into a temporary module and runs their modelgen directives.
+// It returns the folder of the module and the folder of the modelgen binary.
+func generateModule(t *testing.T, sources map[string]string) (string, string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skip building the generated code in short mode")
+	}
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if output, err := exec.Command("go", "build", "-o", filepath.Join(bin, "modelgen"), "github.com/yanun0323/gox/cmd/modelgen").CombinedOutput(); err != nil {
+		t.Fatalf("build modelgen, err: %+v\n%s", err, output)
+	}
+
+	writeModuleFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	for name, content := range sources {
+		writeModuleFile(t, filepath.Join(dir, name), content)
+	}
+
+	runGo(t, dir, bin, "generate", "./...")
+
+	return dir, bin
+}
+
+func runGo(t *testing.T, dir, bin string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %s, err: %+v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func writeModuleFile(t *testing.T, file, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		t.Fatalf("mkdir, err: %+v", err)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write file, err: %+v", err)
+	}
+}
@@ -0,0 +1,606 @@
+package modelgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_typeDBTX = "DBTX"
+
+	_methodCreate = "Create"
+	_methodGet    = "Get"
+	_methodUpdate = "Update"
+	_methodDelete = "Delete"
+	_methodList   = "List"
+)
+
+// generateRepository generates the database/sql CRUD repository of the target struct, the column
+// list, the scan function and the typed filter of List, with the quotes and placeholders of -dialect.
+func generateRepository(ms *modelSet) ([]merge.Scope, error) {
+	switch *_dialect {
+	case _dialectPostgres, _dialectMySQL, _dialectSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported dialect: %s", *_dialect)
+	}
+
+	if ms.Table == nil || len(ms.Table.Columns) == 0 {
+		return nil, fmt.Errorf("no column of %s for the repository", ms.Target.Name)
+	}
+
+	for i, binding := range ms.Bindings {
+		if binding.Serialized {
+			return nil, fmt.Errorf("unsupported serializer column %s of %s for the repository", ms.Table.Columns[i].Name, ms.Target.Name)
+		}
+	}
+
+	for _, path := range []string{"context", "database/sql", "strconv", "strings"} {
+		ms.addImport(path)
+	}
+
+	g := newRepositoryGenerator(ms)
+
+	var result []merge.Scope
+	if ms.ShouldDeclare(_typeDBTX) {
+		result = append(result, merge.Scope{Key: _typeDBTX, Text: `// DBTX is the database handle of the generated repositories, both *sql.DB and *sql.Tx implement it.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+`})
+	}
+
+	if ms.ShouldDeclare(g.name + "Table") {
+		result = append(result, merge.Scope{Key: g.name + "Table", Text: fmt.Sprintf(`// The table and columns of the %s.
+const (
+	%sTable = %s
+	%sColumns = %s
+)
+`, g.name, g.name, strconv.Quote(g.table.Name), g.name, goStringLiteral(g.columnList(g.all())))})
+	}
+
+	if ms.ShouldDeclare(g.scan) {
+		result = append(result, merge.Scope{Key: g.scan, Text: g.genScanString()})
+	}
+
+	if g.hasPointers() && ms.ShouldDeclare(g.values) {
+		result = append(result, merge.Scope{Key: g.values, Text: g.genValuesString()})
+	}
+
+	if ms.ShouldDeclare(g.filter) {
+		result = append(result, merge.Scope{Key: g.filter, Text: g.genFilterString()})
+	}
+
+	if !ms.ShouldDeclare(g.repository) {
+		return result, nil
+	}
+
+	result = append(result,
+		merge.Scope{Key: g.repository, Text: fmt.Sprintf(`// %s is the CRUD repository of the %s over the %s table.
+type %s struct {
+	db %s
+}
+`, g.repository, g.name, g.table.Name, g.repository, _typeDBTX)},
+		merge.Scope{Key: "New" + g.repository, Text: fmt.Sprintf(`// New%s returns the %s over the database handle.
+func New%s(db %s) *%s {
+	return &%s{db: db}
+}
+`, g.repository, g.repository, g.repository, _typeDBTX, g.repository, g.repository)},
+	)
+
+	methods := []struct {
+		name string
+		gen  func() (string, bool)
+	}{
+		{_methodCreate, g.genCreateString},
+		{_methodGet, g.genGetString},
+		{_methodUpdate, g.genUpdateString},
+		{_methodDelete, g.genDeleteString},
+		{_methodList, g.genListString},
+	}
+
+	for _, method := range methods {
+		key := g.repository + "." + method.name
+		if !ms.ShouldDeclare(key) {
+			continue
+		}
+
+		if text, ok := method.gen(); ok {
+			result = append(result, merge.Scope{Key: key, Text: text})
+		}
+	}
+
+	return result, nil
+}
+
+// repositoryGenerator generates the repository of the target struct.
+type repositoryGenerator struct {
+	ms       *modelSet
+	table    *sqlTable
+	bindings []sqlBinding
+
+	name       string
+	repository string
+	scan       string
+	filter     string
+	values     string
+	// param is the parameter name of the target struct, the receiver of the repository is 'r'.
+	param string
+	// filterFields is the field names of the filter, empty for the columns which can't be filtered.
+	filterFields []string
+}
+
+func newRepositoryGenerator(ms *modelSet) *repositoryGenerator {
+	g := &repositoryGenerator{
+		ms:         ms,
+		table:      ms.Table,
+		bindings:   ms.Bindings,
+		name:       ms.Target.Name,
+		repository: helper.FirstUpperCase(ms.Target.Name) + "Repository",
+		scan:       "Scan" + helper.FirstUpperCase(ms.Target.Name),
+		filter:     helper.FirstUpperCase(ms.Target.Name) + "Filter",
+		values:     helper.FirstLowerCase(ms.Target.Name) + "Values",
+		param:      ms.Target.Receiver(),
+	}
+
+	if g.param == "r" {
+		g.param = "model"
+	}
+
+	used := map[string]bool{}
+	g.filterFields = make([]string, len(g.bindings))
+	for i, binding := range g.bindings {
+		t := parseTypeExpr(binding.Type)
+		if t.Kind != typeIdent && t.Kind != typePointer {
+			continue
+		}
+
+		name := binding.Field[strings.LastIndex(binding.Field, ".")+1:]
+		if used[name] {
+			name = helper.CamelCase(g.table.Columns[i].Name)
+		}
+
+		used[name] = true
+		g.filterFields[i] = name
+	}
+
+	return g
+}
+
+// all returns the indexes of all the columns.
+func (g *repositoryGenerator) all() []int {
+	indexes := make([]int, len(g.table.Columns))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return indexes
+}
+
+// primaryKeys returns the indexes of the primary key columns.
+func (g *repositoryGenerator) primaryKeys() []int {
+	var indexes []int
+	for i, column := range g.table.Columns {
+		if column.PrimaryKey {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// autoIncrement returns the index of the auto increment primary key which is set back by Create.
+func (g *repositoryGenerator) autoIncrement() (int, bool) {
+	for i, column := range g.table.Columns {
+		if column.PrimaryKey && column.AutoIncrement && parseTypeExpr(g.bindings[i].Type).Kind == typeIdent {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (g *repositoryGenerator) hasPointers() bool {
+	for _, binding := range g.bindings {
+		if len(binding.Pointers) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *repositoryGenerator) columnList(indexes []int) string {
+	names := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		names = append(names, g.table.Columns[i].Name)
+	}
+
+	return g.table.quoteList(names)
+}
+
+// placeholder returns the n-th placeholder of the dialect, starting from 1.
+func (g *repositoryGenerator) placeholder(n int) string {
+	if g.table.Dialect == _dialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+
+	return "?"
+}
+
+// conditions returns the conditions like '"id" = $1 AND "key" = $2' with the placeholders from start.
+func (g *repositoryGenerator) conditions(indexes []int, sep string, start int) string {
+	conditions := make([]string, 0, len(indexes))
+	for n, i := range indexes {
+		conditions = append(conditions, g.table.quote(g.table.Columns[i].Name)+" = "+g.placeholder(start+n))
+	}
+
+	return strings.Join(conditions, sep)
+}
+
+// args returns the argument list of the columns read from the target struct, and the statement
+// preparing them when the columns are promoted through the embedded struct pointers.
+func (g *repositoryGenerator) args(indexes []int) (prepare string, args string) {
+	list := make([]string, 0, len(indexes))
+	usesValues := false
+	for _, i := range indexes {
+		usesValues = usesValues || len(g.bindings[i].Pointers) != 0
+	}
+
+	for _, i := range indexes {
+		if usesValues {
+			list = append(list, fmt.Sprintf("values[%d]", i))
+			continue
+		}
+
+		list = append(list, g.param+"."+g.bindings[i].Field)
+	}
+
+	if usesValues {
+		prepare = fmt.Sprintf("\tvalues := %s(%s)\n", g.values, g.param)
+	}
+
+	return prepare, strings.Join(list, ", ")
+}
+
+// params returns the parameters and the arguments of the primary keys.
+func (g *repositoryGenerator) params(indexes []int) (params string, args string) {
+	var (
+		paramList = make([]string, 0, len(indexes))
+		argList   = make([]string, 0, len(indexes))
+		used      = map[string]bool{"ctx": true, "r": true}
+	)
+
+	for _, i := range indexes {
+		binding := g.bindings[i]
+		name := helper.LowerCamelCase(binding.Field[strings.LastIndex(binding.Field, ".")+1:])
+		if _goKeywords[name] || used[name] {
+			name += "Value"
+		}
+
+		used[name] = true
+		paramList = append(paramList, name+" "+g.ms.Qualify(binding.Type))
+		argList = append(argList, name)
+	}
+
+	return strings.Join(paramList, ", "), strings.Join(argList, ", ")
+}
+
+// allocPointers returns the statements allocating the nil embedded struct pointers on the way to
+// the columns.
+func (g *repositoryGenerator) allocPointers(receiver string, indexes []int, checkNil bool) string {
+	var (
+		buf     = strings.Builder{}
+		visited = map[string]bool{}
+	)
+
+	for _, i := range indexes {
+		for _, p := range g.bindings[i].Pointers {
+			if visited[p.Field] {
+				continue
+			}
+
+			visited[p.Field] = true
+			alloc := fmt.Sprintf("%s.%s = new(%s)", receiver, p.Field, g.ms.Qualify(p.Type))
+			if checkNil {
+				buf.WriteString(fmt.Sprintf("\tif %s.%s == nil {\n\t\t%s\n\t}\n\n", receiver, p.Field, alloc))
+				continue
+			}
+
+			buf.WriteString("\t" + alloc + "\n")
+		}
+	}
+
+	return buf.String()
+}
+
+func (g *repositoryGenerator) genScanString() string {
+	dest := make([]string, 0, len(g.bindings))
+	for _, binding := range g.bindings {
+		dest = append(dest, "&x."+binding.Field)
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s scans the row of the columns in %sColumns into the %s.\n", g.scan, g.name, g.name))
+	buf.WriteString(fmt.Sprintf("func %s(row interface{ Scan(dest ...any) error }) (*%s, error) {\n", g.scan, g.name))
+	buf.WriteString(fmt.Sprintf("\tvar x %s\n", g.name))
+	buf.WriteString(g.allocPointers("x", g.all(), false))
+	buf.WriteString(fmt.Sprintf("\tif err := row.Scan(%s); err != nil {\n\t\treturn nil, err\n\t}\n\n", strings.Join(dest, ", ")))
+	buf.WriteString("\treturn &x, nil\n}\n")
+
+	return buf.String()
+}
+
+func (g *repositoryGenerator) genValuesString() string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s returns the values of the columns in %sColumns, the columns of the nil embedded structs are NULL.\n", g.values, g.name))
+	buf.WriteString(fmt.Sprintf("func %s(x *%s) []any {\n", g.values, g.name))
+	buf.WriteString(fmt.Sprintf("\tvalues := make([]any, %d)\n", len(g.bindings)))
+
+	var (
+		current string
+		open    bool
+	)
+
+	for i, binding := range g.bindings {
+		conditions := make([]string, 0, len(binding.Pointers))
+		for _, p := range binding.Pointers {
+			conditions = append(conditions, "x."+p.Field+" != nil")
+		}
+
+		condition := strings.Join(conditions, " && ")
+		if condition != current || !open {
+			if open {
+				buf.WriteString("\t}\n")
+				open = false
+			}
+
+			if len(condition) != 0 {
+				buf.WriteString(fmt.Sprintf("\tif %s {\n", condition))
+				open = true
+			}
+
+			current = condition
+		}
+
+		indent := "\t"
+		if open {
+			indent = "\t\t"
+		}
+
+		buf.WriteString(fmt.Sprintf("%svalues[%d] = x.%s\n", indent, i, binding.Field))
+	}
+
+	if open {
+		buf.WriteString("\t}\n")
+	}
+
+	buf.WriteString("\n\treturn values\n}\n")
+
+	return buf.String()
+}
+
+func (g *repositoryGenerator) genFilterString() string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s filters the %s rows of List by the equality of the columns, the nil fields are ignored.\n", g.filter, g.name))
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", g.filter))
+	for i, name := range g.filterFields {
+		if len(name) == 0 {
+			continue
+		}
+
+		typeText := g.ms.Qualify(g.bindings[i].Type)
+		if !strings.HasPrefix(typeText, "*") {
+			typeText = "*" + typeText
+		}
+
+		buf.WriteString(fmt.Sprintf("\t%s %s\n", name, typeText))
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+func (g *repositoryGenerator) genCreateString() (string, bool) {
+	var (
+		ai, hasAutoIncrement = g.autoIncrement()
+		columns              []int
+	)
+
+	for i := range g.table.Columns {
+		if !hasAutoIncrement || i != ai {
+			columns = append(columns, i)
+		}
+	}
+
+	var query string
+	switch {
+	case len(columns) != 0:
+		placeholders := make([]string, 0, len(columns))
+		for n := range columns {
+			placeholders = append(placeholders, g.placeholder(n+1))
+		}
+
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", g.table.quote(g.table.Name), g.columnList(columns), strings.Join(placeholders, ", "))
+	case g.table.Dialect == _dialectMySQL:
+		query = fmt.Sprintf("INSERT INTO %s () VALUES ()", g.table.quote(g.table.Name))
+	default:
+		query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", g.table.quote(g.table.Name))
+	}
+
+	prepare, args := g.args(columns)
+	if len(args) != 0 {
+		args = ", " + args
+	}
+
+	buf := strings.Builder{}
+	if hasAutoIncrement {
+		buf.WriteString(fmt.Sprintf("// Create inserts the %s into the %s table and sets the auto increment %s back.\n", g.name, g.table.Name, g.bindings[ai].Field))
+	} else {
+		buf.WriteString(fmt.Sprintf("// Create inserts the %s into the %s table.\n", g.name, g.table.Name))
+	}
+
+	buf.WriteString(fmt.Sprintf("func (r *%s) Create(ctx context.Context, %s *%s) error {\n", g.repository, g.param, g.name))
+	buf.WriteString(prepare)
+
+	switch {
+	case !hasAutoIncrement:
+		buf.WriteString(fmt.Sprintf("\t_, err := r.db.ExecContext(ctx, %s%s)\n\treturn err\n}\n", goStringLiteral(query), args))
+	case g.table.Dialect == _dialectPostgres:
+		buf.WriteString(g.allocPointers(g.param, []int{ai}, true))
+		query += " RETURNING " + g.table.quote(g.table.Columns[ai].Name)
+		buf.WriteString(fmt.Sprintf("\treturn r.db.QueryRowContext(ctx, %s%s).Scan(&%s.%s)\n}\n", goStringLiteral(query), args, g.param, g.bindings[ai].Field))
+	default:
+		buf.WriteString(fmt.Sprintf("\tresult, err := r.db.ExecContext(ctx, %s%s)\n", goStringLiteral(query), args))
+		buf.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\n")
+		buf.WriteString("\tid, err := result.LastInsertId()\n")
+		buf.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\n")
+		buf.WriteString(g.allocPointers(g.param, []int{ai}, true))
+		buf.WriteString(fmt.Sprintf("\t%s.%s = %s(id)\n\treturn nil\n}\n", g.param, g.bindings[ai].Field, g.ms.Qualify(g.bindings[ai].Type)))
+	}
+
+	return buf.String(), true
+}
+
+func (g *repositoryGenerator) genGetString() (string, bool) {
+	keys := g.primaryKeys()
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	params, args := g.params(keys)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", g.columnList(g.all()), g.table.quote(g.table.Name), g.conditions(keys, " AND ", 1))
+
+	return fmt.Sprintf(`// Get returns the %s of the primary key, it returns sql.ErrNoRows when the row doesn't exist.
+func (r *%s) Get(ctx context.Context, %s) (*%s, error) {
+	return %s(r.db.QueryRowContext(ctx, %s, %s))
+}
+`, g.name, g.repository, params, g.name, g.scan, goStringLiteral(query), args), true
+}
+
+func (g *repositoryGenerator) genUpdateString() (string, bool) {
+	keys := g.primaryKeys()
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	var columns []int
+	for i, column := range g.table.Columns {
+		if !column.PrimaryKey {
+			columns = append(columns, i)
+		}
+	}
+
+	if len(columns) == 0 {
+		return "", false
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", g.table.quote(g.table.Name), g.conditions(columns, ", ", 1), g.conditions(keys, " AND ", len(columns)+1))
+	prepare, args := g.args(append(columns, keys...))
+
+	return fmt.Sprintf(`// Update updates the columns of the %s by the primary key.
+func (r *%s) Update(ctx context.Context, %s *%s) error {
+%s	_, err := r.db.ExecContext(ctx, %s, %s)
+	return err
+}
+`, g.name, g.repository, g.param, g.name, prepare, goStringLiteral(query), args), true
+}
+
+func (g *repositoryGenerator) genDeleteString() (string, bool) {
+	keys := g.primaryKeys()
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	params, args := g.params(keys)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", g.table.quote(g.table.Name), g.conditions(keys, " AND ", 1))
+
+	return fmt.Sprintf(`// Delete deletes the %s of the primary key.
+func (r *%s) Delete(ctx context.Context, %s) error {
+	_, err := r.db.ExecContext(ctx, %s, %s)
+	return err
+}
+`, g.name, g.repository, params, goStringLiteral(query), args), true
+}
+
+func (g *repositoryGenerator) genListString() (string, bool) {
+	// the placeholder of the dynamic queries is numbered by the length of the args in postgres
+	placeholder := func(prefix string) string {
+		if g.table.Dialect == _dialectPostgres {
+			return goStringLiteral(prefix+"$") + "+strconv.Itoa(len(args))"
+		}
+
+		return goStringLiteral(prefix + "?")
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// List returns the %s rows matching the filter ordered by the primary key, it returns all the rows\n", g.name))
+	buf.WriteString("// after the offset when the limit isn't positive.\n")
+	buf.WriteString(fmt.Sprintf("func (r *%s) List(ctx context.Context, filter %s, limit, offset int) ([]*%s, error) {\n", g.repository, g.filter, g.name))
+	buf.WriteString("\tvar (\n\t\tconditions []string\n\t\targs       []any\n\t)\n\n")
+
+	for i, name := range g.filterFields {
+		if len(name) == 0 {
+			continue
+		}
+
+		buf.WriteString(fmt.Sprintf("\tif filter.%s != nil {\n", name))
+		buf.WriteString(fmt.Sprintf("\t\targs = append(args, *filter.%s)\n", name))
+		buf.WriteString(fmt.Sprintf("\t\tconditions = append(conditions, %s)\n\t}\n\n", placeholder(g.table.quote(g.table.Columns[i].Name)+" = ")))
+	}
+
+	buf.WriteString(fmt.Sprintf("\tquery := %s\n", goStringLiteral(fmt.Sprintf("SELECT %s FROM %s", g.columnList(g.all()), g.table.quote(g.table.Name)))))
+	buf.WriteString("\tif len(conditions) != 0 {\n\t\tquery += \" WHERE \" + strings.Join(conditions, \" AND \")\n\t}\n\n")
+
+	if keys := g.primaryKeys(); len(keys) != 0 {
+		buf.WriteString(fmt.Sprintf("\tquery += %s\n\n", goStringLiteral(" ORDER BY "+g.columnList(keys))))
+	}
+
+	buf.WriteString("\tif limit > 0 {\n\t\targs = append(args, limit)\n")
+	buf.WriteString(fmt.Sprintf("\t\tquery += %s\n\t}\n\n", placeholder(" LIMIT ")))
+	buf.WriteString("\tif offset > 0 {\n")
+	switch g.table.Dialect {
+	case _dialectMySQL:
+		buf.WriteString("\t\tif limit <= 0 {\n\t\t\tquery += \" LIMIT 18446744073709551615\"\n\t\t}\n\n")
+	case _dialectSQLite:
+		buf.WriteString("\t\tif limit <= 0 {\n\t\t\tquery += \" LIMIT -1\"\n\t\t}\n\n")
+	}
+	buf.WriteString("\t\targs = append(args, offset)\n")
+	buf.WriteString(fmt.Sprintf("\t\tquery += %s\n\t}\n\n", placeholder(" OFFSET ")))
+
+	buf.WriteString(`	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*` + g.name + `
+	for rows.Next() {
+		item, err := ` + g.scan + `(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+`)
+
+	return buf.String(), true
+}
+
+// goStringLiteral returns the raw string literal of the text, or the interpreted one when the
+// text contains backquotes like the mysql identifiers.
+func goStringLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
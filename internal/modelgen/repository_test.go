@@ -0,0 +1,233 @@
+package modelgen
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRepositoryBindings(t *testing.T) {
+	fields, err := parseStructFieldsFromText("struct {\n*gorm.Model\nKey string `gorm:\"column:key\"`\nTags []string `gorm:\"serializer:json\"`\n}")
+	if err != nil {
+		t.Fatalf("parse fields, err: %+v", err)
+	}
+
+	table := &sqlTable{Dialect: _dialectPostgres, Name: "examples"}
+	builder := ddlBuilder{table: table, indexes: map[string]int{}, noAutoIncrement: map[string]bool{}}
+	if err := builder.addColumns(fields, "", sqlBinding{}); err != nil {
+		t.Fatalf("add columns, err: %+v", err)
+	}
+
+	if len(builder.bindings) != len(table.Columns) || len(table.Columns) != 6 {
+		t.Fatalf("bindings mismatch: %+v", builder.bindings)
+	}
+
+	if id := builder.bindings[0]; id.Field != "Model.ID" || len(id.Pointers) != 1 || id.Pointers[0].Type != "gorm.Model" {
+		t.Fatalf("binding of id mismatch: %+v", id)
+	}
+
+	if key := builder.bindings[4]; key.Field != "Key" || len(key.Pointers) != 0 || key.Serialized {
+		t.Fatalf("binding of key mismatch: %+v", key)
+	}
+
+	if tags := builder.bindings[5]; !tags.Serialized {
+		t.Fatalf("binding of tags mismatch: %+v", tags)
+	}
+
+	g := newRepositoryGenerator(&modelSet{Target: &model{Name: "Example"}, Table: table, Bindings: builder.bindings})
+	if got := g.conditions([]int{4, 0}, " AND ", 2); got != `"key" = $2 AND "id" = $3` {
+		t.Fatalf("conditions mismatch: %s", got)
+	}
+
+	if prepare, args := g.args([]int{4, 0}); prepare != "\tvalues := exampleValues(e)\n" || args != "values[4], values[0]" {
+		t.Fatalf("args mismatch: %q, %s", prepare, args)
+	}
+
+	if prepare, args := g.args([]int{4}); len(prepare) != 0 || args != "e.Key" {
+		t.Fatalf("args mismatch: %q, %s", prepare, args)
+	}
+}
+
+func TestGenerateRepository(t *testing.T) {
+	queries := map[string][]string{
+		_dialectPostgres: {
+			`INSERT INTO "members" ("name", "status", "note") VALUES ($1, $2, $3) RETURNING "id"`,
+			`SELECT "id", "name", "status", "note" FROM "members" WHERE "id" = $1`,
+			`UPDATE "members" SET "name" = $1, "status" = $2, "note" = $3 WHERE "id" = $4`,
+			`DELETE FROM "members" WHERE "id" = $1`,
+			`SELECT "id", "name", "status", "note" FROM "members" WHERE "name" = $1 AND "status" = $2 ORDER BY "id" LIMIT $3 OFFSET $4`,
+			`SELECT "id", "name", "status", "note" FROM "members" ORDER BY "id" OFFSET $1`,
+		},
+		_dialectMySQL: {
+			"INSERT INTO `members` (`name`, `status`, `note`) VALUES (?, ?, ?)",
+			"SELECT `id`, `name`, `status`, `note` FROM `members` WHERE `id` = ?",
+			"UPDATE `members` SET `name` = ?, `status` = ?, `note` = ? WHERE `id` = ?",
+			"DELETE FROM `members` WHERE `id` = ?",
+			"SELECT `id`, `name`, `status`, `note` FROM `members` WHERE `name` = ? AND `status` = ? ORDER BY `id` LIMIT ? OFFSET ?",
+			"SELECT `id`, `name`, `status`, `note` FROM `members` ORDER BY `id` LIMIT 18446744073709551615 OFFSET ?",
+		},
+		_dialectSQLite: {
+			`INSERT INTO "members" ("name", "status", "note") VALUES (?, ?, ?)`,
+			`SELECT "id", "name", "status", "note" FROM "members" WHERE "id" = ?`,
+			`UPDATE "members" SET "name" = ?, "status" = ?, "note" = ? WHERE "id" = ?`,
+			`DELETE FROM "members" WHERE "id" = ?`,
+			`SELECT "id", "name", "status", "note" FROM "members" WHERE "name" = ? AND "status" = ? ORDER BY "id" LIMIT ? OFFSET ?`,
+			`SELECT "id", "name", "status", "note" FROM "members" ORDER BY "id" LIMIT -1 OFFSET ?`,
+		},
+	}
+
+	sources := map[string]string{
+		"domain/member.go": `package domain
+
+//go:generate modelgen -type=Member -relative -repository -dialect=postgres -destination=../postgres/member.go -package=postgres
+//go:generate modelgen -type=Member -relative -repository -dialect=mysql -destination=../mysql/member.go -package=mysql
+//go:generate modelgen -type=Member -relative -repository -dialect=sqlite -destination=../sqlite/member.go -package=sqlite
+
+type Member struct {
+	ID     int64  ` + "`gorm:\"primaryKey\"`" + `
+	Name   string ` + "`gorm:\"size:64\"`" + `
+	Status int
+	Note   *string
+}
+`,
+		"dbtest/dbtest.go": `package dbtest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Recorder is the connector of a fake database recording the queries, the queries return the
+// member of id 7, or only the id when they return the inserted id.
+type Recorder struct {
+	Queries []string
+	Args    [][]any
+}
+
+func (r *Recorder) Connect(context.Context) (driver.Conn, error) { return conn{r: r}, nil }
+func (r *Recorder) Driver() driver.Driver                        { return nil }
+
+func (r *Recorder) record(query string, args []driver.NamedValue) {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	r.Queries = append(r.Queries, query)
+	r.Args = append(r.Args, values)
+}
+
+type conn struct{ r *Recorder }
+
+func (conn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("prepare isn't supported") }
+func (conn) Close() error                        { return nil }
+func (conn) Begin() (driver.Tx, error)           { return nil, errors.New("begin isn't supported") }
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args)
+	return result{}, nil
+}
+
+func (c conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args)
+	if strings.Contains(query, "RETURNING") {
+		return &rows{columns: []string{"id"}, values: []driver.Value{int64(7)}}, nil
+	}
+
+	return &rows{columns: []string{"id", "name", "status", "note"}, values: []driver.Value{int64(7), "n", int64(2), nil}}, nil
+}
+
+type result struct{}
+
+func (result) LastInsertId() (int64, error) { return 7, nil }
+func (result) RowsAffected() (int64, error) { return 1, nil }
+
+type rows struct {
+	columns []string
+	values  []driver.Value
+	done    bool
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+`,
+	}
+
+	tests := map[string]string{}
+	for dialect, expected := range queries {
+		tests[dialect+"/member_test.go"] = fmt.Sprintf(`package %s
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"example.com/app/dbtest"
+)
+
+func TestRepository(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		rec    = &dbtest.Recorder{}
+		repo   = NewMemberRepository(sql.OpenDB(rec))
+		name   = "n"
+		status = 2
+	)
+
+	m := &Member{Name: "n", Status: 2}
+	if err := repo.Create(ctx, m); err != nil || m.ID != 7 {
+		t.Fatalf("create mismatch: %%+v, err: %%+v", m, err)
+	}
+
+	if got, err := repo.Get(ctx, 7); err != nil || !reflect.DeepEqual(got, m) {
+		t.Fatalf("get mismatch: %%+v, err: %%+v", got, err)
+	}
+
+	if err := repo.Update(ctx, m); err != nil {
+		t.Fatalf("update, err: %%+v", err)
+	}
+
+	if err := repo.Delete(ctx, 7); err != nil {
+		t.Fatalf("delete, err: %%+v", err)
+	}
+
+	if list, err := repo.List(ctx, MemberFilter{Name: &name, Status: &status}, 10, 20); err != nil || len(list) != 1 || !reflect.DeepEqual(list[0], m) {
+		t.Fatalf("list mismatch: %%+v, err: %%+v", list, err)
+	}
+
+	if _, err := repo.List(ctx, MemberFilter{}, 0, 5); err != nil {
+		t.Fatalf("list by offset, err: %%+v", err)
+	}
+
+	if expected := %#v; !reflect.DeepEqual(rec.Queries, expected) {
+		t.Fatalf("queries mismatch:\n%%q\nexpected:\n%%q", rec.Queries, expected)
+	}
+
+	for i, count := range []int{3, 1, 4, 1, 4, 1} {
+		if len(rec.Args[i]) != count {
+			t.Fatalf("args of %%s mismatch: %%+v", rec.Queries[i], rec.Args[i])
+		}
+	}
+
+	if expected := []any{"n", int64(2), int64(10), int64(20)}; !reflect.DeepEqual(rec.Args[4], expected) {
+		t.Fatalf("args of list mismatch: %%+v", rec.Args[4])
+	}
+}
+`, dialect, expected)
+	}
+
+	runModule(t, sources, tests)
+}
@@ -0,0 +1,468 @@
+package modelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/goast"
+)
+
+const (
+	_formatJSONSchema = "jsonschema"
+	_formatOpenAPI    = "openapi"
+
+	_jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	_openAPIVersion  = "3.1.0"
+)
+
+// generateSchemaAndSave generates the JSON Schema or OpenAPI components of the target struct
+// and its relative structs, then saves it to the destination.
+func generateSchemaAndSave(ast goast.Ast, targetScope goast.Scope, structName string) error {
+	relativeScopes, relativeScopesNames := findRelativeScopes(ast, targetScope)
+
+	builder := schemaBuilder{
+		format:  *_format,
+		structs: make(map[string][]structField, len(relativeScopes)+1),
+		names:   map[string]string{structName: *_name},
+	}
+
+	switch *_format {
+	case _formatJSONSchema:
+		builder.refPrefix = "#/$defs/"
+	case _formatOpenAPI:
+		builder.refPrefix = "#/components/schemas/"
+	default:
+		return fmt.Errorf("unsupported format: %s", *_format)
+	}
+
+	targetFields, err := parseStructFields(targetScope)
+	if err != nil {
+		return fmt.Errorf("parse fields of %s, err: %w", structName, err)
+	}
+	builder.structs[structName] = targetFields
+
+	for _, name := range relativeScopesNames {
+		fields, err := parseStructFields(relativeScopes[name])
+		if err != nil {
+			return fmt.Errorf("parse fields of %s, err: %w", name, err)
+		}
+
+		builder.structs[name] = fields
+	}
+
+	target := builder.structSchema(targetFields, findScopeDoc(ast, targetScope))
+
+	definitions := newOrderedMap()
+	for _, name := range relativeScopesNames {
+		definitions.Set(builder.schemaName(name), builder.structSchema(builder.structs[name], findScopeDoc(ast, relativeScopes[name])))
+	}
+
+	destination := formatDestination(".json")
+
+	var document *orderedMap
+	switch *_format {
+	case _formatJSONSchema:
+		document = newOrderedMap()
+		document.Set("$schema", _jsonSchemaDraft)
+		document.Set("$id", filepath.Base(destination))
+		document.Set("title", *_name)
+		for _, key := range target.Keys() {
+			document.Set(key, target.Get(key))
+		}
+
+		if definitions.Len() != 0 {
+			document.Set("$defs", definitions)
+		}
+	case _formatOpenAPI:
+		document, err = loadOpenAPIDocument(destination)
+		if err != nil {
+			return err
+		}
+
+		schemas := document.Object("components").Object("schemas")
+		schemas.Set(*_name, target)
+		for _, key := range definitions.Keys() {
+			schemas.Set(key, definitions.Get(key))
+		}
+	}
+
+	buf, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schema, err: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return fmt.Errorf("make destination directory, err: %w", err)
+	}
+
+	helper.recordFile(destination)
+	if err := os.WriteFile(destination, append(buf, '\n'), 0o644); err != nil {
+		return fmt.Errorf("save schema, err: %w", err)
+	}
+
+	return nil
+}
+
+// loadOpenAPIDocument loads the existing OpenAPI document to merge the generated schemas into,
+// or returns a new document if the file does not exist.
+func loadOpenAPIDocument(destination string) (*orderedMap, error) {
+	document := newOrderedMap()
+
+	buf, err := os.ReadFile(destination)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read destination, err: %w", err)
+	}
+
+	if len(buf) != 0 {
+		if err := json.Unmarshal(buf, document); err != nil {
+			return nil, fmt.Errorf("parse destination openapi document, err: %w", err)
+		}
+	}
+
+	if !document.Has("openapi") {
+		document.Set("openapi", _openAPIVersion)
+	}
+
+	if !document.Has("info") {
+		title := *_package
+		if len(title) == 0 {
+			title = os.Getenv("GOPACKAGE")
+		}
+
+		info := newOrderedMap()
+		info.Set("title", title)
+		info.Set("version", "0.0.0")
+		document.Set("info", info)
+	}
+
+	return document, nil
+}
+
+type schemaBuilder struct {
+	format    string
+	refPrefix string
+	structs   map[string][]structField
+	names     map[string]string
+}
+
+func (b schemaBuilder) schemaName(structName string) string {
+	if name, ok := b.names[structName]; ok && len(name) != 0 {
+		return name
+	}
+
+	return structName
+}
+
+// structSchema returns the object schema of the struct fields.
+func (b schemaBuilder) structSchema(fields []structField, doc []string) *orderedMap {
+	var (
+		properties = newOrderedMap()
+		required   []string
+	)
+
+	b.addProperties(properties, &required, fields)
+
+	result := newOrderedMap()
+	result.Set("type", "object")
+	if len(doc) != 0 {
+		result.Set("description", strings.Join(doc, "\n"))
+	}
+
+	result.Set("properties", properties)
+	if len(required) != 0 {
+		result.Set("required", required)
+	}
+
+	return result
+}
+
+func (b schemaBuilder) addProperties(properties *orderedMap, required *[]string, fields []structField) {
+	for _, field := range fields {
+		name, options, tagged := field.TagValue("json")
+		if name == "-" && len(options) == 0 {
+			continue
+		}
+
+		if field.Embedded && len(name) == 0 {
+			t := parseTypeExpr(field.Type)
+			for t.Kind == typePointer {
+				t = t.Elem
+			}
+
+			if embedded, ok := b.structs[t.Name]; ok {
+				b.addProperties(properties, required, embedded)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.FieldName()
+		}
+
+		property := b.typeSchema(parseTypeExpr(field.Type))
+		if tagged && hasOption(options, "string") {
+			property = newOrderedMap()
+			property.Set("type", "string")
+		}
+
+		if len(field.Doc) != 0 {
+			property.Set("description", strings.Join(field.Doc, "\n"))
+		}
+
+		properties.Set(name, property)
+		if !hasOption(options, "omitempty") && !hasOption(options, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// typeSchema returns the schema of the type expression.
+func (b schemaBuilder) typeSchema(t *typeExpr) *orderedMap {
+	result := newOrderedMap()
+
+	switch t.Kind {
+	case typePointer:
+		return b.typeSchema(t.Elem)
+	case typeSlice, typeArray:
+		if t.Elem.Kind == typeIdent && (t.Elem.Name == "byte" || t.Elem.Name == "uint8") && t.Kind == typeSlice {
+			result.Set("type", "string")
+			if b.format == _formatOpenAPI {
+				result.Set("format", "byte")
+			} else {
+				result.Set("contentEncoding", "base64")
+			}
+
+			return result
+		}
+
+		result.Set("type", "array")
+		result.Set("items", b.typeSchema(t.Elem))
+		if _, err := strconv.Atoi(t.Len); err == nil {
+			result.Set("minItems", json.Number(t.Len))
+			result.Set("maxItems", json.Number(t.Len))
+		}
+	case typeMap:
+		result.Set("type", "object")
+		result.Set("additionalProperties", b.typeSchema(t.Elem))
+	case typeStruct:
+		fields, err := parseStructFieldsFromText(t.Raw)
+		if err != nil {
+			return result
+		}
+
+		return b.structSchema(fields, nil)
+	case typeIdent:
+		switch t.Name {
+		case "bool":
+			result.Set("type", "boolean")
+		case "string":
+			result.Set("type", "string")
+		case "int", "int64", "uint", "uint64", "uintptr", "time.Duration":
+			result.Set("type", "integer")
+			if b.format == _formatOpenAPI {
+				result.Set("format", "int64")
+			}
+		case "int8", "int16", "int32", "rune", "uint8", "uint16", "uint32", "byte":
+			result.Set("type", "integer")
+			if b.format == _formatOpenAPI {
+				result.Set("format", "int32")
+			}
+		case "float32":
+			result.Set("type", "number")
+			if b.format == _formatOpenAPI {
+				result.Set("format", "float")
+			}
+		case "float64":
+			result.Set("type", "number")
+			if b.format == _formatOpenAPI {
+				result.Set("format", "double")
+			}
+		case "time.Time":
+			result.Set("type", "string")
+			result.Set("format", "date-time")
+		default:
+			if _, ok := b.structs[t.Name]; ok {
+				result.Set("$ref", b.refPrefix+b.schemaName(t.Name))
+			}
+		}
+
+		if strings.HasPrefix(t.Name, "uint") || t.Name == "byte" {
+			result.Set("minimum", json.Number("0"))
+		}
+	}
+
+	return result
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orderedMap is a JSON object which keeps the order of its keys.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]any{}}
+}
+
+func (m *orderedMap) Len() int {
+	return len(m.keys)
+}
+
+func (m *orderedMap) Keys() []string {
+	return m.keys
+}
+
+func (m *orderedMap) Has(key string) bool {
+	_, ok := m.values[key]
+	return ok
+}
+
+func (m *orderedMap) Get(key string) any {
+	return m.values[key]
+}
+
+func (m *orderedMap) Set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Object returns the object value of the key, creating it if it doesn't exist.
+func (m *orderedMap) Object(key string) *orderedMap {
+	if obj, ok := m.values[key].(*orderedMap); ok {
+		return obj
+	}
+
+	obj := newOrderedMap()
+	m.Set(key, obj)
+	return obj
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (m *orderedMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return errors.New("json object expected")
+	}
+
+	return m.decodeObject(decoder)
+}
+
+func (m *orderedMap) decodeObject(decoder *json.Decoder) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return errors.New("json object key expected")
+		}
+
+		value, err := decodeOrderedValue(decoder)
+		if err != nil {
+			return err
+		}
+
+		m.Set(key, value)
+	}
+
+	_, err := decoder.Token()
+	return err
+}
+
+func decodeOrderedValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := newOrderedMap()
+		if err := obj.decodeObject(decoder); err != nil {
+			return nil, err
+		}
+
+		return obj, nil
+	case '[':
+		arr := []any{}
+		for decoder.More() {
+			value, err := decodeOrderedValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, value)
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		return arr, nil
+	}
+
+	return nil, fmt.Errorf("unexpected json delimiter %s", delim)
+}
@@ -0,0 +1,161 @@
+package modelgen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// jsonSchema is the subset of the JSON Schema keywords generated by modelgen.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema"`
+	ID                   string                 `json:"$id"`
+	Title                string                 `json:"title"`
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Format               string                 `json:"format"`
+	Description          string                 `json:"description"`
+	ContentEncoding      string                 `json:"contentEncoding"`
+	Minimum              *float64               `json:"minimum"`
+	Items                *jsonSchema            `json:"items"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	dir, _ := generateModule(t, map[string]string{
+		"domain/member.go": `package domain
+
+import "time"
+
+//go:generate modelgen -type=Member -format=jsonschema -destination=../schema/member.go
+//go:generate modelgen -type=Member -format=openapi -name=MemberResponse -destination=../api/openapi.go
+
+// Member is the member of the organization.
+type Member struct {
+	Base
+	ID        int64          ` + "`json:\"id\"`" + `
+	Name      string         ` + "`json:\"name,omitempty\"`" + `
+	Age       uint8          ` + "`json:\"age\"`" + `
+	Score     float64
+	Count     int64          ` + "`json:\"count,string\"`" + `
+	Tags      []string       ` + "`json:\"tags\"`" + `
+	Labels    map[string]int ` + "`json:\"labels,omitempty\"`" + `
+	Avatar    []byte         ` + "`json:\"avatar,omitempty\"`" + `
+	Secret    string         ` + "`json:\"-\"`" + `
+	Profile   *Profile       ` + "`json:\"profile,omitempty\"`" + `
+	CreatedAt time.Time      ` + "`json:\"created_at\"`" + `
+	internal  string
+}
+
+type Base struct {
+	Version int32 ` + "`json:\"version\"`" + `
+}
+
+type Profile struct {
+	// Nick is the nick name.
+	Nick string ` + "`json:\"nick\"`" + `
+}
+`,
+	})
+
+	var document jsonSchema
+	readJSONFile(t, filepath.Join(dir, "schema", "member.json"), &document)
+
+	if document.Schema != _jsonSchemaDraft || document.ID != "member.json" || document.Title != "Member" || document.Type != "object" {
+		t.Fatalf("document mismatch: %+v", document)
+	}
+
+	if document.Description != "Member is the member of the organization." {
+		t.Fatalf("description mismatch: %s", document.Description)
+	}
+
+	if expected := []string{"version", "id", "age", "Score", "count", "tags", "created_at"}; !reflect.DeepEqual(document.Required, expected) {
+		t.Fatalf("required mismatch: %+v", document.Required)
+	}
+
+	testCases := []struct {
+		desc     string
+		property string
+		expected jsonSchema
+	}{
+		{desc: "embedded struct field", property: "version", expected: jsonSchema{Type: "integer"}},
+		{desc: "json tag name", property: "id", expected: jsonSchema{Type: "integer"}},
+		{desc: "omitempty", property: "name", expected: jsonSchema{Type: "string"}},
+		{desc: "unsigned", property: "age", expected: jsonSchema{Type: "integer", Minimum: new(float64)}},
+		{desc: "field name without tag", property: "Score", expected: jsonSchema{Type: "number"}},
+		{desc: "string option", property: "count", expected: jsonSchema{Type: "string"}},
+		{desc: "slice", property: "tags", expected: jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+		{desc: "map", property: "labels", expected: jsonSchema{Type: "object", AdditionalProperties: &jsonSchema{Type: "integer"}}},
+		{desc: "bytes", property: "avatar", expected: jsonSchema{Type: "string", ContentEncoding: "base64"}},
+		{desc: "relative struct", property: "profile", expected: jsonSchema{Ref: "#/$defs/Profile"}},
+		{desc: "time", property: "created_at", expected: jsonSchema{Type: "string", Format: "date-time"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if property := document.Properties[tc.property]; property == nil || !reflect.DeepEqual(*property, tc.expected) {
+				t.Fatalf("property %s mismatch: %+v", tc.property, property)
+			}
+		})
+	}
+
+	if len(document.Properties) != len(testCases) {
+		t.Fatalf("properties mismatch: %+v", document.Properties)
+	}
+
+	profile := document.Defs["Profile"]
+	if profile == nil || profile.Type != "object" || !reflect.DeepEqual(profile.Required, []string{"nick"}) {
+		t.Fatalf("definition of profile mismatch: %+v", profile)
+	}
+
+	if nick := profile.Properties["nick"]; nick == nil || nick.Type != "string" || nick.Description != "Nick is the nick name." {
+		t.Fatalf("nick of profile mismatch: %+v", nick)
+	}
+
+	var openapi struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]*jsonSchema `json:"schemas"`
+		} `json:"components"`
+	}
+	readJSONFile(t, filepath.Join(dir, "api", "openapi.json"), &openapi)
+
+	if openapi.OpenAPI != _openAPIVersion || len(openapi.Components.Schemas) != 3 {
+		t.Fatalf("openapi mismatch: %+v", openapi)
+	}
+
+	member := openapi.Components.Schemas["MemberResponse"]
+	if member == nil || !reflect.DeepEqual(member.Required, document.Required) {
+		t.Fatalf("openapi member mismatch: %+v", member)
+	}
+
+	for property, expected := range map[string]jsonSchema{
+		"id":      {Type: "integer", Format: "int64"},
+		"version": {Type: "integer", Format: "int32"},
+		"Score":   {Type: "number", Format: "double"},
+		"avatar":  {Type: "string", Format: "byte"},
+		"profile": {Ref: "#/components/schemas/Profile"},
+	} {
+		if schema := member.Properties[property]; schema == nil || !reflect.DeepEqual(*schema, expected) {
+			t.Fatalf("openapi property %s mismatch: %+v", property, schema)
+		}
+	}
+}
+
+func readJSONFile(t *testing.T, file string, v any) {
+	t.Helper()
+
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read %s, err: %+v", file, err)
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		t.Fatalf("decode %s, err: %+v\n%s", file, err, buf)
+	}
+}
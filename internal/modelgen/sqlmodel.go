@@ -0,0 +1,706 @@
+package modelgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_nullablePointer = "pointer"
+	_nullableSQL     = "sql"
+)
+
+// generateFromSQLAndSave generates go model structs from the CREATE TABLE statements of the sql file,
+// then saves them into the destination.
+func generateFromSQLAndSave(file string) error {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read sql file, err: %w", err)
+	}
+
+	tables, err := parseSQLTables(string(buf))
+	if err != nil {
+		return fmt.Errorf("parse sql file %s, err: %w", file, err)
+	}
+
+	if len(*_table) != 0 {
+		for _, table := range tables {
+			if table.Name == *_table {
+				tables = []*sqlTable{table}
+				break
+			}
+		}
+
+		if len(tables) != 1 || tables[0].Name != *_table {
+			return fmt.Errorf("table %s not found in %s", *_table, file)
+		}
+	}
+
+	if len(tables) == 0 {
+		return fmt.Errorf("no CREATE TABLE statement found in %s", file)
+	}
+
+	switch *_nullable {
+	case _nullablePointer, _nullableSQL:
+	default:
+		return fmt.Errorf("unsupported nullable type: %s", *_nullable)
+	}
+
+	var (
+		generated   []merge.Scope
+		importPaths []string
+		imported    = map[string]bool{}
+	)
+
+	for _, table := range tables {
+		structName := helper.CamelCase(helper.Singularize(table.Name))
+		if len(*_name) != 0 && len(tables) == 1 {
+			structName = *_name
+		}
+
+		fields := make([]structField, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			goType, importPath := sqlColumnGoType(column)
+			if len(importPath) != 0 && !imported[importPath] {
+				imported[importPath] = true
+				importPaths = append(importPaths, importPath)
+			}
+
+			field := structField{
+				Name: helper.CamelCase(column.Name),
+				Type: goType,
+				Tag:  sqlColumnTag(table, column),
+			}
+
+			if len(column.Comment) != 0 {
+				field.Doc = strings.Split(column.Comment, "\n")
+			}
+
+			fields = append(fields, field)
+		}
+
+		generated = append(generated,
+			merge.Scope{
+				Key:  structName,
+				Text: genStructString(structName, []string{fmt.Sprintf("%s is the model of table %s.", structName, table.Name)}, fields),
+			},
+			merge.Scope{
+				Key:  structName + ".TableName",
+				Text: fmt.Sprintf("// TableName returns the table name of %s.\nfunc (%s) TableName() string {\n\treturn %q\n}\n", structName, structName, table.Name),
+			},
+		)
+	}
+
+	return saveGeneratedScopes(importPaths, generated)
+}
+
+// sqlColumnGoType returns the go type of the column and the import path it requires.
+func sqlColumnGoType(column sqlColumn) (goType string, importPath string) {
+	sqlType := strings.ToLower(column.Type)
+	if i := strings.Index(sqlType, "("); i >= 0 {
+		sqlType = strings.TrimSpace(sqlType[:i]) + sqlType[strings.LastIndex(sqlType, ")")+1:]
+	}
+
+	unsigned := strings.Contains(sqlType, "unsigned")
+	base := strings.Fields(sqlType)
+	if len(base) == 0 {
+		return "string", ""
+	}
+
+	switch base[0] {
+	case "bool", "boolean":
+		goType = "bool"
+	case "tinyint":
+		goType = "int8"
+		if strings.HasPrefix(strings.ToLower(column.Type), "tinyint(1)") {
+			goType = "bool"
+		}
+	case "smallint", "int2", "smallserial", "serial2", "year":
+		goType = "int16"
+	case "mediumint", "int", "integer", "int4", "serial", "serial4":
+		goType = "int32"
+		if *_dialect == _dialectSQLite {
+			goType = "int64"
+		}
+	case "bigint", "int8", "bigserial", "serial8":
+		goType = "int64"
+	case "real", "float4", "float":
+		goType = "float32"
+	case "double", "float8", "numeric", "decimal", "money":
+		goType = "float64"
+	case "date", "datetime", "timestamp", "timestamptz", "time", "timetz":
+		goType, importPath = "time.Time", "time"
+	case "json", "jsonb":
+		goType, importPath = "json.RawMessage", "encoding/json"
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		goType = "[]byte"
+	default:
+		goType = "string"
+	}
+
+	if unsigned && strings.HasPrefix(goType, "int") {
+		goType = "u" + goType
+	}
+
+	if column.NotNull || column.PrimaryKey || goType == "[]byte" || goType == "json.RawMessage" {
+		return goType, importPath
+	}
+
+	if *_nullable == _nullableSQL {
+		nullType := map[string]string{
+			"bool":      "sql.NullBool",
+			"int8":      "sql.NullInt16",
+			"int16":     "sql.NullInt16",
+			"int32":     "sql.NullInt32",
+			"int64":     "sql.NullInt64",
+			"float32":   "sql.NullFloat64",
+			"float64":   "sql.NullFloat64",
+			"string":    "sql.NullString",
+			"time.Time": "sql.NullTime",
+		}[goType]
+
+		if len(nullType) != 0 {
+			return nullType, "database/sql"
+		}
+	}
+
+	return "*" + goType, importPath
+}
+
+// sqlColumnTag returns the struct tag of the column with the tag keys of -tags.
+func sqlColumnTag(table *sqlTable, column sqlColumn) string {
+	var tags []string
+	for _, key := range strings.Split(*_tags, ",") {
+		switch key = strings.TrimSpace(key); key {
+		case "":
+		case "gorm":
+			settings := []string{"column:" + column.Name}
+			if column.PrimaryKey {
+				settings = append(settings, "primaryKey")
+			}
+
+			if column.AutoIncrement {
+				settings = append(settings, "autoIncrement")
+			}
+
+			if column.NotNull && !column.PrimaryKey {
+				settings = append(settings, "not null")
+			}
+
+			if column.Unique {
+				settings = append(settings, "unique")
+			}
+
+			if size := sqlColumnSize(column.Type); size > 0 {
+				settings = append(settings, "size:"+strconv.Itoa(size))
+			}
+
+			if len(column.Default) != 0 && !strings.EqualFold(column.Default, "NULL") {
+				settings = append(settings, "default:"+column.Default)
+			}
+
+			for _, index := range table.Indexes {
+				if !hasOption(index.Columns, column.Name) {
+					continue
+				}
+
+				if index.Unique {
+					settings = append(settings, "uniqueIndex:"+index.Name)
+				} else {
+					settings = append(settings, "index:"+index.Name)
+				}
+			}
+
+			if len(column.Comment) != 0 {
+				settings = append(settings, "comment:"+strings.NewReplacer(";", ",", `"`, "'").Replace(column.Comment))
+			}
+
+			tags = append(tags, fmt.Sprintf(`gorm:"%s"`, strings.Join(settings, ";")))
+		default:
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, key, column.Name))
+		}
+	}
+
+	return strings.Join(tags, " ")
+}
+
+func sqlColumnSize(sqlType string) int {
+	lower := strings.ToLower(sqlType)
+	if !strings.Contains(lower, "char") {
+		return 0
+	}
+
+	start, end := strings.Index(lower, "("), strings.Index(lower, ")")
+	if start < 0 || end < start {
+		return 0
+	}
+
+	size, _ := strconv.Atoi(strings.TrimSpace(lower[start+1 : end]))
+	return size
+}
+
+// sqlToken is a token of the sql text.
+type sqlToken struct {
+	text   string
+	quoted bool
+}
+
+// upper returns the upper case keyword of the token, or empty if the token is quoted.
+func (t sqlToken) upper() string {
+	if t.quoted {
+		return ""
+	}
+
+	return strings.ToUpper(t.text)
+}
+
+// tokenizeSQL splits the sql text into tokens without comments.
+func tokenizeSQL(text string) ([]sqlToken, error) {
+	var tokens []sqlToken
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(text[i:], "--") || c == '#':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			i += end
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unclosed block comment")
+			}
+			i += end + 4
+		case c == '\'':
+			buf := strings.Builder{}
+			buf.WriteByte('\'')
+			j := i + 1
+			for ; j < len(text); j++ {
+				if text[j] == '\'' {
+					if j+1 < len(text) && text[j+1] == '\'' {
+						buf.WriteString("''")
+						j++
+						continue
+					}
+					break
+				}
+				buf.WriteByte(text[j])
+			}
+
+			if j >= len(text) {
+				return nil, errors.New("unclosed string literal")
+			}
+
+			buf.WriteByte('\'')
+			tokens = append(tokens, sqlToken{text: buf.String()})
+			i = j + 1
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+
+			end := strings.IndexByte(text[i+1:], closing)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed quoted identifier %c", c)
+			}
+
+			tokens = append(tokens, sqlToken{text: text[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.ContainsRune("(),;.=", rune(c)):
+			tokens = append(tokens, sqlToken{text: string(c)})
+			i++
+		default:
+			j := i
+			for j < len(text) && !strings.ContainsRune(" \t\n\r(),;.='\"`[", rune(text[j])) {
+				j++
+			}
+
+			if j == i {
+				j++
+			}
+
+			tokens = append(tokens, sqlToken{text: text[i:j]})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseSQLTables parses the CREATE TABLE and CREATE INDEX statements of the sql text.
+func parseSQLTables(text string) ([]*sqlTable, error) {
+	tokens, err := tokenizeSQL(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tables     []*sqlTable
+		tableIndex = map[string]*sqlTable{}
+	)
+
+	for _, statement := range splitSQLTokens(tokens, ";") {
+		p := &sqlParser{tokens: statement}
+		if !p.accept("CREATE") {
+			continue
+		}
+
+		p.accept("OR", "REPLACE")
+		p.accept("TEMPORARY")
+		p.accept("TEMP")
+		p.accept("UNLOGGED")
+
+		switch {
+		case p.accept("TABLE"):
+			table, err := p.parseCreateTable()
+			if err != nil {
+				return nil, err
+			}
+
+			tables = append(tables, table)
+			tableIndex[table.Name] = table
+		case p.accept("UNIQUE", "INDEX"):
+			p.parseCreateIndex(tableIndex, true)
+		case p.accept("INDEX"):
+			p.parseCreateIndex(tableIndex, false)
+		}
+	}
+
+	return tables, nil
+}
+
+func splitSQLTokens(tokens []sqlToken, sep string) [][]sqlToken {
+	var (
+		result [][]sqlToken
+		depth  int
+		start  int
+	)
+
+	for i, t := range tokens {
+		if t.quoted {
+			continue
+		}
+
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case sep:
+			if depth == 0 {
+				result = append(result, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if start < len(tokens) {
+		result = append(result, tokens[start:])
+	}
+
+	return result
+}
+
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *sqlParser) peek(offset ...int) sqlToken {
+	i := p.pos
+	if len(offset) != 0 {
+		i += offset[0]
+	}
+
+	if i >= len(p.tokens) {
+		return sqlToken{}
+	}
+
+	return p.tokens[i]
+}
+
+func (p *sqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// accept consumes the keywords if the following tokens match all of them.
+func (p *sqlParser) accept(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if p.peek(i).upper() != keyword {
+			return false
+		}
+	}
+
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// parseName parses a possibly schema qualified name and returns the last part of it.
+func (p *sqlParser) parseName() string {
+	name := p.next().text
+	for p.peek().text == "." && !p.peek().quoted {
+		p.pos++
+		name = p.next().text
+	}
+
+	return name
+}
+
+// parseParenthesis returns the tokens inside the parenthesis starting at the current token.
+func (p *sqlParser) parseParenthesis() ([]sqlToken, error) {
+	if p.peek().text != "(" {
+		return nil, fmt.Errorf("'(' expected, got %q", p.peek().text)
+	}
+
+	depth := 0
+	start := p.pos + 1
+	for ; !p.done(); p.pos++ {
+		t := p.peek()
+		if t.quoted {
+			continue
+		}
+
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.tokens[start : p.pos-1], nil
+			}
+		}
+	}
+
+	return nil, errors.New("unclosed parenthesis")
+}
+
+func (p *sqlParser) parseCreateTable() (*sqlTable, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	table := &sqlTable{Dialect: *_dialect, Name: p.parseName()}
+
+	body, err := p.parseParenthesis()
+	if err != nil {
+		return nil, fmt.Errorf("parse table %s, err: %w", table.Name, err)
+	}
+
+	var primaryKeys []string
+	for _, definition := range splitSQLTokens(body, ",") {
+		d := &sqlParser{tokens: definition}
+		if d.accept("CONSTRAINT") {
+			d.next()
+		}
+
+		switch {
+		case d.accept("PRIMARY", "KEY"):
+			primaryKeys = append(primaryKeys, d.parseColumnList()...)
+		case d.accept("UNIQUE"):
+			d.accept("KEY")
+			d.accept("INDEX")
+			name := ""
+			if d.peek().text != "(" {
+				name = d.next().text
+			}
+
+			columns := d.parseColumnList()
+			if len(name) == 0 {
+				name = "uni_" + table.Name + "_" + strings.Join(columns, "_")
+			}
+
+			table.Indexes = append(table.Indexes, sqlIndex{Name: name, Unique: true, Columns: columns})
+		case d.accept("FULLTEXT"), d.accept("SPATIAL"), d.accept("KEY"), d.accept("INDEX"):
+			d.accept("KEY")
+			d.accept("INDEX")
+			name := ""
+			if d.peek().text != "(" {
+				name = d.next().text
+			}
+
+			columns := d.parseColumnList()
+			if len(name) == 0 {
+				name = "idx_" + table.Name + "_" + strings.Join(columns, "_")
+			}
+
+			table.Indexes = append(table.Indexes, sqlIndex{Name: name, Columns: columns})
+		case d.accept("FOREIGN"), d.accept("CHECK"), d.accept("EXCLUDE"):
+		default:
+			column, err := d.parseColumn()
+			if err != nil {
+				return nil, fmt.Errorf("parse column of table %s, err: %w", table.Name, err)
+			}
+
+			table.Columns = append(table.Columns, column)
+		}
+	}
+
+	for i := range table.Columns {
+		if hasOption(primaryKeys, table.Columns[i].Name) {
+			table.Columns[i].PrimaryKey = true
+		}
+	}
+
+	return table, nil
+}
+
+// parseColumnList parses the column names inside the parenthesis like '(a, b(10) DESC)'.
+func (p *sqlParser) parseColumnList() []string {
+	list, err := p.parseParenthesis()
+	if err != nil {
+		return nil
+	}
+
+	var columns []string
+	for _, column := range splitSQLTokens(list, ",") {
+		if len(column) != 0 {
+			columns = append(columns, column[0].text)
+		}
+	}
+
+	return columns
+}
+
+var _sqlColumnConstraintKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "PRIMARY": true, "UNIQUE": true, "DEFAULT": true, "AUTO_INCREMENT": true,
+	"AUTOINCREMENT": true, "COMMENT": true, "REFERENCES": true, "CHECK": true, "GENERATED": true,
+	"CONSTRAINT": true, "COLLATE": true, "ON": true, "CHARACTER": true, "CHARSET": true, "IDENTITY": true,
+}
+
+func (p *sqlParser) parseColumn() (sqlColumn, error) {
+	if p.done() {
+		return sqlColumn{}, errors.New("empty column definition")
+	}
+
+	column := sqlColumn{Name: p.next().text}
+
+	column.Type = p.joinUntilConstraint()
+	if len(column.Type) == 0 {
+		return sqlColumn{}, fmt.Errorf("type of column %s not found", column.Name)
+	}
+
+	switch strings.ToLower(strings.Fields(column.Type)[0]) {
+	case "serial", "bigserial", "smallserial", "serial2", "serial4", "serial8":
+		column.AutoIncrement = true
+		column.NotNull = true
+	}
+
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			column.NotNull = true
+		case p.accept("NULL"):
+		case p.accept("PRIMARY", "KEY"):
+			column.PrimaryKey = true
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			column.Unique = true
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"):
+			column.AutoIncrement = true
+		case p.accept("GENERATED"):
+			if p.joinUntilConstraint(); p.accept("IDENTITY") {
+				column.AutoIncrement = true
+			}
+		case p.accept("DEFAULT"):
+			if p.peek().text == "(" {
+				list, err := p.parseParenthesis()
+				if err != nil {
+					return sqlColumn{}, err
+				}
+
+				column.Default = joinSQLTokens(list)
+			} else {
+				column.Default = p.joinUntilConstraint()
+			}
+		case p.accept("COMMENT"):
+			column.Comment = strings.ReplaceAll(strings.Trim(p.next().text, "'"), "''", "'")
+		default:
+			p.next()
+		}
+	}
+
+	return column, nil
+}
+
+// joinUntilConstraint joins the tokens until a column constraint keyword outside parenthesis.
+func (p *sqlParser) joinUntilConstraint() string {
+	start := p.pos
+	depth := 0
+	for ; !p.done(); p.pos++ {
+		t := p.peek()
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+
+		if depth == 0 && _sqlColumnConstraintKeywords[t.upper()] && p.pos != start {
+			break
+		}
+	}
+
+	return joinSQLTokens(p.tokens[start:p.pos])
+}
+
+func joinSQLTokens(tokens []sqlToken) string {
+	buf := strings.Builder{}
+	for i, t := range tokens {
+		if i != 0 && t.text != "(" && t.text != ")" && t.text != "," && t.text != "." &&
+			tokens[i-1].text != "(" && tokens[i-1].text != "," && tokens[i-1].text != "." {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(t.text)
+	}
+
+	return buf.String()
+}
+
+func (p *sqlParser) parseCreateIndex(tables map[string]*sqlTable, unique bool) {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+
+	name := ""
+	if p.peek().upper() != "ON" {
+		name = p.parseName()
+	}
+
+	if !p.accept("ON") {
+		return
+	}
+
+	table, ok := tables[p.parseName()]
+	if !ok {
+		return
+	}
+
+	if p.accept("USING") {
+		p.next()
+	}
+
+	columns := p.parseColumnList()
+	if len(columns) == 0 {
+		return
+	}
+
+	if len(name) == 0 {
+		name = "idx_" + table.Name + "_" + strings.Join(columns, "_")
+	}
+
+	table.Indexes = append(table.Indexes, sqlIndex{Name: name, Unique: unique, Columns: columns})
+}
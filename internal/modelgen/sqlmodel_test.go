@@ -0,0 +1,63 @@
+package modelgen
+
+import "testing"
+
+func TestParseSQLTables(t *testing.T) {
+	tables, err := parseSQLTables(`
+-- users table
+CREATE TABLE IF NOT EXISTS public."users" (
+	id BIGSERIAL PRIMARY KEY,
+	email VARCHAR(128) NOT NULL UNIQUE, /* inline comment */
+	status smallint NOT NULL DEFAULT 1,
+	created_at timestamp with time zone DEFAULT now()
+);
+CREATE UNIQUE INDEX idx_users_status ON users (status, email);
+
+CREATE TABLE ` + "`items`" + ` (
+	` + "`id`" + ` int unsigned NOT NULL AUTO_INCREMENT,
+	` + "`price`" + ` decimal(10,2) DEFAULT NULL COMMENT 'it''s price',
+	PRIMARY KEY (` + "`id`" + `)
+) ENGINE=InnoDB;
+`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(tables) != 2 {
+		t.Fatalf("tables length mismatch: %d", len(tables))
+	}
+
+	users := tables[0]
+	if users.Name != "users" || len(users.Columns) != 4 {
+		t.Fatalf("users mismatch: %+v", users)
+	}
+
+	if id := users.Columns[0]; !id.PrimaryKey || !id.AutoIncrement || id.Type != "BIGSERIAL" {
+		t.Fatalf("id mismatch: %+v", id)
+	}
+
+	if email := users.Columns[1]; !email.NotNull || !email.Unique || email.Type != "VARCHAR(128)" {
+		t.Fatalf("email mismatch: %+v", email)
+	}
+
+	if createdAt := users.Columns[3]; createdAt.Type != "timestamp with time zone" || createdAt.Default != "now()" {
+		t.Fatalf("created_at mismatch: %+v", createdAt)
+	}
+
+	if len(users.Indexes) != 1 || !users.Indexes[0].Unique || len(users.Indexes[0].Columns) != 2 {
+		t.Fatalf("users indexes mismatch: %+v", users.Indexes)
+	}
+
+	items := tables[1]
+	if id := items.Columns[0]; !id.PrimaryKey || !id.AutoIncrement || id.Type != "int unsigned" {
+		t.Fatalf("items id mismatch: %+v", id)
+	}
+
+	if price := items.Columns[1]; price.Comment != "it's price" || price.Type != "decimal(10,2)" {
+		t.Fatalf("price mismatch: %+v", price)
+	}
+
+	if goType, _ := sqlColumnGoType(items.Columns[0]); goType != "uint32" {
+		t.Fatalf("go type of items id mismatch: %s", goType)
+	}
+}
@@ -0,0 +1,585 @@
+package modelgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/merge"
+)
+
+const (
+	_tagValidate           = "validate"
+	_methodValidate        = "Validate"
+	_typeFieldViolation    = "FieldViolation"
+	_typeValidationError   = "ValidationError"
+	_funcIsValidEmail      = "isValidEmail"
+	_funcIsValidURL        = "isValidURL"
+	_validateRuleRequired  = "required"
+	_validateRuleOmitEmpty = "omitempty"
+	_validateRuleDive      = "dive"
+)
+
+// validateRule is a single rule of the validate tag, e.g. 'min=1'.
+type validateRule struct {
+	Name  string
+	Param string
+}
+
+func (r validateRule) String() string {
+	if len(r.Param) == 0 {
+		return r.Name
+	}
+
+	return r.Name + "=" + r.Param
+}
+
+// parseValidateTag parses the validate tag like 'required,min=1,dive,email', the rules after dive
+// are applied to the elements.
+func parseValidateTag(tag string) (rules, diveRules []validateRule) {
+	target := &rules
+	for _, s := range strings.Split(tag, ",") {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		if s == _validateRuleDive && target == &rules {
+			target = &diveRules
+			continue
+		}
+
+		name, param, _ := strings.Cut(s, "=")
+		*target = append(*target, validateRule{Name: name, Param: param})
+	}
+
+	return rules, diveRules
+}
+
+func hasValidateRule(rules []validateRule, name string) bool {
+	for _, r := range rules {
+		if r.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateCategory is the category of the value checked by the rules.
+type validateCategory int
+
+const (
+	validateOther validateCategory = iota
+	validateString
+	validateNumber
+	validateBool
+	validateLength
+	validateNilable
+	validateTime
+	validateModel
+)
+
+// validator generates the statements checking the values by the validate rules.
+type validator struct {
+	models *modelSet
+}
+
+// generateValidate generates the Validate methods of the models and the error types reporting the
+// violations.
+func generateValidate(ms *modelSet) ([]merge.Scope, error) {
+	var (
+		result []merge.Scope
+		v      = validator{models: ms}
+		add    = func(key, text string) {
+			if ms.ShouldDeclare(key) {
+				result = append(result, merge.Scope{Key: key, Text: text})
+			}
+		}
+	)
+
+	ms.addImport("errors")
+	ms.addImport("strings")
+	add(_typeFieldViolation, genFieldViolationString())
+	add(_typeValidationError, genValidationErrorString())
+	add(_typeValidationError+".Error", genValidationErrorMethodString())
+	add(_typeValidationError+".add", genValidationErrorAddString())
+	add(_typeValidationError+".merge", genValidationErrorMergeString())
+
+	var helpers []string
+	for _, m := range ms.Models {
+		if !ms.ShouldGenerate(m, _methodValidate) {
+			continue
+		}
+
+		text, used, err := v.genValidateString(m)
+		if err != nil {
+			return nil, err
+		}
+
+		helpers = append(helpers, used...)
+		result = append(result, merge.Scope{Key: m.Name + "." + _methodValidate, Text: text})
+	}
+
+	for _, name := range helpers {
+		switch name {
+		case _funcIsValidEmail:
+			ms.addImport("net/mail")
+			add(name, fmt.Sprintf(`// %s reports whether the string is a single email address without name.
+func %s(s string) bool {
+	address, err := mail.ParseAddress(s)
+	return err == nil && address.Address == s
+}
+`, name, name))
+		case _funcIsValidURL:
+			ms.addImport("net/url")
+			add(name, fmt.Sprintf(`// %s reports whether the string is an absolute URL.
+func %s(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && len(u.Scheme) != 0 && (len(u.Host) != 0 || len(u.Opaque) != 0)
+}
+`, name, name))
+		}
+	}
+
+	return result, nil
+}
+
+func genFieldViolationString() string {
+	return fmt.Sprintf(`// %s is a field violating the rule of its validate tag.
+type %s struct {
+	// Path is the JSON path of the field, e.g. 'items[0].name'.
+	Path    string
+	Rule    string
+	Message string
+}
+`, _typeFieldViolation, _typeFieldViolation)
+}
+
+func genValidationErrorString() string {
+	return fmt.Sprintf(`// %s aggregates the field violations reported by the %s methods.
+type %s struct {
+	Violations []%s
+}
+`, _typeValidationError, _methodValidate, _typeValidationError, _typeFieldViolation)
+}
+
+func genValidationErrorMethodString() string {
+	return fmt.Sprintf(`// Error joins the messages of the violations.
+func (e *%s) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Path+" "+v.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+`, _typeValidationError)
+}
+
+func genValidationErrorAddString() string {
+	return fmt.Sprintf(`func (e *%s) add(path, rule, message string) {
+	e.Violations = append(e.Violations, %s{Path: path, Rule: rule, Message: message})
+}
+`, _typeValidationError, _typeFieldViolation)
+}
+
+func genValidationErrorMergeString() string {
+	return fmt.Sprintf(`// merge adds the violations of the error returned by a nested %s method with the path prefix.
+func (e *%s) merge(prefix string, err error) {
+	var nested *%s
+	if !errors.As(err, &nested) {
+		e.add(prefix, "", err.Error())
+		return
+	}
+
+	for _, v := range nested.Violations {
+		switch {
+		case len(v.Path) == 0:
+			v.Path = prefix
+		case strings.HasPrefix(v.Path, "["):
+			v.Path = prefix + v.Path
+		default:
+			v.Path = prefix + "." + v.Path
+		}
+
+		e.Violations = append(e.Violations, v)
+	}
+}
+`, _methodValidate, _typeValidationError, _typeValidationError)
+}
+
+func (v validator) genValidateString(m *model) (string, []string, error) {
+	var (
+		receiver = m.Receiver()
+		body     strings.Builder
+		helpers  []string
+	)
+
+	for _, f := range m.Fields {
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, _ := reflect.StructTag(f.Tag).Lookup(_tagValidate)
+		if tag == "-" {
+			continue
+		}
+
+		rules, diveRules := parseValidateTag(tag)
+		path := f.FieldName()
+		if name, _, ok := f.TagValue("json"); ok && len(name) != 0 && name != "-" {
+			path = name
+		}
+
+		text, used, err := v.validate(receiver+"."+f.FieldName(), parseTypeExpr(f.Type), rules, diveRules, strconv.Quote(path), 0)
+		if err != nil {
+			return "", nil, fmt.Errorf("validate tag of %s.%s, err: %w", m.Name, f.FieldName(), err)
+		}
+
+		body.WriteString(text)
+		helpers = append(helpers, used...)
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("// %s checks the fields of the %s by their validate tags, it returns a *%s\n", _methodValidate, m.Name, _typeValidationError))
+	buf.WriteString("// aggregating all the violations.\n")
+	buf.WriteString(fmt.Sprintf("func (%s *%s) %s() error {\n", receiver, m.Name, _methodValidate))
+	buf.WriteString(fmt.Sprintf("if %s == nil {\nreturn nil\n}\n\n", receiver))
+	if body.Len() != 0 {
+		buf.WriteString(fmt.Sprintf("errs := &%s{}\n", _typeValidationError))
+		buf.WriteString(body.String())
+		buf.WriteString("\nif len(errs.Violations) != 0 {\nreturn errs\n}\n\n")
+	}
+	buf.WriteString("return nil\n}\n")
+
+	return buf.String(), helpers, nil
+}
+
+// category returns the validate category of the type and the type to check, which is the
+// underlying type of the named types.
+func (v validator) category(t *typeExpr) (validateCategory, *typeExpr) {
+	switch t.Kind {
+	case typeSlice, typeMap, typeArray:
+		return validateLength, t
+	case typePointer, typeChan, typeFunc, typeInterface:
+		return validateNilable, t
+	case typeIdent:
+		switch {
+		case t.Name == "string":
+			return validateString, t
+		case t.Name == "bool":
+			return validateBool, t
+		case t.Name == "any" || t.Name == "error":
+			return validateNilable, t
+		case t.Name == "time.Time":
+			return validateTime, t
+		case t.Name == "time.Duration" || _basicTypes[t.Name]:
+			return validateNumber, t
+		}
+
+		if _, ok := v.models.MethodResult(t.Name, _methodValidate, *_validate); ok {
+			return validateModel, t
+		}
+
+		if underlying, ok := v.models.Underlying(t.Name); ok {
+			c, _ := v.category(underlying)
+			return c, underlying
+		}
+	}
+
+	return validateOther, t
+}
+
+// validate returns the statements checking the value by the rules, path is the expression of the
+// JSON path, and the helper functions used by the statements.
+func (v validator) validate(value string, t *typeExpr, rules, diveRules []validateRule, path string, depth int) (string, []string, error) {
+	var (
+		buf     strings.Builder
+		helpers []string
+		suffix  = depthSuffix(depth)
+	)
+
+	if t.Kind == typePointer {
+		var rest []validateRule
+		for _, r := range rules {
+			if r.Name != _validateRuleRequired && r.Name != _validateRuleOmitEmpty {
+				rest = append(rest, r)
+			}
+		}
+
+		var inner string
+		if c, _ := v.category(t.Elem); c == validateModel {
+			inner = fmt.Sprintf("if err := %s.%s(); err != nil {\nerrs.merge(%s, err)\n}\n", value, _methodValidate, path)
+		} else {
+			text, used, err := v.validate("elem"+suffix, t.Elem, rest, diveRules, path, depth+1)
+			if err != nil {
+				return "", nil, err
+			}
+
+			if len(text) != 0 {
+				inner = fmt.Sprintf("elem%s := *%s\n%s", suffix, value, text)
+				helpers = append(helpers, used...)
+			}
+		}
+
+		switch {
+		case hasValidateRule(rules, _validateRuleRequired):
+			buf.WriteString(fmt.Sprintf("if %s == nil {\nerrs.add(%s, %q, \"is required\")\n}", value, path, _validateRuleRequired))
+			if len(inner) != 0 {
+				buf.WriteString(fmt.Sprintf(" else {\n%s}", inner))
+			}
+			buf.WriteString("\n")
+		case len(inner) != 0:
+			buf.WriteString(fmt.Sprintf("if %s != nil {\n%s}\n", value, inner))
+		}
+
+		return buf.String(), helpers, nil
+	}
+
+	category, underlying := v.category(t)
+	var (
+		cases     [][2]string // condition and statement
+		omitEmpty bool
+	)
+
+	for _, r := range rules {
+		switch r.Name {
+		case _validateRuleOmitEmpty:
+			omitEmpty = true
+			continue
+		case _validateRuleRequired:
+			if category == validateModel || category == validateOther {
+				continue
+			}
+		}
+
+		condition, message, helper, err := v.ruleCondition(value, t, category, r)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if len(helper) != 0 {
+			helpers = append(helpers, helper)
+		}
+
+		cases = append(cases, [2]string{condition, fmt.Sprintf("errs.add(%s, %q, %q)\n", path, r.String(), message)})
+	}
+
+	switch len(cases) {
+	case 0:
+	case 1:
+		buf.WriteString(fmt.Sprintf("if %s {\n%s}\n", cases[0][0], cases[0][1]))
+	default:
+		buf.WriteString("switch {\n")
+		for _, c := range cases {
+			buf.WriteString(fmt.Sprintf("case %s:\n%s", c[0], c[1]))
+		}
+		buf.WriteString("}\n")
+	}
+
+	switch category {
+	case validateModel:
+		buf.WriteString(fmt.Sprintf("if err := %s.%s(); err != nil {\nerrs.merge(%s, err)\n}\n", value, _methodValidate, path))
+	case validateLength:
+		var (
+			elem, loop string
+			elemPath   string
+		)
+
+		if underlying.Kind == typeMap {
+			elem = "elem" + suffix
+			loop = fmt.Sprintf("for key%s, %s := range %s {\n", suffix, elem, value)
+			elemPath = sprintfPathExpr(path, "[%v]", "key"+suffix)
+		} else {
+			elem = fmt.Sprintf("%s[index%s]", value, suffix)
+			loop = fmt.Sprintf("for index%s := range %s {\n", suffix, value)
+			elemPath = sprintfPathExpr(path, "[%d]", "index"+suffix)
+		}
+
+		elemDive, elemDiveRules := diveRules, []validateRule(nil)
+		if i := indexValidateRule(diveRules, _validateRuleDive); i >= 0 {
+			elemDive, elemDiveRules = diveRules[:i], diveRules[i+1:]
+		}
+
+		text, used, err := v.validate(elem, underlying.Elem, elemDive, elemDiveRules, "path"+suffix, depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if len(text) != 0 {
+			v.models.addImport("fmt")
+			helpers = append(helpers, used...)
+			buf.WriteString(loop)
+			buf.WriteString(fmt.Sprintf("path%s := %s\n", suffix, elemPath))
+			buf.WriteString(text)
+			buf.WriteString("}\n")
+		}
+	}
+
+	if omitEmpty && buf.Len() != 0 {
+		// the same as go-playground/validator, omitempty of the structs does nothing
+		if present, ok := v.presentCondition(value, category); ok {
+			return fmt.Sprintf("if %s {\n%s}\n", present, buf.String()), helpers, nil
+		}
+	}
+
+	return buf.String(), helpers, nil
+}
+
+func indexValidateRule(rules []validateRule, name string) int {
+	for i, r := range rules {
+		if r.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// zeroCondition returns the condition reporting whether the value is zero.
+func (v validator) zeroCondition(value string, category validateCategory) (string, bool) {
+	switch category {
+	case validateString:
+		return value + ` == ""`, true
+	case validateNumber:
+		return value + " == 0", true
+	case validateBool:
+		return "!" + value, true
+	case validateLength:
+		return "len(" + value + ") == 0", true
+	case validateNilable:
+		return value + " == nil", true
+	case validateTime:
+		return value + ".IsZero()", true
+	}
+
+	return "", false
+}
+
+// presentCondition returns the condition reporting whether the value is not zero.
+func (v validator) presentCondition(value string, category validateCategory) (string, bool) {
+	switch category {
+	case validateString:
+		return value + ` != ""`, true
+	case validateNumber:
+		return value + " != 0", true
+	case validateBool:
+		return value, true
+	case validateLength:
+		return "len(" + value + ") != 0", true
+	case validateNilable:
+		return value + " != nil", true
+	case validateTime:
+		return "!" + value + ".IsZero()", true
+	}
+
+	return "", false
+}
+
+// ruleCondition returns the condition reporting whether the value violates the rule, the message of
+// the violation, and the helper function used by the condition.
+func (v validator) ruleCondition(value string, t *typeExpr, category validateCategory, r validateRule) (condition, message, helper string, err error) {
+	unsupported := fmt.Errorf("rule %s is not supported by type %s", r, t.Raw)
+	if r.Name == _validateRuleRequired {
+		zero, ok := v.zeroCondition(value, category)
+		if !ok {
+			return "", "", "", unsupported
+		}
+
+		if category == validateLength {
+			// the same as go-playground/validator, an empty but non-nil slice or map is present
+			zero = value + " == nil"
+		}
+
+		return zero, "is required", "", nil
+	}
+
+	operators := map[string]struct{ operator, text string }{
+		"min": {"<", "at least"},
+		"max": {">", "at most"},
+		"len": {"!=", ""},
+		"eq":  {"!=", ""},
+		"ne":  {"==", "not"},
+		"gt":  {"<=", "greater than"},
+		"gte": {"<", "greater than or equal to"},
+		"lt":  {">=", "less than"},
+		"lte": {">", "less than or equal to"},
+	}
+
+	if op, ok := operators[r.Name]; ok {
+		if len(r.Param) == 0 {
+			return "", "", "", fmt.Errorf("rule %s requires a parameter", r.Name)
+		}
+
+		text := strings.TrimSpace(op.text + " " + r.Param)
+		switch category {
+		case validateNumber:
+			if r.Name == "len" {
+				return "", "", "", unsupported
+			}
+
+			return fmt.Sprintf("%s %s %s", value, op.operator, r.Param), "must be " + text, "", nil
+		case validateString:
+			if r.Name == "eq" || r.Name == "ne" {
+				return fmt.Sprintf("%s %s %s", value, op.operator, strconv.Quote(r.Param)), "must be " + text, "", nil
+			}
+
+			v.models.addImport("unicode/utf8")
+			return fmt.Sprintf("utf8.RuneCountInString(%s) %s %s", stringExpr(value, t), op.operator, r.Param), "length must be " + text, "", nil
+		case validateLength:
+			if r.Name == "eq" || r.Name == "ne" {
+				return "", "", "", unsupported
+			}
+
+			return fmt.Sprintf("len(%s) %s %s", value, op.operator, r.Param), "length must be " + text, "", nil
+		}
+
+		return "", "", "", unsupported
+	}
+
+	switch r.Name {
+	case "oneof":
+		var conditions []string
+		for _, option := range strings.Fields(r.Param) {
+			switch category {
+			case validateString:
+				conditions = append(conditions, fmt.Sprintf("%s != %s", value, strconv.Quote(option)))
+			case validateNumber:
+				conditions = append(conditions, fmt.Sprintf("%s != %s", value, option))
+			default:
+				return "", "", "", unsupported
+			}
+		}
+
+		if len(conditions) == 0 {
+			return "", "", "", fmt.Errorf("rule %s requires a parameter", r.Name)
+		}
+
+		return strings.Join(conditions, " && "), "must be one of [" + r.Param + "]", "", nil
+	case "email":
+		if category != validateString {
+			return "", "", "", unsupported
+		}
+
+		return fmt.Sprintf("!%s(%s)", _funcIsValidEmail, stringExpr(value, t)), "must be a valid email address", _funcIsValidEmail, nil
+	case "url":
+		if category != validateString {
+			return "", "", "", unsupported
+		}
+
+		return fmt.Sprintf("!%s(%s)", _funcIsValidURL, stringExpr(value, t)), "must be a valid URL", _funcIsValidURL, nil
+	}
+
+	return "", "", "", fmt.Errorf("unsupported rule %s", r)
+}
+
+// stringExpr returns the expression converting the value of a named string type into string.
+func stringExpr(value string, t *typeExpr) string {
+	if t.Kind == typeIdent && t.Name == "string" {
+		return value
+	}
+
+	return "string(" + value + ")"
+}
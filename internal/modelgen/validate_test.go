@@ -0,0 +1,88 @@
+package modelgen
+
+import "testing"
+
+func TestParseValidateTag(t *testing.T) {
+	rules, diveRules := parseValidateTag("required,max=3,dive,oneof=a b,dive,min=1")
+	if len(rules) != 2 || rules[0].Name != "required" || rules[1].String() != "max=3" {
+		t.Fatalf("rules mismatch: %+v", rules)
+	}
+
+	if len(diveRules) != 3 || diveRules[0].Param != "a b" || diveRules[1].Name != "dive" || diveRules[2].String() != "min=1" {
+		t.Fatalf("dive rules mismatch: %+v", diveRules)
+	}
+}
+
+func TestGenerateValidate(t *testing.T) {
+	runModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+//go:generate modelgen -type=Order -relative -validate -destination=../entity/order.go -package=entity
+
+type Order struct {
+	ID     int64             ` + "`json:\"id\" validate:\"required\"`" + `
+	Email  string            ` + "`json:\"email\" validate:\"omitempty,email\"`" + `
+	Status string            ` + "`json:\"status\" validate:\"oneof=active inactive\"`" + `
+	Tags   []string          ` + "`json:\"tags\" validate:\"max=2,dive,min=1\"`" + `
+	Labels map[string]string ` + "`json:\"labels\" validate:\"dive,len=2\"`" + `
+	Owner  *Item             ` + "`json:\"owner\" validate:\"required\"`" + `
+	Items  []Item            ` + "`json:\"items\"`" + `
+}
+
+type Item struct {
+	Name  string  ` + "`json:\"name\" validate:\"required\"`" + `
+	Price float64 ` + "`json:\"price\" validate:\"gte=0\"`" + `
+}
+`,
+	}, map[string]string{
+		"entity/order_test.go": `package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Order{ID: 1, Status: "active", Tags: []string{"a"}, Labels: map[string]string{"k": "ab"}, Owner: &Item{Name: "o"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid order, err: %+v", err)
+	}
+
+	invalid := Order{
+		Email:  "mail",
+		Status: "deleted",
+		Tags:   []string{"a", "", "c"},
+		Labels: map[string]string{"k": "abc"},
+		Items:  []Item{{Name: "i"}, {Price: -1}},
+	}
+
+	var verr *ValidationError
+	if err := invalid.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("invalid order, err: %+v", err)
+	}
+
+	expected := map[string]string{
+		"id":             "required",
+		"email":          "email",
+		"status":         "oneof=active inactive",
+		"tags":           "max=2",
+		"tags[1]":        "min=1",
+		"labels[k]":      "len=2",
+		"owner":          "required",
+		"items[1].name":  "required",
+		"items[1].price": "gte=0",
+	}
+
+	if len(verr.Violations) != len(expected) {
+		t.Fatalf("violations mismatch: %+v", verr.Violations)
+	}
+
+	for _, v := range verr.Violations {
+		if expected[v.Path] != v.Rule {
+			t.Fatalf("violation mismatch: %+v", v)
+		}
+	}
+}
+`,
+	})
+}
@@ -0,0 +1,107 @@
+package plugingen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/domaingen"
+	"github.com/yanun0323/gox/internal/report"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+const _commandName = "plugin"
+
+var _flags = command.NewFlagSet(_commandName)
+
+var (
+	_help        = _flags.Bool("h", false, "show command help")
+	_plugin      = _flags.String("plugin", "", "plugin name, the plugin 'mock' is run by gox-gen-mock in PATH")
+	_param       = _flags.String("param", "", "parameter passed to the plugin")
+	_destination = _flags.String("destination", "", "destination file passed to the plugin, the generated files are relative to its folder")
+	_package     = _flags.String("package", "", "package name of the destination")
+	_name        = _flags.String("name", "", "implementation struct name passed to the plugin")
+	_type        = _flags.String("type", "", "comma separated target interface names in the package, instead of the interface below the go:generate directive")
+	_noEmbed     = _flags.Bool("noembed", false, "skip the methods of the embed interfaces")
+	_json        = _flags.Bool("json", false, "print the report of each interface as a JSON line")
+)
+
+var helper = util.Helper{}
+
+// Usage is a replacement usage function for the flags package.
+func Usage() {
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "%s: generate files from the interface by the plugin executable %s<plugin>\n", _commandName, gen.PluginPrefix)
+	fmt.Fprintf(os.Stderr, "\n")
+	_flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\texample:\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "\t//go:generate gox %s -plugin=mock -destination=../mock/member.go -package=mock\n", _commandName)
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// Command is the plugin command of gox, it passes the interface resolved like 'gox domain' to the
+// plugin executable and saves the files of the plugin.
+var Command = command.Command{
+	Name:    _commandName,
+	Summary: "generate files from the interface by the plugin executable " + gen.PluginPrefix + "<plugin>",
+	Run:     run,
+}
+
+func run(args []string) error {
+	if err := command.Parse(_flags, Usage, args); err != nil {
+		return err
+	}
+
+	if *_help {
+		_flags.Usage()
+		return nil
+	}
+
+	rep := report.New(_commandName, *_json)
+
+	return rep.Finish(generate(rep))
+}
+
+func generate(rep *report.Reporter) error {
+	if len(*_plugin) == 0 {
+		_flags.Usage()
+		return errors.New("plugin not define")
+	}
+
+	dir, file, err := helper.GetDir()
+	if err != nil {
+		return fmt.Errorf("get directory, err: %w", err)
+	}
+
+	refs, err := domaingen.FindInterfaceRefs(dir, file, os.Getenv("GOLINE"), helper.SplitList(*_type), *_name)
+	if err != nil {
+		return err
+	}
+
+	opt := gen.Options{
+		Destination: *_destination,
+		Package:     *_package,
+		Name:        *_name,
+		NoEmbed:     *_noEmbed,
+	}
+
+	for _, ref := range refs {
+		rp := report.Report{Type: ref.Name, Source: report.NewPosition(ref.File, ref.Line), Destination: helper.AbsPath(*_destination)}
+		result, err := gen.RunPlugin(context.Background(), ref, opt, *_plugin, *_param)
+		if err == nil {
+			rp, err = report.Result(result), result.Save()
+		}
+
+		rep.Report(rp, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
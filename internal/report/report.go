@@ -0,0 +1,265 @@
+// Package report writes the results of the generators as JSON lines by -json, so the editor
+// integrations and the CI can consume them. A line is written for each target:
+//
+//	{"command":"domain","type":"MemberUsecase","source":{"file":"/app/domain/member.go","line":12,"column":6},"destination":"/app/usecase/member.go","actions":[{"kind":"create_file","name":"/app/usecase/member.go"},{"kind":"add","name":"memberUsecase"}]}
+//	{"command":"domain","type":"MemberRepository","source":{"file":"/app/domain/member.go","line":20},"error":{"message":"MemberRepository isn't an interface","position":{"file":"/app/domain/member.go","line":21,"column":6}}}
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yanun0323/gox/gen"
+	"github.com/yanun0323/gox/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+// Report is the result of a target.
+type Report struct {
+	// Command is the generator of the target, e.g. domain.
+	Command string `json:"command"`
+	// Type is the name of the source type.
+	Type string `json:"type,omitempty"`
+	// Source is the position of the source type, or the go:generate directive or the config before the
+	// type is resolved.
+	Source *Position `json:"source,omitempty"`
+	// Destination is the absolute path of the generated file.
+	Destination string `json:"destination,omitempty"`
+	// Actions is the changes of the generated files in the order they're made.
+	Actions []gen.Action `json:"actions,omitempty"`
+	// Cached is true when the target is skipped since its inputs are unchanged.
+	Cached bool `json:"cached,omitempty"`
+	// Error is the error of the target.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Position is a position in a go file, the line and the column are 1-based and omitted when unknown.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Error is the error of a target.
+type Error struct {
+	Message string `json:"message"`
+	// Position is the position causing the error, it's the source position when the error doesn't carry its own.
+	Position *Position `json:"position,omitempty"`
+}
+
+// NewPosition returns the position of the line in the file, it returns nil without the file.
+func NewPosition(file string, line int) *Position {
+	if len(file) == 0 {
+		return nil
+	}
+
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	return &Position{File: file, Line: line}
+}
+
+// TokenPosition returns the position of the token position, it returns nil if the position is invalid.
+func TokenPosition(pos token.Position) *Position {
+	if !pos.IsValid() {
+		return nil
+	}
+
+	p := NewPosition(pos.Filename, pos.Line)
+	p.Column = pos.Column
+
+	return p
+}
+
+// Result returns the report of the result of gen, the destination is the first file of the result.
+func Result(result *gen.Result) Report {
+	rp := Report{Type: result.Type, Source: TokenPosition(result.Source), Actions: result.Actions}
+	if len(result.Files) != 0 {
+		rp.Destination = result.Files[0].Path
+	}
+
+	return rp
+}
+
+// TypePosition returns the position of the type declared in the package folder, it returns nil if
+// the type isn't found.
+func TypePosition(dir, name string) *Position {
+	file, _, _, err := util.Helper{}.FindTypeSpec(dir, name)
+	if err != nil {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return NewPosition(file, 0)
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				return TokenPosition(fset.Position(ts.Name.Pos()))
+			}
+		}
+	}
+
+	return NewPosition(file, 0)
+}
+
+// NewError returns the error of the report. It's positioned by the position carried by the error,
+// e.g. gen.PositionError, the scanner errors of the parser and the package errors of the type
+// checker, or the fallback.
+func NewError(err error, fallback *Position) *Error {
+	result := &Error{Message: err.Error(), Position: fallback}
+
+	var (
+		posErr  *gen.PositionError
+		listErr scanner.ErrorList
+		scanErr *scanner.Error
+		pkgErr  packages.Error
+	)
+
+	switch {
+	case errors.As(err, &posErr):
+		result.Position = TokenPosition(posErr.Pos)
+		result.Message = strings.Replace(result.Message, posErr.Pos.String()+": ", "", 1)
+	case errors.As(err, &listErr) && len(listErr) != 0:
+		result.Position = TokenPosition(listErr[0].Pos)
+	case errors.As(err, &scanErr):
+		result.Position = TokenPosition(scanErr.Pos)
+	case errors.As(err, &pkgErr):
+		if pos := parsePosition(pkgErr.Pos); pos != nil {
+			result.Position = pos
+		}
+	}
+
+	return result
+}
+
+// parsePosition parses the position of 'file:line:column', 'file:line' or 'file'.
+func parsePosition(s string) *Position {
+	if len(s) == 0 || s == "-" {
+		return nil
+	}
+
+	numbers := make([]int, 0, 2)
+	for len(numbers) < 2 {
+		i := strings.LastIndexByte(s, ':')
+		if i < 0 {
+			break
+		}
+
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			break
+		}
+
+		numbers = append([]int{n}, numbers...)
+		s = s[:i]
+	}
+
+	p := NewPosition(s, 0)
+	if len(numbers) != 0 {
+		p.Line = numbers[0]
+	}
+
+	if len(numbers) == 2 {
+		p.Column = numbers[1]
+	}
+
+	return p
+}
+
+// _output is the writer of the enabled reporters.
+var _output io.Writer = os.Stdout
+
+// SetOutput sets the writer of the reporters created afterward, it's the stdout by default.
+func SetOutput(w io.Writer) {
+	_output = w
+}
+
+// Writer returns the writer of the reporters created afterward.
+func Writer() io.Writer {
+	return _output
+}
+
+// Reporter writes the reports of a command run to the stdout, or the writer of SetOutput, as JSON
+// lines, it writes nothing when it's disabled.
+type Reporter struct {
+	command string
+	source  *Position
+	w       io.Writer
+	failed  bool
+}
+
+// New returns the reporter of the command, it's enabled by -json. The errors without the positions
+// are positioned at the go:generate directive running the command.
+func New(command string, enabled bool) *Reporter {
+	line, _ := strconv.Atoi(os.Getenv("GOLINE"))
+	r := &Reporter{command: command, source: NewPosition(os.Getenv("GOFILE"), line)}
+	if enabled {
+		r.w = _output
+	}
+
+	return r
+}
+
+// Report writes the report of a target, the error is positioned at the source unless it carries its
+// own position.
+func (r *Reporter) Report(rp Report, err error) {
+	if err != nil {
+		fallback := rp.Source
+		if fallback == nil {
+			fallback = r.source
+		}
+
+		rp.Error = NewError(err, fallback)
+	}
+
+	r.Write(rp)
+}
+
+// Write writes the report as it is, the command is the one of the reporter if it's empty.
+func (r *Reporter) Write(rp Report) {
+	if rp.Error != nil {
+		r.failed = true
+	}
+
+	if r.w == nil {
+		return
+	}
+
+	if len(rp.Command) == 0 {
+		rp.Command = r.command
+	}
+
+	if err := json.NewEncoder(r.w).Encode(rp); err != nil {
+		log.Printf("write report, err: %+v", err)
+	}
+}
+
+// Finish reports the error of the run which isn't reported by a target, e.g. the missing flags,
+// and returns the error.
+func (r *Reporter) Finish(err error) error {
+	if err != nil && !r.failed {
+		r.Report(Report{Source: r.source}, err)
+	}
+
+	return err
+}
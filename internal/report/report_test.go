@@ -0,0 +1,58 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/yanun0323/gox/gen"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestNewError(t *testing.T) {
+	_, parseErr := parser.ParseFile(token.NewFileSet(), "/app/domain/member.go", "package domain\n\nfunc {", 0)
+
+	fallback := &Position{File: "/app/domain/member.go", Line: 5}
+	testCases := []struct {
+		err      error
+		message  string
+		position Position
+	}{
+		{
+			err:      errors.New("package not define"),
+			message:  "package not define",
+			position: *fallback,
+		},
+		{
+			err:      &gen.PositionError{Pos: token.Position{Filename: "/app/domain/member.go", Line: 12, Column: 6}, Err: errors.New("Member isn't an interface")},
+			message:  "Member isn't an interface",
+			position: Position{File: "/app/domain/member.go", Line: 12, Column: 6},
+		},
+		{
+			err:      fmt.Errorf("parse file, err: %w", parseErr),
+			position: Position{File: "/app/domain/member.go", Line: 3, Column: 6},
+		},
+		{
+			err:      fmt.Errorf("type check, err: %w", packages.Error{Pos: "/app/domain/member.go:8:33", Msg: "undefined: Missing"}),
+			message:  "type check, err: /app/domain/member.go:8:33: undefined: Missing",
+			position: Position{File: "/app/domain/member.go", Line: 8, Column: 33},
+		},
+		{
+			err:      packages.Error{Pos: "-", Msg: "go list failed"},
+			position: *fallback,
+		},
+	}
+
+	for _, tc := range testCases {
+		result := NewError(tc.err, fallback)
+		if len(tc.message) != 0 && result.Message != tc.message {
+			t.Fatalf("message of %q mismatch: %s", tc.err, result.Message)
+		}
+
+		if result.Position == nil || *result.Position != tc.position {
+			t.Fatalf("position of %q mismatch: %+v", tc.err, result.Position)
+		}
+	}
+}
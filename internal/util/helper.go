@@ -0,0 +1,387 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// NoError logs the error with the message and exits, the commands print their reports by -json
+// before returning the errors.
+func NoError(err error, msg ...string) {
+	if err != nil {
+		if len(msg) == 0 || len(msg[0]) == 0 {
+			log.Fatal(err)
+		}
+
+		log.Fatalf("%s, err: %+v", msg[0], err)
+	}
+}
+
+// Helper is the helpers shared by the commands, the commands embed it into their own helper with
+// the command specific ones.
+type Helper struct{}
+
+func (Helper) EnvironmentPrint() {
+	for _, ev := range []string{"GOARCH", "GOOS", "GOFILE", "GOLINE", "GOPACKAGE", "DOLLAR"} {
+		fmt.Println("\t", ev, "=", os.Getenv(ev))
+	}
+}
+
+func (Helper) FirstLowerCase(s string) string {
+	if s[0] <= 'Z' && s[0] >= 'A' {
+		buf := []byte(s)
+		gap := byte('a' - 'A')
+		buf[0] = buf[0] + gap
+		return string(buf)
+	}
+	return s
+}
+
+func (Helper) FirstUpperCase(s string) string {
+	if s[0] <= 'z' && s[0] >= 'a' {
+		buf := []byte(s)
+		gap := byte('a' - 'A')
+		buf[0] = buf[0] - gap
+		return string(buf)
+	}
+	return s
+}
+
+func (h Helper) IsFirstUpperCase(s string, ignoreChars ...byte) bool {
+	tidied := h.TidyString(s, ignoreChars...)
+
+	return tidied[0] >= 'A' && tidied[0] <= 'Z'
+}
+
+func (Helper) GetDir() (currentDirectory string, currentFile string, e error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	cwd = filepath.Clean(cwd)
+	file := filepath.Join(cwd, os.Getenv("GOFILE"))
+	return cwd, file, nil
+}
+
+func (h Helper) FindProjectDir() (string, error) {
+	_, filePath, err := h.GetDir()
+	if err != nil {
+		return "", err
+	}
+
+	filePathSpan := strings.SplitAfter(filePath, string(os.PathSeparator))
+	for len(filePathSpan) != 0 {
+		filePathSpan = filePathSpan[:len(filePathSpan)-1]
+		d := strings.Join(filePathSpan, "")
+		_, err := os.Stat(d + "go.mod")
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return "", err
+		}
+
+		return filepath.Join(filePathSpan...), nil
+	}
+
+	return "", errors.New("project not found")
+}
+
+func (h Helper) GetSourceImportString() (alias, importPath string, err error) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+
+	moduleName, err := h.GetModuleName()
+	if err != nil {
+		return "", "", err
+	}
+
+	projectDir, err := h.FindProjectDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	relativePath := strings.TrimPrefix(currentDir, projectDir)
+	relativePathSpan := strings.Split(relativePath, string(os.PathSeparator))
+
+	ali := os.Getenv("GOPACKAGE")
+	if len(relativePathSpan) != 0 && relativePathSpan[len(relativePathSpan)-1] == ali {
+		ali = ""
+	}
+
+	return ali, strings.Join([]string{moduleName, strings.Join(relativePathSpan, "/")}, ""), nil
+}
+
+// GetGoModulePath returns the go.mod path of the working directory, it's passed by GOX_GOMOD
+// when the generator is run by 'gox generate', which saves running 'go env' for each directive.
+func (Helper) GetGoModulePath() (string, error) {
+	if mod := os.Getenv("GOX_GOMOD"); len(mod) != 0 {
+		return mod, nil
+	}
+
+	env, err := exec.Command("go", "env").Output()
+	if err != nil {
+		return "", err
+	}
+
+	// find GOMOD keyword
+	rows := strings.Split(string(env), "\n")
+	for _, row := range rows {
+		span := strings.Split(row, "=")
+		if len(span) != 2 || span[0] != "GOMOD" {
+			continue
+		}
+
+		mod := strings.Trim(span[1], "'")
+		mod = strings.Trim(mod, "\"")
+		if len(mod) == 0 {
+			return "", errors.New("go.mod not found, please run this program in the root folder of a go module project")
+		}
+
+		return mod, nil
+	}
+
+	return "", errors.New("go.mod not found, please run this program in the root folder of a go module project")
+}
+
+func (h Helper) GetModuleName() (string, error) {
+	path, err := h.GetGoModulePath()
+	if err != nil {
+		return "", err
+	}
+
+	return h.ReadModuleName(path)
+}
+
+// ReadModuleName returns the module name declared in the go.mod file, the error of a missing file
+// wraps os.ErrNotExist.
+func (Helper) ReadModuleName(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("go mod file not found, err: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "module ") {
+			name := strings.TrimPrefix(line, "module ")
+			return strings.TrimSpace(name), nil
+		}
+	}
+
+	return "", errors.New("module not found")
+}
+
+// SplitList splits the comma separated list, e.g. the names of -type, the empty items are dropped.
+func (Helper) SplitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// FindTypeSpec finds the declaration of the type in the go files of the package folder, the test
+// files are skipped.
+func (Helper) FindTypeSpec(dir, name string) (file string, f *ast.File, spec *ast.TypeSpec, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("read dir %s, err: %w", dir, err)
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("parse file %s, err: %w", file, err)
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					return file, f, ts, nil
+				}
+			}
+		}
+	}
+
+	return "", nil, nil, fmt.Errorf("type %s not found in %s", name, dir)
+}
+
+func (Helper) AbsPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+
+	return path
+}
+
+func (h Helper) EqualFold(a, b string, ignoreChars ...byte) bool {
+	a = h.TidyString(a, ignoreChars...)
+	b = h.TidyString(b, ignoreChars...)
+
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+
+	return strings.EqualFold(a, b)
+}
+
+func (Helper) TidyString(s string, removeChars ...byte) string {
+	tidied := s
+	for _, char := range removeChars {
+		tidied = strings.ReplaceAll(tidied, string(char), "")
+	}
+
+	return tidied
+}
+
+func (Helper) InsertString(s, prefix, insert string) string {
+	if strings.HasPrefix(s, prefix) {
+		return prefix + insert + strings.TrimPrefix(s, prefix)
+	}
+
+	return insert + s
+}
+
+// SnakeCase converts the go style name into snake case, e.g. 'UserID' to 'user_id'.
+func (Helper) SnakeCase(s string) string {
+	buf := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isUpper := c >= 'A' && c <= 'Z'
+		if isUpper && i != 0 {
+			prev := s[i-1]
+			prevLower := (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9')
+			nextLower := i+1 < len(s) && s[i+1] >= 'a' && s[i+1] <= 'z'
+			prevUpper := prev >= 'A' && prev <= 'Z'
+			if prevLower || (prevUpper && nextLower) {
+				buf.WriteByte('_')
+			}
+		}
+
+		if isUpper {
+			c += 'a' - 'A'
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
+
+// Pluralize returns the plural form of the english noun.
+func (Helper) Pluralize(s string) string {
+	switch {
+	case len(s) == 0:
+		return s
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
+// Singularize returns the singular form of the english noun.
+func (Helper) Singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "zes"),
+		strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"), strings.HasSuffix(s, "uses"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"), strings.HasSuffix(s, "is"):
+		return s
+	case strings.HasSuffix(s, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	default:
+		return s
+	}
+}
+
+// LowerCamelCase converts the go style name into lower camel case, e.g. 'ID' to 'id' and 'URLPath'
+// to 'urlPath'.
+func (Helper) LowerCamelCase(s string) string {
+	upper := 0
+	for upper < len(s) && s[upper] >= 'A' && s[upper] <= 'Z' {
+		upper++
+	}
+
+	switch {
+	case upper == len(s):
+		return strings.ToLower(s)
+	case upper > 1:
+		return strings.ToLower(s[:upper-1]) + s[upper-1:]
+	default:
+		return strings.ToLower(s[:upper]) + s[upper:]
+	}
+}
+
+// _commonInitialisms is the initialisms kept in upper case by CamelCase, like golint does.
+var _commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"LHS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// CamelCase converts the snake/kebab/space separated name into the go style exported name,
+// e.g. 'user_id' to 'UserID'.
+func (h Helper) CamelCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+
+	buf := strings.Builder{}
+	for _, word := range words {
+		if upper := strings.ToUpper(word); _commonInitialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+
+		buf.WriteString(h.FirstUpperCase(word))
+	}
+
+	result := buf.String()
+	if len(result) == 0 {
+		return "X"
+	}
+
+	if result[0] >= '0' && result[0] <= '9' {
+		result = "X" + result
+	}
+
+	return result
+}
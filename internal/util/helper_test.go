@@ -0,0 +1,91 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var helper = Helper{}
+
+func TestSnakeCase(t *testing.T) {
+	for s, expected := range map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"CreatedAt":  "created_at",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+	} {
+		if result := helper.SnakeCase(s); result != expected {
+			t.Fatalf("snake case of %s mismatch: %s", s, result)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	for s, expected := range map[string]string{
+		"example":  "examples",
+		"category": "categories",
+		"day":      "days",
+		"box":      "boxes",
+		"status":   "statuses",
+	} {
+		if result := helper.Pluralize(s); result != expected {
+			t.Fatalf("plural of %s mismatch: %s", s, result)
+		}
+	}
+}
+
+func TestLowerCamelCase(t *testing.T) {
+	for s, expected := range map[string]string{
+		"ID":        "id",
+		"URLPath":   "urlPath",
+		"CreatedAt": "createdAt",
+		"key":       "key",
+	} {
+		if result := helper.LowerCamelCase(s); result != expected {
+			t.Fatalf("lower camel case of %s mismatch: %s", s, result)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	result := helper.SplitList(" Member, Order,,Status ")
+	if strings.Join(result, "|") != "Member|Order|Status" {
+		t.Fatalf("list mismatch: %+v", result)
+	}
+
+	if result := helper.SplitList(""); len(result) != 0 {
+		t.Fatalf("empty list mismatch: %+v", result)
+	}
+}
+
+func TestFindTypeSpec(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"member.go":      "package domain\n\ntype Member struct{}\n",
+		"order.go":       "package domain\n\ntype (\n\tOrder  struct{}\n\tStatus int\n)\n",
+		"order_test.go":  "package domain\n\ntype Fixture struct{}\n",
+		"generate.go.md": "type Readme struct{}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("write file, err: %+v", err)
+		}
+	}
+
+	file, f, spec, err := helper.FindTypeSpec(dir, "Status")
+	if err != nil {
+		t.Fatalf("find type spec, err: %+v", err)
+	}
+
+	if file != filepath.Join(dir, "order.go") || f.Name.Name != "domain" || spec.Name.Name != "Status" {
+		t.Fatalf("type spec mismatch: %s %s", file, spec.Name.Name)
+	}
+
+	for _, name := range []string{"Fixture", "Readme", "Unknown"} {
+		if _, _, _, err := helper.FindTypeSpec(dir, name); err == nil {
+			t.Fatalf("expected error of %s", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetSourceImportString(t *testing.T) {
+	{
+		os.Setenv("GOFILE", "helper")
+		os.Setenv("GOPACKAGE", "usecase")
+
+		alias, dir, err := helper.GetSourceImportString()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+
+		if alias != "usecase" {
+			t.Fatalf("alias mismatch: %s", alias)
+		}
+
+		if dir != "github.com/yanun0323/gox/internal/util" {
+			t.Fatalf("import path mismatch: %s", dir)
+		}
+	}
+
+	{
+		os.Setenv("GOFILE", "helper")
+		os.Setenv("GOPACKAGE", "util")
+
+		alias, dir, err := helper.GetSourceImportString()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+
+		if alias != "" {
+			t.Fatalf("alias mismatch: %s", alias)
+		}
+
+		if dir != "github.com/yanun0323/gox/internal/util" {
+			t.Fatalf("import path mismatch: %s", dir)
+		}
+	}
+}
+
+func TestGetGoModulePath(t *testing.T) {
+	name, err := helper.GetGoModulePath()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	t.Log("module path:", name)
+}
+
+func TestGetGoModuleName(t *testing.T) {
+	name, err := helper.GetModuleName()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	t.Log("module name:", name)
+
+	if name != "github.com/yanun0323/gox" {
+		t.Fatalf("mismatch: %s", name)
+	}
+}
+
+func TestReadModuleName(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := helper.ReadModuleName(filepath.Join(dir, "go.mod")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing go.mod error mismatch: %+v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// comment\nmodule example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatalf("write go.mod, err: %+v", err)
+	}
+
+	name, err := helper.ReadModuleName(filepath.Join(dir, "go.mod"))
+	if err != nil || name != "example.com/app" {
+		t.Fatalf("module name mismatch: %s, err: %+v", name, err)
+	}
+}
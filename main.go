@@ -1,3 +1,68 @@
 package main
 
-func main() {}
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/yanun0323/gox/internal/command"
+	"github.com/yanun0323/gox/internal/domaingen"
+	"github.com/yanun0323/gox/internal/enumgen"
+	"github.com/yanun0323/gox/internal/generate"
+	"github.com/yanun0323/gox/internal/inspect"
+	"github.com/yanun0323/gox/internal/modelgen"
+	"github.com/yanun0323/gox/internal/plugingen"
+	"github.com/yanun0323/gox/internal/util"
+)
+
+//go:embed VERSION
+var _version string
+
+var _commands = []command.Command{
+	domaingen.Command,
+	modelgen.Command,
+	enumgen.Command,
+	plugingen.Command,
+	generate.Command,
+	generate.WatchCommand,
+	inspect.Command,
+	inspect.CheckCommand,
+	{Name: "version", Summary: "print the version of gox", Run: printVersion},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gox: ")
+
+	if len(os.Args) < 2 {
+		command.PrintUsage(_commands)
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if cmd, ok := command.Find(_commands, strings.Join(args, " ")); ok {
+			util.NoError(cmd.Run([]string{"-h"}))
+			return
+		}
+
+		command.PrintUsage(_commands)
+		return
+	}
+
+	cmd, ok := command.Find(_commands, name)
+	if !ok {
+		command.PrintUsage(_commands)
+		log.Fatalf("unknown command %s", name)
+	}
+
+	util.NoError(cmd.Run(args))
+}
+
+func printVersion([]string) error {
+	fmt.Println("gox", strings.TrimSpace(_version))
+	return nil
+}